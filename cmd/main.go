@@ -26,7 +26,9 @@ func main() {
 		// Same as the ALB default idle timeout.
 		IdleTimeout:       60 * time.Second,
 		ReadHeaderTimeout: 10 * time.Second,
-		Handler:           http.TimeoutHandler(router, 60*time.Second, "Timeout!\n"),
+		// Release asset downloads are streamed without a timeout
+		// as they can take longer than a minute for large assets.
+		Handler: registry.TimeoutHandler(router, 60*time.Second, "Timeout!\n"),
 	}
 	log.Printf("Starting server on port %d", port)
 	log.Fatal(srv.ListenAndServe())
@@ -0,0 +1,62 @@
+package apikeys
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"go.uber.org/zap"
+)
+
+type apiKeyAuthenticator struct {
+	keyHeader string
+	store     *FileStore
+	logger    *zap.Logger
+	clock     func() time.Time
+}
+
+// NewAuthenticator creates an authenticator that expects clients to
+// provide an API key issued by the registry in the provided header.
+// The scope of the key is attached to the principal so that requests
+// for plugins outside of the scope are rejected.
+func NewAuthenticator(
+	keyHeader string,
+	store *FileStore,
+	logger *zap.Logger,
+) auth.Authenticator {
+	return &apiKeyAuthenticator{
+		keyHeader: keyHeader,
+		store:     store,
+		logger:    logger,
+		clock:     time.Now,
+	}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(req *http.Request) (*auth.Principal, error) {
+	plainKey := strings.TrimSpace(req.Header.Get(a.keyHeader))
+	if !strings.HasPrefix(plainKey, KeyPrefix) {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	key, exists, err := a.store.Lookup(plainKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || !key.IsActive(a.clock()) {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	a.logger.Info(
+		"Authenticated request with API key",
+		zap.String("keyId", key.ID),
+		zap.String("keyLabel", key.Label),
+		zap.String("path", req.URL.Path),
+	)
+
+	return &auth.Principal{
+		Subject: "api-key:" + key.ID,
+		Scope:   key.Scope,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package apikeys
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type AuthenticatorTestSuite struct {
+	suite.Suite
+	store         *FileStore
+	authenticator auth.Authenticator
+}
+
+func (s *AuthenticatorTestSuite) SetupTest() {
+	s.store = NewFileStore(filepath.Join(s.T().TempDir(), "api-keys.json"))
+	s.authenticator = NewAuthenticator(
+		"bluelink-gh-registry-token",
+		s.store,
+		zap.NewNop(),
+	)
+}
+
+func (s *AuthenticatorTestSuite) Test_authenticates_active_key_with_scope() {
+	scope := &auth.Scope{Plugins: []string{"aws*"}}
+	plainKey, key, err := s.store.Create(&CreateKeyParams{
+		Label: "ci-runner",
+		Scope: scope,
+	})
+	s.Require().NoError(err)
+
+	principal, err := s.authenticator.Authenticate(s.request(plainKey))
+	s.Require().NoError(err)
+	s.Assert().Equal("api-key:"+key.ID, principal.Subject)
+	s.Assert().Equal(scope, principal.Scope)
+}
+
+func (s *AuthenticatorTestSuite) Test_rejects_expired_and_revoked_keys() {
+	expiresAt := time.Now().Add(-time.Minute)
+	expiredKey, _, err := s.store.Create(&CreateKeyParams{
+		Label:     "expired",
+		ExpiresAt: &expiresAt,
+	})
+	s.Require().NoError(err)
+
+	revokedKey, key, err := s.store.Create(&CreateKeyParams{Label: "revoked"})
+	s.Require().NoError(err)
+	s.Require().NoError(s.store.Revoke(key.ID))
+
+	for _, plainKey := range []string{expiredKey, revokedKey, KeyPrefix + "unknown", ""} {
+		_, err := s.authenticator.Authenticate(s.request(plainKey))
+		s.Assert().ErrorIs(err, auth.ErrUnauthenticated)
+	}
+}
+
+func (s *AuthenticatorTestSuite) request(plainKey string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/plugins", nil)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", plainKey)
+	return req
+}
+
+func TestAuthenticatorTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthenticatorTestSuite))
+}
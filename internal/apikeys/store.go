@@ -0,0 +1,268 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+)
+
+const (
+	// KeyPrefix is the prefix of all API keys issued by the registry,
+	// this makes keys easy to identify, for example, by secret scanners.
+	KeyPrefix = "blgr_"
+)
+
+var (
+	// ErrKeyNotFound is returned when an API key
+	// with the requested ID does not exist.
+	ErrKeyNotFound = errors.New("API key not found")
+)
+
+// Key holds the stored information about an API key,
+// the key itself is never stored, only a hash of the key.
+type Key struct {
+	ID string `json:"id"`
+	// Label describes who or what the key was issued to,
+	// it is included in audit logs for requests made with the key.
+	Label     string      `json:"label"`
+	Hash      string      `json:"hash"`
+	Scope     *auth.Scope `json:"scope,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	ExpiresAt *time.Time  `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time  `json:"revokedAt,omitempty"`
+}
+
+// IsActive determines whether the key can be used
+// at the provided time.
+func (k *Key) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil &&
+		(k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreateKeyParams holds the parameters for creating a new API key.
+type CreateKeyParams struct {
+	Label     string
+	Scope     *auth.Scope
+	ExpiresAt *time.Time
+}
+
+type keysFile struct {
+	Keys []*Key `json:"keys"`
+}
+
+// FileStore stores hashed API keys in a JSON file.
+//
+// Changes to the file made by another process, such as the API keys
+// tool, are picked up the next time a key is looked up, so keys can be
+// created and revoked without restarting the registry.
+type FileStore struct {
+	path         string
+	mu           sync.Mutex
+	keysByHash   map[string]*Key
+	loadedAt     time.Time
+	loadedSize   int64
+	loadedExists bool
+}
+
+// NewFileStore creates a store for API keys backed by the JSON file
+// at the provided path, the file is created when the first key is added.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path:       path,
+		keysByHash: map[string]*Key{},
+	}
+}
+
+// Create generates a new API key and stores its hash,
+// returning the key which is not stored and can not be retrieved again.
+func (s *FileStore) Create(params *CreateKeyParams) (string, *Key, error) {
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+
+	plainKey := KeyPrefix + secret
+	key := &Key{
+		ID:        id,
+		Label:     params.Label,
+		Hash:      HashKey(plainKey),
+		Scope:     params.Scope,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: params.ExpiresAt,
+	}
+
+	err = s.update(func(keys []*Key) ([]*Key, error) {
+		return append(keys, key), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plainKey, key, nil
+}
+
+// List returns all the stored keys, including revoked
+// and expired keys.
+func (s *FileStore) List() ([]*Key, error) {
+	file, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return file.Keys, nil
+}
+
+// Revoke marks the key with the provided ID as revoked,
+// revoked keys are kept in the store for auditing.
+func (s *FileStore) Revoke(id string) error {
+	return s.update(func(keys []*Key) ([]*Key, error) {
+		index := slices.IndexFunc(keys, func(key *Key) bool {
+			return key.ID == id
+		})
+		if index == -1 {
+			return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+		}
+
+		if keys[index].RevokedAt == nil {
+			now := time.Now().UTC()
+			keys[index].RevokedAt = &now
+		}
+		return keys, nil
+	})
+}
+
+// Lookup finds the stored key for the provided API key,
+// returning false if the key does not exist.
+// Revoked and expired keys are returned, callers should check
+// whether the key is active.
+func (s *FileStore) Lookup(plainKey string) (*Key, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadIfChanged(); err != nil {
+		return nil, false, err
+	}
+
+	key, exists := s.keysByHash[HashKey(plainKey)]
+	return key, exists, nil
+}
+
+func (s *FileStore) reloadIfChanged() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.keysByHash = map[string]*Key{}
+		s.loadedExists = false
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.loadedExists &&
+		info.ModTime().Equal(s.loadedAt) &&
+		info.Size() == s.loadedSize {
+		return nil
+	}
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	keysByHash := make(map[string]*Key, len(file.Keys))
+	for _, key := range file.Keys {
+		keysByHash[key.Hash] = key
+	}
+	s.keysByHash = keysByHash
+	s.loadedAt = info.ModTime()
+	s.loadedSize = info.Size()
+	s.loadedExists = true
+	return nil
+}
+
+func (s *FileStore) read() (*keysFile, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &keysFile{Keys: []*Key{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := &keysFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %q: %w", s.path, err)
+	}
+
+	return file, nil
+}
+
+func (s *FileStore) update(modify func(keys []*Key) ([]*Key, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	keys, err := modify(file.Keys)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(&keysFile{Keys: keys}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The file is replaced atomically so a registry reading the file
+	// never sees a partially written file.
+	tempFile, err := os.CreateTemp(filepath.Dir(s.path), ".api-keys-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), s.path)
+}
+
+// HashKey returns the hex encoded SHA-256 hash of an API key.
+// API keys have enough entropy that a fast hash is sufficient.
+func HashKey(plainKey string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(plainKey)))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(numBytes int) (string, error) {
+	randomBytes := make([]byte, numBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,89 @@
+package apikeys
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileStoreTestSuite struct {
+	suite.Suite
+	path  string
+	store *FileStore
+}
+
+func (s *FileStoreTestSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "api-keys.json")
+	s.store = NewFileStore(s.path)
+}
+
+func (s *FileStoreTestSuite) Test_creates_and_looks_up_hashed_keys() {
+	plainKey, key, err := s.store.Create(&CreateKeyParams{
+		Label: "ci-runner",
+		Scope: &auth.Scope{
+			Organisations: []string{"newstack-cloud"},
+		},
+	})
+	s.Require().NoError(err)
+	s.Assert().Contains(plainKey, KeyPrefix)
+	s.Assert().Equal(HashKey(plainKey), key.Hash)
+	s.Assert().NotContains(key.Hash, plainKey)
+
+	found, exists, err := s.store.Lookup(plainKey)
+	s.Require().NoError(err)
+	s.Require().True(exists)
+	s.Assert().Equal(key.ID, found.ID)
+	s.Assert().Equal([]string{"newstack-cloud"}, found.Scope.Organisations)
+
+	_, exists, err = s.store.Lookup(KeyPrefix + "unknown")
+	s.Require().NoError(err)
+	s.Assert().False(exists)
+}
+
+func (s *FileStoreTestSuite) Test_picks_up_changes_made_by_another_store() {
+	_, exists, err := s.store.Lookup(KeyPrefix + "unknown")
+	s.Require().NoError(err)
+	s.Assert().False(exists)
+
+	// A separate store for the same file, as used by the API keys tool.
+	plainKey, key, err := NewFileStore(s.path).Create(&CreateKeyParams{
+		Label: "developer",
+	})
+	s.Require().NoError(err)
+
+	found, exists, err := s.store.Lookup(plainKey)
+	s.Require().NoError(err)
+	s.Require().True(exists)
+	s.Assert().True(found.IsActive(found.CreatedAt))
+
+	s.Require().NoError(NewFileStore(s.path).Revoke(key.ID))
+
+	found, exists, err = s.store.Lookup(plainKey)
+	s.Require().NoError(err)
+	s.Require().True(exists)
+	s.Assert().False(found.IsActive(found.CreatedAt))
+}
+
+func (s *FileStoreTestSuite) Test_returns_error_when_revoking_missing_key() {
+	err := s.store.Revoke("missing")
+	s.Assert().ErrorIs(err, ErrKeyNotFound)
+}
+
+func (s *FileStoreTestSuite) Test_lists_all_keys() {
+	for _, label := range []string{"first", "second"} {
+		_, _, err := s.store.Create(&CreateKeyParams{Label: label})
+		s.Require().NoError(err)
+	}
+
+	keys, err := s.store.List()
+	s.Require().NoError(err)
+	s.Require().Len(keys, 2)
+	s.Assert().Equal("first", keys[0].Label)
+	s.Assert().Equal("second", keys[1].Label)
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(FileStoreTestSuite))
+}
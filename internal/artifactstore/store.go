@@ -0,0 +1,160 @@
+package artifactstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DatabaseFileName is the name of the database file
+	// that is created in the configured artifact store directory.
+	DatabaseFileName = "artifacts.db"
+
+	// The amount of time to wait to obtain a lock on the database file
+	// before giving up, this prevents the registry from hanging on
+	// startup when another process holds the lock.
+	openTimeout = 5 * time.Second
+)
+
+var (
+	artifactsBucket = []byte("artifacts")
+)
+
+// Store is an on-disk store for the contents of release artifacts
+// that survives restarts of the registry.
+// It implements the utils.ArtifactCache interface.
+//
+// Published release artifacts are immutable, so entries are keyed by
+// the owner, repository, tag and asset ID without expiry.
+// Entries are not isolated by token, callers are expected to have
+// fetched the release that the artifact belongs to from GitHub with
+// their own token before the store is used to retrieve the
+// artifact contents, this acts as the access check for cached data.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the artifact store database
+// in the provided directory.
+func Open(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(
+		filepath.Join(dir, DatabaseFileName),
+		0600,
+		&bolt.Options{Timeout: openTimeout},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(artifactsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Get retrieves the contents of a stored artifact.
+func (s *Store) Get(
+	ctx context.Context,
+	key *utils.ArtifactKey,
+	token string,
+) ([]byte, bool) {
+	var contents []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(artifactsBucket).Get(storeKey(key))
+		if value != nil {
+			// Values are only valid for the lifetime of the transaction.
+			contents = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if err != nil || contents == nil {
+		return nil, false
+	}
+
+	return contents, true
+}
+
+// Set persists the contents of an artifact.
+// Failures to write to the store are ignored as the
+// artifact contents can always be fetched from GitHub again.
+func (s *Store) Set(
+	ctx context.Context,
+	key *utils.ArtifactKey,
+	token string,
+	contents []byte,
+) {
+	if key.AssetID == 0 {
+		return
+	}
+
+	_ = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(artifactsBucket).Put(storeKey(key), contents)
+	})
+}
+
+// DeleteRepository removes all the stored artifacts for a repository,
+// this is used when a repository is deleted or renamed so that artifacts
+// are not kept for a repository that no longer exists.
+func (s *Store) DeleteRepository(owner string, repo string) error {
+	prefix := repositoryKeyPrefix(owner, repo)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(artifactsBucket).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); {
+			err := cursor.Delete()
+			if err != nil {
+				return err
+			}
+			// The cursor is moved to the next key when the
+			// current key is deleted.
+			key, _ = cursor.Seek(prefix)
+		}
+		return nil
+	})
+}
+
+// Close closes the underlying database file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Owner and repository names are case-insensitive in GitHub,
+// they are normalised so artifacts for a repository can be removed
+// regardless of the case used in requests.
+func storeKey(key *utils.ArtifactKey) []byte {
+	return fmt.Appendf(
+		repositoryKeyPrefix(key.Owner, key.Repository),
+		"%s/%d/%s",
+		key.Tag,
+		key.AssetID,
+		key.Kind,
+	)
+}
+
+func repositoryKeyPrefix(owner string, repo string) []byte {
+	return []byte(
+		fmt.Sprintf(
+			"%s/%s/",
+			strings.ToLower(owner),
+			strings.ToLower(repo),
+		),
+	)
+}
@@ -0,0 +1,80 @@
+package artifactstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type StoreTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *StoreTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+func (s *StoreTestSuite) Test_persists_artifacts_across_reopens() {
+	store, err := Open(s.dir)
+	s.Require().NoError(err)
+
+	key := testArtifactKey()
+	store.Set(context.Background(), key, "test-token", []byte("artifact-contents"))
+	s.Require().NoError(store.Close())
+
+	reopened, err := Open(s.dir)
+	s.Require().NoError(err)
+	defer reopened.Close()
+
+	contents, ok := reopened.Get(context.Background(), key, "test-token")
+	s.Require().True(ok)
+	s.Assert().Equal([]byte("artifact-contents"), contents)
+}
+
+func (s *StoreTestSuite) Test_reports_missing_artifact() {
+	store, err := Open(s.dir)
+	s.Require().NoError(err)
+	defer store.Close()
+
+	_, ok := store.Get(context.Background(), testArtifactKey(), "test-token")
+	s.Assert().False(ok)
+}
+
+func (s *StoreTestSuite) Test_deletes_artifacts_for_repository() {
+	store, err := Open(s.dir)
+	s.Require().NoError(err)
+	defer store.Close()
+
+	key := testArtifactKey()
+	otherRepoKey := testArtifactKey()
+	otherRepoKey.Repository = "bluelink-provider-example-2"
+	store.Set(context.Background(), key, "test-token", []byte("artifact-contents"))
+	store.Set(context.Background(), otherRepoKey, "test-token", []byte("other-contents"))
+
+	// Owner and repository names are case-insensitive.
+	s.Require().NoError(store.DeleteRepository("Newstack-Cloud", "Bluelink-Provider-Example"))
+
+	_, ok := store.Get(context.Background(), key, "test-token")
+	s.Assert().False(ok)
+
+	contents, ok := store.Get(context.Background(), otherRepoKey, "test-token")
+	s.Require().True(ok)
+	s.Assert().Equal([]byte("other-contents"), contents)
+}
+
+func testArtifactKey() *utils.ArtifactKey {
+	return &utils.ArtifactKey{
+		Owner:      "newstack-cloud",
+		Repository: "bluelink-provider-example",
+		Tag:        "v1.0.1",
+		AssetID:    8,
+		Kind:       utils.ArtifactKindRegistryInfo,
+	}
+}
+
+func TestStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(StoreTestSuite))
+}
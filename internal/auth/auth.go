@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated is returned when a client request
+	// does not have valid credentials.
+	ErrUnauthenticated = errors.New("request is not authenticated")
+
+	// ErrForbidden is returned when an authenticated client is not
+	// allowed to access the requested plugin.
+	ErrForbidden = errors.New("request is not allowed to access this plugin")
+
+	// ErrNoInstallation is returned when the registry does not have
+	// credentials to access repositories for an owner.
+	ErrNoInstallation = errors.New("no GitHub App installation configured for owner")
+)
+
+// Principal holds information about an authenticated client.
+type Principal struct {
+	// Subject identifies the client that made the request.
+	Subject string
+	// GitHubToken is the GitHub token provided by the client,
+	// this is only set when GitHub tokens are passed through
+	// from the client.
+	GitHubToken string
+	// Scope restricts the plugins that the client can access,
+	// when nil, the client can access all plugins.
+	Scope *Scope
+}
+
+// Resource holds information about the plugin that a client
+// request is for.
+type Resource struct {
+	Owner  string
+	Plugin string
+	// Version and Asset are only set for requests
+	// to download a release asset.
+	Version string
+	Asset   string
+}
+
+// Authenticator provides an interface for authenticating
+// client requests to the registry.
+type Authenticator interface {
+	// Authenticate authenticates a client request, returning
+	// ErrUnauthenticated when the request does not have valid credentials.
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+// TokenSource provides an interface for a source of GitHub tokens
+// used to make requests to GitHub on behalf of a client.
+type TokenSource interface {
+	// Token returns the GitHub token to use for requests
+	// for repositories that belong to the provided owner.
+	Token(ctx context.Context, principal *Principal, owner string) (string, error)
+}
+
+// TokenResolver provides an interface for resolving the GitHub token
+// to use to fulfil a client request to the registry.
+type TokenResolver interface {
+	// ResolveToken authenticates a client request and returns the GitHub
+	// token to use for requests to GitHub for the provided resource.
+	ResolveToken(req *http.Request, resource *Resource) (string, error)
+}
+
+type tokenResolver struct {
+	authenticator Authenticator
+	tokenSource   TokenSource
+}
+
+// NewTokenResolver creates a new token resolver that authenticates client
+// requests with the provided authenticator and retrieves GitHub tokens
+// from the provided token source.
+func NewTokenResolver(
+	authenticator Authenticator,
+	tokenSource TokenSource,
+) TokenResolver {
+	return &tokenResolver{
+		authenticator: authenticator,
+		tokenSource:   tokenSource,
+	}
+}
+
+func (r *tokenResolver) ResolveToken(
+	req *http.Request,
+	resource *Resource,
+) (string, error) {
+	principal, err := r.authenticator.Authenticate(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !principal.Scope.Allows(resource) {
+		return "", ErrForbidden
+	}
+
+	return r.tokenSource.Token(req.Context(), principal, resource.Owner)
+}
+
+type chainAuthenticator struct {
+	authenticators []Authenticator
+}
+
+// NewChainAuthenticator creates an authenticator that tries each of the
+// provided authenticators in order, authenticating the request with the
+// first authenticator that accepts the credentials in the request.
+func NewChainAuthenticator(authenticators ...Authenticator) Authenticator {
+	return &chainAuthenticator{
+		authenticators: authenticators,
+	}
+}
+
+func (a *chainAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	for _, authenticator := range a.authenticators {
+		principal, err := authenticator.Authenticate(req)
+		if err == nil {
+			return principal, nil
+		}
+
+		if !errors.Is(err, ErrUnauthenticated) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+type passthroughAuthenticator struct {
+	tokenHeader string
+}
+
+// NewPassthroughAuthenticator creates an authenticator that expects
+// clients to provide a GitHub token in the provided header.
+// The token is not validated by the authenticator, it is passed through
+// to GitHub which will reject requests made with an invalid token.
+func NewPassthroughAuthenticator(tokenHeader string) Authenticator {
+	return &passthroughAuthenticator{
+		tokenHeader: tokenHeader,
+	}
+}
+
+func (a *passthroughAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token := strings.TrimSpace(req.Header.Get(a.tokenHeader))
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	return &Principal{
+		GitHubToken: token,
+	}, nil
+}
+
+type passthroughTokenSource struct{}
+
+// NewPassthroughTokenSource creates a token source that uses
+// the GitHub token provided by the client.
+func NewPassthroughTokenSource() TokenSource {
+	return &passthroughTokenSource{}
+}
+
+func (s *passthroughTokenSource) Token(
+	ctx context.Context,
+	principal *Principal,
+	owner string,
+) (string, error) {
+	if principal.GitHubToken == "" {
+		return "", ErrUnauthenticated
+	}
+
+	return principal.GitHubToken, nil
+}
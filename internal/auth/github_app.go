@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+)
+
+const (
+	// GitHub rejects app JWTs that expire more than
+	// 10 minutes in the future.
+	appJWTLifetime = 9 * time.Minute
+	// The issued at time of app JWTs is set in the past
+	// to allow for clock drift between the registry and GitHub.
+	appJWTClockDrift = 60 * time.Second
+	// Installation tokens are refreshed this long before they expire
+	// so tokens are not used right at the end of their lifetime.
+	installationTokenRefreshWindow = 5 * time.Minute
+)
+
+// GitHubAppConfig holds the configuration for authenticating
+// as a GitHub App installation.
+type GitHubAppConfig struct {
+	AppID      int64
+	PrivateKey *rsa.PrivateKey
+	// InstallationIDs maps the owners (organisations or users) of plugin
+	// repositories to the ID of the app installation for the owner.
+	InstallationIDs map[string]int64
+}
+
+type installationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+type gitHubAppTokenSource struct {
+	config          *GitHubAppConfig
+	installationIDs map[string]int64
+	httpClient      *http.Client
+	baseURL         *url.URL
+	clock           func() time.Time
+	mu              sync.Mutex
+	tokens          map[int64]*installationToken
+	// Token requests are serialised for each installation so that
+	// concurrent client requests share a single newly minted token.
+	installationLocks map[int64]*sync.Mutex
+}
+
+// GitHubAppTokenSourceOption is a function that configures
+// a GitHub App token source.
+type GitHubAppTokenSourceOption func(*gitHubAppTokenSource)
+
+// WithGitHubAppHTTPClient configures the HTTP client used
+// to request installation tokens from the GitHub API.
+func WithGitHubAppHTTPClient(httpClient *http.Client) GitHubAppTokenSourceOption {
+	return func(s *gitHubAppTokenSource) {
+		s.httpClient = httpClient
+	}
+}
+
+// WithGitHubAppBaseURL configures the base URL of the GitHub API
+// used to request installation tokens.
+func WithGitHubAppBaseURL(baseURL *url.URL) GitHubAppTokenSourceOption {
+	return func(s *gitHubAppTokenSource) {
+		s.baseURL = baseURL
+	}
+}
+
+// WithGitHubAppClock configures the function used to get the
+// current time, this is primarily useful for tests.
+func WithGitHubAppClock(clock func() time.Time) GitHubAppTokenSourceOption {
+	return func(s *gitHubAppTokenSource) {
+		s.clock = clock
+	}
+}
+
+// NewGitHubAppTokenSource creates a token source that authenticates
+// as a GitHub App and uses installation tokens for the installation
+// configured for the owner of the repositories being accessed.
+// Installation tokens are cached and refreshed shortly before they expire.
+// ErrNoInstallation is returned for owners without a configured installation.
+func NewGitHubAppTokenSource(
+	config *GitHubAppConfig,
+	opts ...GitHubAppTokenSourceOption,
+) TokenSource {
+	installationIDs := map[string]int64{}
+	for owner, installationID := range config.InstallationIDs {
+		installationIDs[strings.ToLower(owner)] = installationID
+	}
+
+	source := &gitHubAppTokenSource{
+		config:            config,
+		installationIDs:   installationIDs,
+		httpClient:        http.DefaultClient,
+		clock:             time.Now,
+		tokens:            map[int64]*installationToken{},
+		installationLocks: map[int64]*sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(source)
+	}
+
+	return source
+}
+
+func (s *gitHubAppTokenSource) Token(
+	ctx context.Context,
+	principal *Principal,
+	owner string,
+) (string, error) {
+	installationID, hasInstallation := s.installationIDs[strings.ToLower(owner)]
+	if !hasInstallation {
+		return "", ErrNoInstallation
+	}
+
+	installationLock := s.installationLock(installationID)
+	installationLock.Lock()
+	defer installationLock.Unlock()
+
+	if token, isValid := s.cachedToken(installationID); isValid {
+		return token, nil
+	}
+
+	token, err := s.createInstallationToken(ctx, installationID)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.tokens[installationID] = token
+	s.mu.Unlock()
+
+	return token.token, nil
+}
+
+func (s *gitHubAppTokenSource) installationLock(installationID int64) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, exists := s.installationLocks[installationID]
+	if !exists {
+		lock = &sync.Mutex{}
+		s.installationLocks[installationID] = lock
+	}
+	return lock
+}
+
+func (s *gitHubAppTokenSource) cachedToken(installationID int64) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, exists := s.tokens[installationID]
+	if !exists ||
+		s.clock().Add(installationTokenRefreshWindow).After(token.expiresAt) {
+		return "", false
+	}
+
+	return token.token, true
+}
+
+func (s *gitHubAppTokenSource) createInstallationToken(
+	ctx context.Context,
+	installationID int64,
+) (*installationToken, error) {
+	jwt, err := s.appJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	client := github.NewClient(s.httpClient).WithAuthToken(jwt)
+	if s.baseURL != nil {
+		client.BaseURL = s.baseURL
+	}
+
+	token, _, err := client.Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to create token for GitHub App installation %d: %w",
+			installationID,
+			err,
+		)
+	}
+
+	return &installationToken{
+		token:     token.GetToken(),
+		expiresAt: token.GetExpiresAt().Time,
+	}, nil
+}
+
+// appJWT creates a JSON Web Token signed with the app's private key
+// to authenticate as the app when requesting installation tokens.
+func (s *gitHubAppTokenSource) appJWT() (string, error) {
+	now := s.clock()
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockDrift).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.config.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) +
+		"." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(
+		rand.Reader,
+		s.config.PrivateKey,
+		crypto.SHA256,
+		digest[:],
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// LoadGitHubAppPrivateKey loads the PEM encoded RSA private key
+// for a GitHub App from the provided file.
+// GitHub provides app private keys in the PKCS #1 format,
+// keys that have been converted to the PKCS #8 format are also supported.
+func LoadGitHubAppPrivateKey(path string) (*rsa.PrivateKey, error) {
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, errors.New("GitHub App private key file does not contain a PEM encoded key")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, isRSAKey := key.(*rsa.PrivateKey)
+	if !isRSAKey {
+		return nil, errors.New("GitHub App private key must be an RSA key")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GitHubAppTokenSourceTestSuite struct {
+	suite.Suite
+	privateKey *rsa.PrivateKey
+	server     *httptest.Server
+	now        time.Time
+	mu         sync.Mutex
+	requests   []string
+	source     TokenSource
+}
+
+func (s *GitHubAppTokenSourceTestSuite) SetupSuite() {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.privateKey = privateKey
+}
+
+func (s *GitHubAppTokenSourceTestSuite) SetupTest() {
+	s.now = time.Now()
+	s.requests = []string{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handleCreateToken))
+
+	baseURL, err := url.Parse(s.server.URL + "/")
+	s.Require().NoError(err)
+	s.source = NewGitHubAppTokenSource(
+		&GitHubAppConfig{
+			AppID:      1234,
+			PrivateKey: s.privateKey,
+			InstallationIDs: map[string]int64{
+				"Newstack-Cloud": 5678,
+			},
+		},
+		WithGitHubAppBaseURL(baseURL),
+		WithGitHubAppClock(func() time.Time {
+			return s.now
+		}),
+	)
+}
+
+func (s *GitHubAppTokenSourceTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *GitHubAppTokenSourceTestSuite) Test_creates_and_caches_installation_token() {
+	for range 3 {
+		token, err := s.source.Token(context.Background(), &Principal{}, "newstack-cloud")
+		s.Require().NoError(err)
+		s.Assert().Equal("ghs_installation-token-1", token)
+	}
+
+	s.Assert().Equal([]string{"/app/installations/5678/access_tokens"}, s.requests)
+}
+
+func (s *GitHubAppTokenSourceTestSuite) Test_refreshes_token_before_it_expires() {
+	_, err := s.source.Token(context.Background(), &Principal{}, "newstack-cloud")
+	s.Require().NoError(err)
+
+	s.now = s.now.Add(56 * time.Minute)
+	token, err := s.source.Token(context.Background(), &Principal{}, "newstack-cloud")
+	s.Require().NoError(err)
+	s.Assert().Equal("ghs_installation-token-2", token)
+}
+
+func (s *GitHubAppTokenSourceTestSuite) Test_returns_error_for_owner_without_installation() {
+	_, err := s.source.Token(context.Background(), &Principal{}, "other-owner")
+	s.Assert().ErrorIs(err, ErrNoInstallation)
+	s.Assert().Empty(s.requests)
+}
+
+func (s *GitHubAppTokenSourceTestSuite) Test_loads_pkcs1_and_pkcs8_private_keys() {
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(s.privateKey)
+	s.Require().NoError(err)
+
+	keys := map[string]*pem.Block{
+		"pkcs1.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(s.privateKey)},
+		"pkcs8.pem": {Type: "PRIVATE KEY", Bytes: pkcs8Bytes},
+	}
+	for name, block := range keys {
+		path := filepath.Join(s.T().TempDir(), name)
+		s.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+
+		key, err := LoadGitHubAppPrivateKey(path)
+		s.Require().NoError(err)
+		s.Assert().True(s.privateKey.Equal(key))
+	}
+}
+
+func (s *GitHubAppTokenSourceTestSuite) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	s.Require().Equal(http.MethodPost, r.Method)
+	s.verifyAppJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	tokenNumber := len(s.requests)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":      fmt.Sprintf("ghs_installation-token-%d", tokenNumber),
+		"expires_at": s.now.Add(time.Hour).Format(time.RFC3339),
+	})
+}
+
+func (s *GitHubAppTokenSourceTestSuite) verifyAppJWT(jwt string) {
+	parts := strings.Split(jwt, ".")
+	s.Require().Len(parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	s.Require().NoError(err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	s.Require().NoError(
+		rsa.VerifyPKCS1v15(&s.privateKey.PublicKey, crypto.SHA256, digest[:], signature),
+	)
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	s.Require().NoError(err)
+	claims := map[string]any{}
+	s.Require().NoError(json.Unmarshal(claimsBytes, &claims))
+	s.Assert().Equal("1234", claims["iss"])
+}
+
+func TestGitHubAppTokenSourceTestSuite(t *testing.T) {
+	suite.Run(t, new(GitHubAppTokenSourceTestSuite))
+}
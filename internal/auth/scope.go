@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"path"
+	"strings"
+)
+
+// Scope restricts the plugins that a client can access.
+type Scope struct {
+	// Organisations holds the owners of plugin repositories
+	// that can be accessed, matched case-insensitively.
+	// An empty list allows access to plugins for all owners.
+	Organisations []string `json:"organisations,omitempty"`
+	// Plugins holds glob patterns (e.g. "aws" or "aws-*") for the names
+	// of plugins that can be accessed, see path.Match for the syntax.
+	// An empty list allows access to all plugins.
+	Plugins []string `json:"plugins,omitempty"`
+}
+
+// Allows determines whether the scope allows access to the provided
+// resource, a nil scope allows access to all resources.
+func (s *Scope) Allows(resource *Resource) bool {
+	if s == nil {
+		return true
+	}
+
+	return s.allowsOrganisation(resource.Owner) &&
+		s.allowsPlugin(resource.Plugin)
+}
+
+func (s *Scope) allowsOrganisation(owner string) bool {
+	if len(s.Organisations) == 0 {
+		return true
+	}
+
+	for _, organisation := range s.Organisations {
+		if strings.EqualFold(organisation, owner) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Scope) allowsPlugin(plugin string) bool {
+	if len(s.Plugins) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.Plugins {
+		if matched, _ := path.Match(pattern, plugin); matched {
+			return true
+		}
+	}
+
+	return false
+}
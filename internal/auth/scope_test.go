@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScopeTestSuite struct {
+	suite.Suite
+}
+
+func (s *ScopeTestSuite) Test_allows_resources_within_scope() {
+	scope := &Scope{
+		Organisations: []string{"newstack-cloud"},
+		Plugins:       []string{"aws", "azure-*"},
+	}
+
+	s.Assert().True(scope.Allows(&Resource{Owner: "Newstack-Cloud", Plugin: "aws"}))
+	s.Assert().True(scope.Allows(&Resource{Owner: "newstack-cloud", Plugin: "azure-storage"}))
+	s.Assert().False(scope.Allows(&Resource{Owner: "newstack-cloud", Plugin: "gcp"}))
+	s.Assert().False(scope.Allows(&Resource{Owner: "other-org", Plugin: "aws"}))
+}
+
+func (s *ScopeTestSuite) Test_empty_and_nil_scopes_allow_all_resources() {
+	var nilScope *Scope
+	for _, scope := range []*Scope{nilScope, {}} {
+		s.Assert().True(scope.Allows(&Resource{Owner: "other-org", Plugin: "gcp"}))
+	}
+}
+
+func (s *ScopeTestSuite) Test_token_resolver_rejects_resources_outside_of_scope() {
+	resolver := NewTokenResolver(
+		&scopedAuthenticator{
+			scope: &Scope{Organisations: []string{"newstack-cloud"}},
+		},
+		NewPassthroughTokenSource(),
+	)
+
+	_, err := resolver.ResolveToken(
+		nil,
+		&Resource{Owner: "other-org", Plugin: "aws"},
+	)
+	s.Assert().ErrorIs(err, ErrForbidden)
+}
+
+type scopedAuthenticator struct {
+	scope *Scope
+}
+
+func (a *scopedAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	return &Principal{
+		GitHubToken: "test-token",
+		Scope:       a.scope,
+	}, nil
+}
+
+func TestScopeTestSuite(t *testing.T) {
+	suite.Run(t, new(ScopeTestSuite))
+}
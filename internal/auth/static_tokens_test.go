@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StaticTokenAuthenticatorTestSuite struct {
+	suite.Suite
+	authenticator Authenticator
+}
+
+func (s *StaticTokenAuthenticatorTestSuite) SetupTest() {
+	s.authenticator = NewStaticTokenAuthenticator(
+		"bluelink-gh-registry-token",
+		[]string{"client-token-1", " client-token-2 ", ""},
+	)
+}
+
+func (s *StaticTokenAuthenticatorTestSuite) Test_authenticates_configured_tokens() {
+	for _, token := range []string{"client-token-1", "client-token-2"} {
+		principal, err := s.authenticator.Authenticate(s.request(token))
+		s.Require().NoError(err)
+		s.Assert().Contains(principal.Subject, "client-token:")
+		s.Assert().Empty(principal.GitHubToken)
+	}
+}
+
+func (s *StaticTokenAuthenticatorTestSuite) Test_rejects_unknown_and_missing_tokens() {
+	for _, token := range []string{"other-token", ""} {
+		_, err := s.authenticator.Authenticate(s.request(token))
+		s.Assert().ErrorIs(err, ErrUnauthenticated)
+	}
+}
+
+func (s *StaticTokenAuthenticatorTestSuite) request(token string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/plugins", nil)
+	s.Require().NoError(err)
+	if token != "" {
+		req.Header.Set("bluelink-gh-registry-token", token)
+	}
+	return req
+}
+
+func TestStaticTokenAuthenticatorTestSuite(t *testing.T) {
+	suite.Run(t, new(StaticTokenAuthenticatorTestSuite))
+}
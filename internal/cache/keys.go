@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// TokenHash produces a hash of a token that can be used
+// as a part of a cache key to make sure that entries fetched
+// with one token are never served to a caller with a different token.
+// The raw token is never stored in the cache.
+func TokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Key joins the provided parts into a single cache key.
+func Key(parts ...string) string {
+	return strings.Join(parts, "::")
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store provides an interface for a key/value store
+// that holds entries for a limited amount of time.
+type Store interface {
+	// Get retrieves the value for the given key,
+	// the second return value will be false if there is no
+	// entry for the key or the entry has expired.
+	Get(key string) (any, bool)
+
+	// Set stores a value for the given key that will expire
+	// after the provided time-to-live.
+	Set(key string, value any, ttl time.Duration)
+
+	// Delete removes the entry for the given key, if one exists.
+	Delete(key string)
+
+	// DeletePrefix removes all entries that have keys
+	// that start with the provided prefix.
+	DeletePrefix(prefix string)
+}
+
+const (
+	// The number of writes to the in-memory store between
+	// sweeps that remove expired entries.
+	sweepInterval = 1000
+)
+
+type inMemoryEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+type inMemoryStore struct {
+	mu         sync.RWMutex
+	entries    map[string]*inMemoryEntry
+	writes     int
+	maxEntries int
+	clock      func() time.Time
+}
+
+// InMemoryStoreOption is a function that configures an in-memory store.
+type InMemoryStoreOption func(*inMemoryStore)
+
+// WithInMemoryStoreClock configures the function used to determine
+// the current time for an in-memory store.
+// This is primarily useful for testing expiry behaviour.
+func WithInMemoryStoreClock(clock func() time.Time) InMemoryStoreOption {
+	return func(s *inMemoryStore) {
+		s.clock = clock
+	}
+}
+
+// WithInMemoryStoreMaxEntries configures the maximum number of entries
+// held in an in-memory store, when the store is full, expired entries are
+// removed and then the entry that is closest to expiring is evicted to make
+// room for a new entry.
+// The number of entries is not limited when this is not set.
+func WithInMemoryStoreMaxEntries(maxEntries int) InMemoryStoreOption {
+	return func(s *inMemoryStore) {
+		s.maxEntries = maxEntries
+	}
+}
+
+// NewInMemoryStore creates a new instance of a store
+// that holds entries in memory for the lifetime of the process.
+func NewInMemoryStore(opts ...InMemoryStoreOption) Store {
+	store := &inMemoryStore{
+		entries: map[string]*inMemoryEntry{},
+		clock:   time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+func (s *inMemoryStore) Get(key string) (any, bool) {
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	if !s.clock().Before(entry.expiresAt) {
+		s.Delete(key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (s *inMemoryStore) Set(key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock()
+	if _, exists := s.entries[key]; !exists && s.isFull() {
+		s.removeExpired(now)
+		if s.isFull() {
+			s.evictNextToExpire()
+		}
+	}
+
+	s.entries[key] = &inMemoryEntry{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+
+	s.writes += 1
+	if s.writes >= sweepInterval {
+		s.removeExpired(now)
+		s.writes = 0
+	}
+}
+
+func (s *inMemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+func (s *inMemoryStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// This must be called while holding a lock.
+func (s *inMemoryStore) isFull() bool {
+	return s.maxEntries > 0 && len(s.entries) >= s.maxEntries
+}
+
+// This must be called while holding the write lock.
+func (s *inMemoryStore) evictNextToExpire() {
+	nextKey := ""
+	var nextExpiresAt time.Time
+	for key, entry := range s.entries {
+		if nextKey == "" || entry.expiresAt.Before(nextExpiresAt) {
+			nextKey = key
+			nextExpiresAt = entry.expiresAt
+		}
+	}
+
+	delete(s.entries, nextKey)
+}
+
+// This must be called while holding the write lock.
+func (s *inMemoryStore) removeExpired(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InMemoryStoreTestSuite struct {
+	suite.Suite
+	now   time.Time
+	store Store
+}
+
+func (s *InMemoryStoreTestSuite) SetupTest() {
+	s.now = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.store = NewInMemoryStore(
+		WithInMemoryStoreClock(func() time.Time {
+			return s.now
+		}),
+	)
+}
+
+func (s *InMemoryStoreTestSuite) Test_returns_stored_value_before_expiry() {
+	s.store.Set("key-1", "value-1", time.Minute)
+
+	s.now = s.now.Add(30 * time.Second)
+	value, ok := s.store.Get("key-1")
+	s.Require().True(ok)
+	s.Assert().Equal("value-1", value)
+}
+
+func (s *InMemoryStoreTestSuite) Test_does_not_return_expired_value() {
+	s.store.Set("key-1", "value-1", time.Minute)
+
+	s.now = s.now.Add(time.Minute)
+	_, ok := s.store.Get("key-1")
+	s.Assert().False(ok)
+}
+
+func (s *InMemoryStoreTestSuite) Test_does_not_store_value_with_zero_ttl() {
+	s.store.Set("key-1", "value-1", 0)
+
+	_, ok := s.store.Get("key-1")
+	s.Assert().False(ok)
+}
+
+func (s *InMemoryStoreTestSuite) Test_evicts_entry_closest_to_expiry_when_full() {
+	store := NewInMemoryStore(
+		WithInMemoryStoreClock(func() time.Time {
+			return s.now
+		}),
+		WithInMemoryStoreMaxEntries(2),
+	)
+	store.Set("key-1", "value-1", 2*time.Minute)
+	store.Set("key-2", "value-2", time.Minute)
+	// Replacing an existing entry does not evict another entry.
+	store.Set("key-1", "value-1-updated", 2*time.Minute)
+	store.Set("key-3", "value-3", 3*time.Minute)
+
+	value, ok := store.Get("key-1")
+	s.Require().True(ok)
+	s.Assert().Equal("value-1-updated", value)
+	_, ok = store.Get("key-2")
+	s.Assert().False(ok)
+	_, ok = store.Get("key-3")
+	s.Assert().True(ok)
+}
+
+func (s *InMemoryStoreTestSuite) Test_removes_expired_entries_before_evicting_when_full() {
+	store := NewInMemoryStore(
+		WithInMemoryStoreClock(func() time.Time {
+			return s.now
+		}),
+		WithInMemoryStoreMaxEntries(2),
+	)
+	store.Set("key-1", "value-1", time.Minute)
+	store.Set("key-2", "value-2", 5*time.Minute)
+
+	s.now = s.now.Add(2 * time.Minute)
+	store.Set("key-3", "value-3", time.Minute)
+
+	_, ok := store.Get("key-2")
+	s.Assert().True(ok)
+	_, ok = store.Get("key-3")
+	s.Assert().True(ok)
+}
+
+func (s *InMemoryStoreTestSuite) Test_deletes_entries_with_prefix() {
+	s.store.Set(Key("releases", "org-1", "repo-1", "a"), "value-1", time.Minute)
+	s.store.Set(Key("releases", "org-1", "repo-1", "b"), "value-2", time.Minute)
+	s.store.Set(Key("releases", "org-1", "repo-10", "a"), "value-3", time.Minute)
+
+	s.store.DeletePrefix(Key("releases", "org-1", "repo-1", ""))
+
+	_, ok := s.store.Get(Key("releases", "org-1", "repo-1", "a"))
+	s.Assert().False(ok)
+	_, ok = s.store.Get(Key("releases", "org-1", "repo-1", "b"))
+	s.Assert().False(ok)
+	value, ok := s.store.Get(Key("releases", "org-1", "repo-10", "a"))
+	s.Require().True(ok)
+	s.Assert().Equal("value-3", value)
+}
+
+func (s *InMemoryStoreTestSuite) Test_produces_distinct_token_hashes() {
+	s.Assert().NotEqual(TokenHash("token-1"), TokenHash("token-2"))
+	s.Assert().Equal(TokenHash("token-1"), TokenHash("token-1"))
+	s.Assert().NotContains(TokenHash("token-1"), "token-1")
+}
+
+func TestInMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryStoreTestSuite))
+}
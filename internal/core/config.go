@@ -5,18 +5,93 @@ import "github.com/caarlos0/env/v11"
 // Config holds the configuration for the github
 // registry service.
 type Config struct {
-	Port                    int    `env:"BLUELINK_GITHUB_REGISTRY_PORT" envDefault:"8085"`
-	AuthTokenHeader         string `env:"BLUELINK_GITHUB_REGISTRY_AUTH_TOKEN_HEADER" envDefault:"bluelink-gh-registry-token"`
-	RegistryBaseURL         string `env:"BLUELINK_GITHUB_REGISTRY_BASE_URL"`
-	PublicSigningKeysString string `env:"BLUELINK_GITHUB_REGISTRY_SIGNING_PUBLIC_KEYS"`
-	HTTPClientTimeout       int    `env:"BLUELINK_GITHUB_REGISTRY_HTTP_CLIENT_TIMEOUT" envDefault:"60"`
-	LoggingLevel            string `env:"BLUELINK_GITHUB_REGISTRY_LOGGING_LEVEL" envDefault:"info"`
-	Environment             string `env:"BLUELINK_GITHUB_REGISTRY_ENVIRONMENT" envDefault:"production"`
-	AccessLogFile           string `env:"BLUELINK_GITHUB_REGISTRY_ACCESS_LOG_FILE"`
-	OutputLogFile           string `env:"BLUELINK_GITHUB_REGISTRY_OUTPUT_LOG_FILE"`
-	ErrorLogFile            string `env:"BLUELINK_GITHUB_REGISTRY_ERROR_LOG_FILE"`
+	Port                    int               `env:"BLUELINK_GITHUB_REGISTRY_PORT" envDefault:"8085"`
+	AuthTokenHeader         string            `env:"BLUELINK_GITHUB_REGISTRY_AUTH_TOKEN_HEADER" envDefault:"bluelink-gh-registry-token"`
+	AuthMode                string            `env:"BLUELINK_GITHUB_REGISTRY_AUTH_MODE" envDefault:"passthrough"`
+	ClientTokens            []string          `env:"BLUELINK_GITHUB_REGISTRY_CLIENT_TOKENS"`
+	APIKeysFile             string            `env:"BLUELINK_GITHUB_REGISTRY_API_KEYS_FILE"`
+	OIDCIssuer              string            `env:"BLUELINK_GITHUB_REGISTRY_OIDC_ISSUER"`
+	OIDCAudience            string            `env:"BLUELINK_GITHUB_REGISTRY_OIDC_AUDIENCE"`
+	OIDCJWKSURL             string            `env:"BLUELINK_GITHUB_REGISTRY_OIDC_JWKS_URL"`
+	OIDCJWKSFile            string            `env:"BLUELINK_GITHUB_REGISTRY_OIDC_JWKS_FILE"`
+	OIDCPolicyFile          string            `env:"BLUELINK_GITHUB_REGISTRY_OIDC_POLICY_FILE"`
+	GitHubAppID             int64             `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_APP_ID"`
+	GitHubAppPrivateKeyFile string            `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_APP_PRIVATE_KEY_FILE"`
+	GitHubAppInstallations  map[string]int64  `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_APP_INSTALLATION_IDS" envKeyValSeparator:":"`
+	RegistryBaseURL         string            `env:"BLUELINK_GITHUB_REGISTRY_BASE_URL"`
+	DownloadProxyEnabled    bool              `env:"BLUELINK_GITHUB_REGISTRY_DOWNLOAD_PROXY_ENABLED" envDefault:"false"`
+	DownloadURLSigningKeys  []string          `env:"BLUELINK_GITHUB_REGISTRY_DOWNLOAD_URL_SIGNING_KEYS"`
+	DownloadURLTTL          int               `env:"BLUELINK_GITHUB_REGISTRY_DOWNLOAD_URL_TTL" envDefault:"300"`
+	PublicSigningKeysString string            `env:"BLUELINK_GITHUB_REGISTRY_SIGNING_PUBLIC_KEYS"`
+	HTTPClientTimeout       int               `env:"BLUELINK_GITHUB_REGISTRY_HTTP_CLIENT_TIMEOUT" envDefault:"60"`
+	LoggingLevel            string            `env:"BLUELINK_GITHUB_REGISTRY_LOGGING_LEVEL" envDefault:"info"`
+	Environment             string            `env:"BLUELINK_GITHUB_REGISTRY_ENVIRONMENT" envDefault:"production"`
+	AccessLogFile           string            `env:"BLUELINK_GITHUB_REGISTRY_ACCESS_LOG_FILE"`
+	OutputLogFile           string            `env:"BLUELINK_GITHUB_REGISTRY_OUTPUT_LOG_FILE"`
+	ErrorLogFile            string            `env:"BLUELINK_GITHUB_REGISTRY_ERROR_LOG_FILE"`
+	CacheEnabled            bool              `env:"BLUELINK_GITHUB_REGISTRY_CACHE_ENABLED" envDefault:"true"`
+	CacheRepoTTL            int               `env:"BLUELINK_GITHUB_REGISTRY_CACHE_REPO_TTL" envDefault:"300"`
+	CacheReleasesTTL        int               `env:"BLUELINK_GITHUB_REGISTRY_CACHE_RELEASES_TTL" envDefault:"60"`
+	CacheRegistryInfoTTL    int               `env:"BLUELINK_GITHUB_REGISTRY_CACHE_REGISTRY_INFO_TTL" envDefault:"86400"`
+	CacheSHASumsTTL         int               `env:"BLUELINK_GITHUB_REGISTRY_CACHE_SHASUMS_TTL" envDefault:"86400"`
+	CacheMaxEntries         int               `env:"BLUELINK_GITHUB_REGISTRY_CACHE_MAX_ENTRIES" envDefault:"10000"`
+	ArtifactStoreDir        string            `env:"BLUELINK_GITHUB_REGISTRY_ARTIFACT_STORE_DIR"`
+	RegistryInfoConcurrency int               `env:"BLUELINK_GITHUB_REGISTRY_REGISTRY_INFO_FETCH_CONCURRENCY" envDefault:"8"`
+	RepoLookupMode          string            `env:"BLUELINK_GITHUB_REGISTRY_REPO_LOOKUP_MODE" envDefault:"direct"`
+	RepoNameTemplate        string            `env:"BLUELINK_GITHUB_REGISTRY_REPO_NAME_TEMPLATE" envDefault:"bluelink-{type}-{plugin}"`
+	ArchiveNameTemplate     string            `env:"BLUELINK_GITHUB_REGISTRY_ARCHIVE_NAME_TEMPLATE" envDefault:"{repo}_{version}_{os}_{arch}"`
+	ChecksumsNameTemplate   string            `env:"BLUELINK_GITHUB_REGISTRY_CHECKSUMS_NAME_TEMPLATE" envDefault:"{repo}_{version}_SHA256SUMS"`
+	ArchiveFormats          []string          `env:"BLUELINK_GITHUB_REGISTRY_ARCHIVE_FORMATS" envDefault:"zip,tar.gz"`
+	OperatingSystems        []string          `env:"BLUELINK_GITHUB_REGISTRY_OPERATING_SYSTEMS" envDefault:"linux,windows,darwin,freebsd"`
+	OperatingSystemAliases  map[string]string `env:"BLUELINK_GITHUB_REGISTRY_OPERATING_SYSTEM_ALIASES" envKeyValSeparator:":"`
+	Architectures           []string          `env:"BLUELINK_GITHUB_REGISTRY_ARCHITECTURES" envDefault:"amd64,arm64,arm,386"`
+	ArchitectureAliases     map[string]string `env:"BLUELINK_GITHUB_REGISTRY_ARCHITECTURE_ALIASES" envKeyValSeparator:":" envDefault:"x86_64:amd64,aarch64:arm64"`
+	DefaultReleaseChannel   string            `env:"BLUELINK_GITHUB_REGISTRY_DEFAULT_RELEASE_CHANNEL" envDefault:"stable"`
+	IncludeDraftReleases    bool              `env:"BLUELINK_GITHUB_REGISTRY_INCLUDE_DRAFT_RELEASES" envDefault:"false"`
+	GitHubAPI               string            `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_API" envDefault:"rest"`
+	GitHubPageSize          int               `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_PAGE_SIZE" envDefault:"100"`
+	GitHubMaxPages          int               `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_MAX_PAGES" envDefault:"50"`
+	ConditionalRequests     bool              `env:"BLUELINK_GITHUB_REGISTRY_CONDITIONAL_REQUESTS_ENABLED" envDefault:"true"`
+	ETagCacheTTL            int               `env:"BLUELINK_GITHUB_REGISTRY_ETAG_CACHE_TTL" envDefault:"86400"`
+	ETagCacheMaxEntries     int               `env:"BLUELINK_GITHUB_REGISTRY_ETAG_CACHE_MAX_ENTRIES" envDefault:"10000"`
+	GitHubWebhookSecret     string            `env:"BLUELINK_GITHUB_REGISTRY_GITHUB_WEBHOOK_SECRET"`
+	WebhookPrewarmToken     string            `env:"BLUELINK_GITHUB_REGISTRY_WEBHOOK_PREWARM_TOKEN"`
+	RateLimitMaxRetries     int               `env:"BLUELINK_GITHUB_REGISTRY_RATE_LIMIT_MAX_RETRIES" envDefault:"2"`
+	RateLimitMaxRetryWait   int               `env:"BLUELINK_GITHUB_REGISTRY_RATE_LIMIT_MAX_RETRY_WAIT" envDefault:"10"`
+	HTTPRetryMaxAttempts    int               `env:"BLUELINK_GITHUB_REGISTRY_HTTP_RETRY_MAX_ATTEMPTS" envDefault:"3"`
+	CircuitBreakerThreshold int               `env:"BLUELINK_GITHUB_REGISTRY_CIRCUIT_BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+	CircuitBreakerOpenTime  int               `env:"BLUELINK_GITHUB_REGISTRY_CIRCUIT_BREAKER_OPEN_DURATION" envDefault:"30"`
 }
 
+const (
+	// AuthModePassthrough is the auth mode where clients provide a GitHub
+	// token that is passed through to make requests to GitHub.
+	AuthModePassthrough = "passthrough"
+	// AuthModeGitHubApp is the auth mode where the registry authenticates
+	// with GitHub as a GitHub App installation and clients authenticate
+	// with the registry separately.
+	AuthModeGitHubApp = "github_app"
+)
+
+const (
+	// RepoLookupModeDirect is the repository lookup mode where the
+	// candidate repository names for a plugin are fetched directly.
+	RepoLookupModeDirect = "direct"
+	// RepoLookupModeList is the repository lookup mode where all the
+	// repositories for the owner are listed and searched for the
+	// plugin repository.
+	RepoLookupModeList = "list"
+)
+
+const (
+	// GitHubAPIREST is the GitHub API option where repositories
+	// and releases are fetched from the GitHub REST API.
+	GitHubAPIREST = "rest"
+	// GitHubAPIGraphQL is the GitHub API option where repositories
+	// and releases are fetched from the GitHub GraphQL API.
+	GitHubAPIGraphQL = "graphql"
+)
+
 // LoadConfigFromEnv loads the application
 // configuration from environment variables.
 func LoadConfigFromEnv() (Config, error) {
@@ -0,0 +1,38 @@
+package httputils
+
+import "net/http"
+
+// ClientFunc is an adapter that allows the use of an ordinary
+// function as a Client.
+type ClientFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f ClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewTransportClient creates a Client that sends requests with the
+// provided http.RoundTripper, this allows client decorators to be
+// composed with transports such as the ETag transport.
+func NewTransportClient(transport http.RoundTripper) Client {
+	return ClientFunc(transport.RoundTrip)
+}
+
+type clientTransport struct {
+	client Client
+}
+
+// NewClientTransport creates a http.RoundTripper that sends requests
+// with the provided Client.
+// This allows a decorated Client to be used as the transport of a
+// http.Client for packages that only have interoperability with the
+// built-in http.Client.
+func NewClientTransport(client Client) http.RoundTripper {
+	return &clientTransport{
+		client: client,
+	}
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Do(req)
+}
@@ -0,0 +1,209 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitBreakerFailureThreshold is the default number of
+	// consecutive failed requests to a host before the circuit is opened.
+	DefaultCircuitBreakerFailureThreshold = 5
+	// DefaultCircuitBreakerOpenDuration is the default amount of time
+	// that requests to a host are rejected for once the circuit is opened.
+	DefaultCircuitBreakerOpenDuration = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when a request is rejected without being
+// sent because too many requests to the same host have failed recently.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type requestOutcome int
+
+const (
+	requestSucceeded requestOutcome = iota
+	requestFailed
+	// requestCancelled is the outcome of a request that failed
+	// because the caller cancelled it.
+	requestCancelled
+)
+
+type hostCircuit struct {
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+type circuitBreakerClient struct {
+	client           Client
+	failureThreshold int
+	openDuration     time.Duration
+	clock            func() time.Time
+	mu               sync.Mutex
+	circuits         map[string]*hostCircuit
+}
+
+// CircuitBreakerClientOption is a function that configures
+// a circuit breaker client.
+type CircuitBreakerClientOption func(*circuitBreakerClient)
+
+// WithCircuitBreakerFailureThreshold configures the number of consecutive
+// failed requests to a host before the circuit is opened.
+func WithCircuitBreakerFailureThreshold(threshold int) CircuitBreakerClientOption {
+	return func(c *circuitBreakerClient) {
+		c.failureThreshold = threshold
+	}
+}
+
+// WithCircuitBreakerOpenDuration configures the amount of time that
+// requests to a host are rejected for once the circuit is opened.
+func WithCircuitBreakerOpenDuration(openDuration time.Duration) CircuitBreakerClientOption {
+	return func(c *circuitBreakerClient) {
+		c.openDuration = openDuration
+	}
+}
+
+// WithCircuitBreakerClock configures the function used to get
+// the current time, this is primarily useful for tests.
+func WithCircuitBreakerClock(clock func() time.Time) CircuitBreakerClientOption {
+	return func(c *circuitBreakerClient) {
+		c.clock = clock
+	}
+}
+
+// NewCircuitBreakerClient creates a Client that keeps a circuit breaker
+// for each host that requests are sent to.
+// Connection errors, timeouts and 5xx responses count as failures, once the
+// failure threshold is reached, requests to the host fail fast with
+// ErrCircuitOpen for the open duration.
+// Requests cancelled by the caller do not count as failures.
+// After the open duration, a single trial request is allowed through,
+// the circuit is closed if it succeeds and opened again if it fails
+// or is cancelled before an outcome is known.
+func NewCircuitBreakerClient(
+	client Client,
+	opts ...CircuitBreakerClientOption,
+) Client {
+	breaker := &circuitBreakerClient{
+		client:           client,
+		failureThreshold: DefaultCircuitBreakerFailureThreshold,
+		openDuration:     DefaultCircuitBreakerOpenDuration,
+		clock:            time.Now,
+		circuits:         map[string]*hostCircuit{},
+	}
+
+	for _, opt := range opts {
+		opt(breaker)
+	}
+
+	return breaker
+}
+
+func (c *circuitBreakerClient) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	allowed, isTrial := c.allow(host)
+	if !allowed {
+		return nil, fmt.Errorf("request to %q rejected: %w", host, ErrCircuitOpen)
+	}
+
+	resp, err := c.client.Do(req)
+	c.record(host, isTrial, outcome(req, resp, err))
+
+	return resp, err
+}
+
+// allow determines whether a request to the host can be sent
+// and whether the request is the trial request for a half-open circuit.
+func (c *circuitBreakerClient) allow(host string) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	circuit, exists := c.circuits[host]
+	if !exists {
+		return true, false
+	}
+
+	switch circuit.state {
+	case circuitOpen:
+		if c.clock().Sub(circuit.openedAt) < c.openDuration {
+			return false, false
+		}
+		// Only the first request after the open duration
+		// is allowed through as a trial.
+		circuit.state = circuitHalfOpen
+		return true, true
+	case circuitHalfOpen:
+		return false, false
+	default:
+		return true, false
+	}
+}
+
+func (c *circuitBreakerClient) record(host string, isTrial bool, outcome requestOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if outcome == requestSucceeded {
+		delete(c.circuits, host)
+		return
+	}
+
+	// A cancelled request does not reflect the health of the host,
+	// but the trial request must always be settled, otherwise the
+	// circuit would stay half-open and reject all requests.
+	if outcome == requestCancelled && !isTrial {
+		return
+	}
+
+	circuit, exists := c.circuits[host]
+	if !exists {
+		circuit = &hostCircuit{}
+		c.circuits[host] = circuit
+	}
+
+	if outcome == requestCancelled {
+		circuit.state = circuitOpen
+		circuit.openedAt = c.clock()
+		return
+	}
+
+	circuit.consecutiveFailures += 1
+	if isTrial || circuit.consecutiveFailures >= c.failureThreshold {
+		circuit.state = circuitOpen
+		circuit.openedAt = c.clock()
+	}
+}
+
+func outcome(req *http.Request, resp *http.Response, err error) requestOutcome {
+	if err == nil {
+		if resp.StatusCode >= 500 {
+			return requestFailed
+		}
+		return requestSucceeded
+	}
+
+	// Requests that exceeded a deadline are failures as the host took
+	// too long to respond, only requests that the caller gave up on
+	// are not counted.
+	if errors.Is(req.Context().Err(), context.Canceled) {
+		return requestCancelled
+	}
+
+	return requestFailed
+}
+
+func isCircuitOpenError(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
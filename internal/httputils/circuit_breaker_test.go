@@ -0,0 +1,152 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CircuitBreakerClientTestSuite struct {
+	suite.Suite
+	now      time.Time
+	failing  bool
+	requests map[string]int
+	client   Client
+}
+
+func (s *CircuitBreakerClientTestSuite) SetupTest() {
+	s.now = time.Now()
+	s.failing = true
+	s.requests = map[string]int{}
+	s.client = NewCircuitBreakerClient(
+		ClientFunc(func(req *http.Request) (*http.Response, error) {
+			s.requests[req.URL.Host] += 1
+			if req.Context().Err() != nil {
+				return nil, req.Context().Err()
+			}
+			if s.failing {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		WithCircuitBreakerFailureThreshold(2),
+		WithCircuitBreakerOpenDuration(time.Minute),
+		WithCircuitBreakerClock(func() time.Time {
+			return s.now
+		}),
+	)
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_opens_circuit_after_consecutive_failures() {
+	for range 2 {
+		_, err := s.send("api.github.com")
+		s.Require().Error(err)
+		s.Assert().NotErrorIs(err, ErrCircuitOpen)
+	}
+
+	_, err := s.send("api.github.com")
+	s.Assert().ErrorIs(err, ErrCircuitOpen)
+	s.Assert().Equal(2, s.requests["api.github.com"])
+
+	// Circuits are kept separately for each host.
+	_, err = s.send("objects.githubusercontent.com")
+	s.Assert().NotErrorIs(err, ErrCircuitOpen)
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_closes_circuit_after_successful_trial_request() {
+	for range 2 {
+		s.send("api.github.com")
+	}
+
+	s.now = s.now.Add(2 * time.Minute)
+	s.failing = false
+	resp, err := s.send("api.github.com")
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+
+	s.failing = true
+	_, err = s.send("api.github.com")
+	s.Assert().NotErrorIs(err, ErrCircuitOpen)
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_reopens_circuit_after_failed_trial_request() {
+	for range 2 {
+		s.send("api.github.com")
+	}
+
+	s.now = s.now.Add(2 * time.Minute)
+	_, err := s.send("api.github.com")
+	s.Assert().NotErrorIs(err, ErrCircuitOpen)
+
+	_, err = s.send("api.github.com")
+	s.Assert().ErrorIs(err, ErrCircuitOpen)
+	s.Assert().Equal(3, s.requests["api.github.com"])
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_reopens_circuit_after_cancelled_trial_request() {
+	for range 2 {
+		s.send("api.github.com")
+	}
+
+	s.now = s.now.Add(2 * time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.sendWithContext(ctx, "api.github.com")
+	s.Require().ErrorIs(err, context.Canceled)
+
+	_, err = s.send("api.github.com")
+	s.Assert().ErrorIs(err, ErrCircuitOpen)
+
+	// A new trial request is allowed after the open duration
+	// instead of the circuit being stuck half-open.
+	s.now = s.now.Add(2 * time.Minute)
+	s.failing = false
+	resp, err := s.send("api.github.com")
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_does_not_count_cancelled_requests_as_failures() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 3 {
+		_, err := s.sendWithContext(ctx, "api.github.com")
+		s.Require().ErrorIs(err, context.Canceled)
+	}
+
+	_, err := s.send("api.github.com")
+	s.Assert().NotErrorIs(err, ErrCircuitOpen)
+}
+
+func (s *CircuitBreakerClientTestSuite) Test_counts_exceeded_deadlines_as_failures() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	for range 2 {
+		_, err := s.sendWithContext(ctx, "api.github.com")
+		s.Require().ErrorIs(err, context.DeadlineExceeded)
+	}
+
+	_, err := s.send("api.github.com")
+	s.Assert().ErrorIs(err, ErrCircuitOpen)
+}
+
+func (s *CircuitBreakerClientTestSuite) send(host string) (*http.Response, error) {
+	return s.sendWithContext(context.Background(), host)
+}
+
+func (s *CircuitBreakerClientTestSuite) sendWithContext(
+	ctx context.Context,
+	host string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+"/repos", nil)
+	s.Require().NoError(err)
+	return s.client.Do(req)
+}
+
+func TestCircuitBreakerClientTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerClientTestSuite))
+}
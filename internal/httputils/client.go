@@ -27,6 +27,34 @@ func WithNativeHTTPClientTimeout(timeout int) NativeHTTPClientOptions {
 	}
 }
 
+// WithNativeHTTPClientTransport configures a http.Client instance
+// with a custom transport.
+func WithNativeHTTPClientTransport(transport http.RoundTripper) NativeHTTPClientOptions {
+	return func(c *http.Client) {
+		c.Transport = transport
+	}
+}
+
+// WithNativeHTTPClientNoTimeout configures a http.Client instance without
+// an overall timeout for requests, this is for requests with response bodies
+// that are streamed for an unbounded amount of time, the transport should
+// be configured with timeouts for connecting and waiting for a response.
+func WithNativeHTTPClientNoTimeout() NativeHTTPClientOptions {
+	return func(c *http.Client) {
+		c.Timeout = 0
+	}
+}
+
+// WithNativeHTTPClientNoRedirects configures a http.Client instance
+// to return redirect responses to the caller instead of following them.
+func WithNativeHTTPClientNoRedirects() NativeHTTPClientOptions {
+	return func(c *http.Client) {
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+}
+
 // NewNativeHTTPClient creates a new instance of a HTTP client
 // configured with a timeout.
 // This is to be used with packages that only have interoperability
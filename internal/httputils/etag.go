@@ -0,0 +1,244 @@
+package httputils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+)
+
+const (
+	// DefaultETagMaxBodySize is the default maximum size in bytes
+	// of a response body that will be stored for conditional requests.
+	DefaultETagMaxBodySize = 5 * 1024 * 1024
+)
+
+// DefaultETagHosts holds the hosts that conditional requests are made to
+// when hosts are not configured.
+// Only requests to the GitHub REST API are conditional, requests for release
+// assets are redirected to unique presigned URLs that are never requested
+// more than once, so storing the responses would only waste memory.
+var DefaultETagHosts = []string{"api.github.com"}
+
+// Headers from a 304 Not Modified response that should replace the
+// stored headers when the stored response is reused, this ensures that
+// clients see up to date rate limit information.
+var notModifiedPassthroughHeaders = []string{
+	"Date",
+	"X-Ratelimit-Limit",
+	"X-Ratelimit-Remaining",
+	"X-Ratelimit-Reset",
+	"X-Ratelimit-Used",
+	"X-Ratelimit-Resource",
+}
+
+type etagEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+type etagTransport struct {
+	base        http.RoundTripper
+	store       cache.Store
+	ttl         time.Duration
+	maxBodySize int
+	hosts       []string
+}
+
+// ETagTransportOption is a function that configures an ETag transport.
+type ETagTransportOption func(*etagTransport)
+
+// WithETagMaxBodySize configures the maximum size in bytes of a response
+// body that will be stored to be reused for conditional requests.
+func WithETagMaxBodySize(maxBodySize int) ETagTransportOption {
+	return func(t *etagTransport) {
+		t.maxBodySize = maxBodySize
+	}
+}
+
+// WithETagHosts configures the hosts that conditional requests are made to,
+// requests to any other host are passed through without storing the response.
+func WithETagHosts(hosts ...string) ETagTransportOption {
+	return func(t *etagTransport) {
+		t.hosts = hosts
+	}
+}
+
+// NewETagTransport creates a http.RoundTripper that makes conditional
+// GET requests using the ETag of a previous response for the same URL.
+// When the server responds with 304 Not Modified, the stored response
+// body is returned to the caller as a 200 response.
+// GitHub does not count 304 responses against the rate limit of a token.
+//
+// Stored responses are keyed by a hash of the Authorization header
+// of the request, so a response fetched with one token is never served
+// for a request made with a different token.
+func NewETagTransport(
+	base http.RoundTripper,
+	store cache.Store,
+	ttl time.Duration,
+	opts ...ETagTransportOption,
+) http.RoundTripper {
+	transport := &etagTransport{
+		base:        base,
+		store:       store,
+		ttl:         ttl,
+		maxBodySize: DefaultETagMaxBodySize,
+		hosts:       DefaultETagHosts,
+	}
+
+	for _, opt := range opts {
+		opt(transport)
+	}
+
+	return transport
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.isConditionalCandidate(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	key := etagKey(req)
+	entry := t.getEntry(key)
+	outgoing := req
+	if entry != nil {
+		outgoing = req.Clone(req.Context())
+		outgoing.Header.Set("If-None-Match", entry.etag)
+	}
+
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		return notModifiedResponse(req, resp, entry), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	return t.storeResponse(key, etag, resp)
+}
+
+func (t *etagTransport) getEntry(key string) *etagEntry {
+	value, ok := t.store.Get(key)
+	if !ok {
+		return nil
+	}
+
+	entry, isEntry := value.(*etagEntry)
+	if !isEntry {
+		return nil
+	}
+
+	return entry
+}
+
+func (t *etagTransport) storeResponse(
+	key string,
+	etag string,
+	resp *http.Response,
+) (*http.Response, error) {
+	if resp.ContentLength > int64(t.maxBodySize) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBodySize)+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if len(body) > t.maxBodySize {
+		// The body is too large to be stored, the part of the body
+		// that has already been read is stitched back together with the
+		// remainder so the caller receives the full response.
+		resp.Body = &multiReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			closer: resp.Body,
+		}
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.store.Set(
+		key,
+		&etagEntry{
+			etag:   etag,
+			header: resp.Header.Clone(),
+			body:   body,
+		},
+		t.ttl,
+	)
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func notModifiedResponse(
+	req *http.Request,
+	notModified *http.Response,
+	entry *etagEntry,
+) *http.Response {
+	header := entry.header.Clone()
+	for _, name := range notModifiedPassthroughHeaders {
+		if value := notModified.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}
+}
+
+func (t *etagTransport) isConditionalCandidate(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		slices.Contains(t.hosts, req.URL.Host) &&
+		// Release asset downloads are requested from the REST API with
+		// an Accept header for binary content, only JSON responses
+		// are stored.
+		!strings.Contains(req.Header.Get("Accept"), "application/octet-stream") &&
+		// Partial content requests are always passed through
+		// as only complete response bodies are stored.
+		req.Header.Get("Range") == "" &&
+		// Requests that are already conditional are left to the caller.
+		req.Header.Get("If-None-Match") == ""
+}
+
+func etagKey(req *http.Request) string {
+	return cache.Key(
+		"etag",
+		req.URL.String(),
+		req.Header.Get("Accept"),
+		req.Header.Get("X-GitHub-Api-Version"),
+		cache.TokenHash(req.Header.Get("Authorization")),
+	)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (m *multiReadCloser) Close() error {
+	return m.closer.Close()
+}
@@ -0,0 +1,145 @@
+package httputils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+	"github.com/stretchr/testify/suite"
+)
+
+type ETagTransportTestSuite struct {
+	suite.Suite
+	server       *httptest.Server
+	fullCount    atomic.Int32
+	notModCount  atomic.Int32
+	client       *http.Client
+	responseBody string
+}
+
+func (s *ETagTransportTestSuite) SetupTest() {
+	s.fullCount.Store(0)
+	s.notModCount.Store(0)
+	s.responseBody = `{"releases":[]}`
+	s.server = httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				etag := `"etag-` + r.Header.Get("Authorization") + `"`
+				if r.Header.Get("If-None-Match") == etag {
+					s.notModCount.Add(1)
+					w.Header().Set("X-RateLimit-Remaining", "4999")
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				s.fullCount.Add(1)
+				w.Header().Set("ETag", etag)
+				w.Header().Set("X-RateLimit-Remaining", "4998")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(s.responseBody))
+			},
+		),
+	)
+	s.client = s.createClient(s.serverHost())
+}
+
+func (s *ETagTransportTestSuite) createClient(hosts ...string) *http.Client {
+	return NewNativeHTTPClient(
+		WithNativeHTTPClientTransport(
+			NewETagTransport(
+				http.DefaultTransport,
+				cache.NewInMemoryStore(),
+				time.Hour,
+				WithETagHosts(hosts...),
+			),
+		),
+	)
+}
+
+func (s *ETagTransportTestSuite) serverHost() string {
+	serverURL, err := url.Parse(s.server.URL)
+	s.Require().NoError(err)
+	return serverURL.Host
+}
+
+func (s *ETagTransportTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ETagTransportTestSuite) Test_reuses_stored_body_on_not_modified_response() {
+	for range 3 {
+		resp := s.get("Bearer token-1")
+		s.Assert().Equal(http.StatusOK, resp.StatusCode)
+		s.Assert().Equal(s.responseBody, s.readBody(resp))
+	}
+
+	s.Assert().Equal(int32(1), s.fullCount.Load())
+	s.Assert().Equal(int32(2), s.notModCount.Load())
+}
+
+func (s *ETagTransportTestSuite) Test_passes_through_latest_rate_limit_headers() {
+	s.readBody(s.get("Bearer token-1"))
+	resp := s.get("Bearer token-1")
+	s.readBody(resp)
+
+	s.Assert().Equal("4999", resp.Header.Get("X-RateLimit-Remaining"))
+}
+
+func (s *ETagTransportTestSuite) Test_does_not_share_stored_responses_between_tokens() {
+	s.readBody(s.get("Bearer token-1"))
+	s.readBody(s.get("Bearer token-2"))
+
+	s.Assert().Equal(int32(2), s.fullCount.Load())
+	s.Assert().Equal(int32(0), s.notModCount.Load())
+}
+
+func (s *ETagTransportTestSuite) Test_does_not_store_responses_for_other_hosts() {
+	s.client = s.createClient("api.github.com")
+
+	for range 3 {
+		s.readBody(s.get("Bearer token-1"))
+	}
+
+	s.Assert().Equal(int32(3), s.fullCount.Load())
+	s.Assert().Equal(int32(0), s.notModCount.Load())
+}
+
+func (s *ETagTransportTestSuite) Test_does_not_store_responses_for_binary_content() {
+	for range 3 {
+		s.readBody(s.getWithAccept("Bearer token-1", "application/octet-stream"))
+	}
+
+	s.Assert().Equal(int32(3), s.fullCount.Load())
+	s.Assert().Equal(int32(0), s.notModCount.Load())
+}
+
+func (s *ETagTransportTestSuite) get(authorization string) *http.Response {
+	return s.getWithAccept(authorization, "application/vnd.github+json")
+}
+
+func (s *ETagTransportTestSuite) getWithAccept(authorization string, accept string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, s.server.URL, nil)
+	s.Require().NoError(err)
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", accept)
+
+	resp, err := s.client.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *ETagTransportTestSuite) readBody(resp *http.Response) string {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	return string(body)
+}
+
+func TestETagTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(ETagTransportTestSuite))
+}
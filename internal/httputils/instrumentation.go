@@ -0,0 +1,58 @@
+package httputils
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestOutcome holds information about a request
+// sent by an instrumented client.
+type RequestOutcome struct {
+	Method string
+	Host   string
+	Path   string
+	// StatusCode is the status code of the response,
+	// this will be 0 when the request failed without a response.
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// RequestObserver is a function that is called with the outcome
+// of each request sent by an instrumented client.
+type RequestObserver func(outcome *RequestOutcome)
+
+type instrumentedClient struct {
+	client   Client
+	observer RequestObserver
+}
+
+// NewInstrumentedClient creates a Client that reports the latency
+// and outcome of each request to the provided observer.
+// The duration covers the time taken to receive the response headers,
+// it does not include the time taken to read the response body.
+func NewInstrumentedClient(client Client, observer RequestObserver) Client {
+	return &instrumentedClient{
+		client:   client,
+		observer: observer,
+	}
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.client.Do(req)
+
+	outcome := &RequestOutcome{
+		Method:   req.Method,
+		Host:     req.URL.Host,
+		Path:     req.URL.Path,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if resp != nil {
+		outcome.StatusCode = resp.StatusCode
+	}
+	c.observer(outcome)
+
+	return resp, err
+}
@@ -0,0 +1,44 @@
+package httputils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InstrumentedClientTestSuite struct {
+	suite.Suite
+}
+
+func (s *InstrumentedClientTestSuite) Test_reports_request_outcome() {
+	outcomes := []*RequestOutcome{}
+	client := NewInstrumentedClient(
+		ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+		}),
+		func(outcome *RequestOutcome) {
+			outcomes = append(outcomes, outcome)
+		},
+	)
+
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"https://api.github.com/repos/newstack-cloud/bluelink-provider-aws",
+		nil,
+	)
+	s.Require().NoError(err)
+
+	_, err = client.Do(req)
+	s.Require().NoError(err)
+	s.Require().Len(outcomes, 1)
+	s.Assert().Equal(http.MethodGet, outcomes[0].Method)
+	s.Assert().Equal("api.github.com", outcomes[0].Host)
+	s.Assert().Equal("/repos/newstack-cloud/bluelink-provider-aws", outcomes[0].Path)
+	s.Assert().Equal(http.StatusNotFound, outcomes[0].StatusCode)
+	s.Assert().NoError(outcomes[0].Err)
+}
+
+func TestInstrumentedClientTestSuite(t *testing.T) {
+	suite.Run(t, new(InstrumentedClientTestSuite))
+}
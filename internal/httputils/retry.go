@@ -0,0 +1,134 @@
+package httputils
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the default maximum number of attempts
+	// for a request, including the initial attempt.
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryBaseDelay is the default delay that the exponential
+	// backoff between retries starts from.
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	// DefaultRetryMaxDelay is the default maximum delay between retries.
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+type retryingClient struct {
+	client      Client
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// RetryingClientOption is a function that configures a retrying client.
+type RetryingClientOption func(*retryingClient)
+
+// WithRetryMaxAttempts configures the maximum number of attempts
+// for a request, including the initial attempt.
+func WithRetryMaxAttempts(maxAttempts int) RetryingClientOption {
+	return func(c *retryingClient) {
+		c.maxAttempts = maxAttempts
+	}
+}
+
+// WithRetryBaseDelay configures the delay that the exponential
+// backoff between retries starts from.
+func WithRetryBaseDelay(baseDelay time.Duration) RetryingClientOption {
+	return func(c *retryingClient) {
+		c.baseDelay = baseDelay
+	}
+}
+
+// WithRetryMaxDelay configures the maximum delay between retries.
+func WithRetryMaxDelay(maxDelay time.Duration) RetryingClientOption {
+	return func(c *retryingClient) {
+		c.maxDelay = maxDelay
+	}
+}
+
+// NewRetryingClient creates a Client that retries idempotent requests
+// (GET and HEAD) that fail with a connection error or a 5xx response.
+// Retries are made with an exponential backoff with full jitter,
+// so that many clients retrying at the same time are spread out.
+//
+// Requests are not retried when the request context has been
+// cancelled or when the circuit for the host is open.
+func NewRetryingClient(client Client, opts ...RetryingClientOption) Client {
+	retrying := &retryingClient{
+		client:      client,
+		maxAttempts: DefaultRetryMaxAttempts,
+		baseDelay:   DefaultRetryBaseDelay,
+		maxDelay:    DefaultRetryMaxDelay,
+	}
+
+	for _, opt := range opts {
+		opt(retrying)
+	}
+
+	return retrying
+}
+
+func (c *retryingClient) Do(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return c.client.Do(req)
+	}
+
+	for attempt := 1; ; attempt += 1 {
+		resp, err := c.client.Do(req)
+		if attempt >= c.maxAttempts || !c.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// The body must be drained and closed so the connection
+			// can be reused for the next attempt.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+func (c *retryingClient) shouldRetry(
+	req *http.Request,
+	resp *http.Response,
+	err error,
+) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !isCircuitOpenError(err)
+	}
+
+	return resp.StatusCode >= 500
+}
+
+// backoff returns a random delay between zero and the exponential
+// backoff for the provided attempt, capped at the maximum delay.
+func (c *retryingClient) backoff(attempt int) time.Duration {
+	delay := min(c.baseDelay<<(attempt-1), c.maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay)
+}
+
+func isIdempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet ||
+		req.Method == http.MethodHead ||
+		// An empty method is treated as GET by the http package.
+		req.Method == ""
+}
@@ -0,0 +1,153 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RetryingClientTestSuite struct {
+	suite.Suite
+}
+
+func (s *RetryingClientTestSuite) Test_retries_server_errors_until_success() {
+	var requests atomic.Int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) < 3 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewRetryingClient(
+		http.DefaultClient,
+		WithRetryMaxAttempts(3),
+		WithRetryBaseDelay(time.Millisecond),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	s.Require().NoError(err)
+
+	resp, err := client.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(int32(3), requests.Load())
+}
+
+func (s *RetryingClientTestSuite) Test_returns_last_response_after_max_attempts() {
+	var requests atomic.Int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewRetryingClient(
+		http.DefaultClient,
+		WithRetryMaxAttempts(2),
+		WithRetryBaseDelay(time.Millisecond),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	s.Require().NoError(err)
+
+	resp, err := client.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Assert().Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	s.Assert().Equal(int32(2), requests.Load())
+}
+
+func (s *RetryingClientTestSuite) Test_does_not_retry_client_errors_or_non_idempotent_requests() {
+	var requests atomic.Int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewRetryingClient(
+		http.DefaultClient,
+		WithRetryBaseDelay(time.Millisecond),
+	)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, err := http.NewRequest(method, server.URL, nil)
+		s.Require().NoError(err)
+
+		resp, err := client.Do(req)
+		s.Require().NoError(err)
+		resp.Body.Close()
+	}
+
+	s.Assert().Equal(int32(2), requests.Load())
+}
+
+func (s *RetryingClientTestSuite) Test_retries_connection_errors() {
+	attempts := 0
+	client := NewRetryingClient(
+		ClientFunc(func(req *http.Request) (*http.Response, error) {
+			attempts += 1
+			return nil, errors.New("connection reset by peer")
+		}),
+		WithRetryMaxAttempts(3),
+		WithRetryBaseDelay(time.Millisecond),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com", nil)
+	s.Require().NoError(err)
+
+	_, err = client.Do(req)
+	s.Require().Error(err)
+	s.Assert().Equal(3, attempts)
+}
+
+func (s *RetryingClientTestSuite) Test_stops_retrying_when_context_is_cancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	client := NewRetryingClient(
+		ClientFunc(func(req *http.Request) (*http.Response, error) {
+			attempts += 1
+			cancel()
+			return nil, errors.New("connection reset by peer")
+		}),
+		WithRetryMaxAttempts(3),
+		WithRetryBaseDelay(time.Millisecond),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com", nil)
+	s.Require().NoError(err)
+
+	_, err = client.Do(req)
+	s.Require().Error(err)
+	s.Assert().Equal(1, attempts)
+}
+
+func TestRetryingClientTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryingClientTestSuite))
+}
@@ -0,0 +1,107 @@
+package oidc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"go.uber.org/zap"
+)
+
+type oidcAuthenticator struct {
+	tokenHeader string
+	verifier    Verifier
+	policy      *Policy
+	logger      *zap.Logger
+}
+
+// NewAuthenticator creates an authenticator that expects clients to
+// provide a JSON Web Token issued by an OpenID Connect provider
+// (e.g. GitHub Actions) in the provided header.
+// The scope of the principal is determined by the first rule in the policy
+// that matches the claims of the token, requests with valid tokens that do
+// not match any rule are rejected with auth.ErrForbidden.
+//
+// Values in the header that are not JSON Web Tokens are ignored so other
+// authenticators can be chained after this one.
+func NewAuthenticator(
+	tokenHeader string,
+	verifier Verifier,
+	policy *Policy,
+	logger *zap.Logger,
+) auth.Authenticator {
+	return &oidcAuthenticator{
+		tokenHeader: tokenHeader,
+		verifier:    verifier,
+		policy:      policy,
+		logger:      logger,
+	}
+}
+
+func (a *oidcAuthenticator) Authenticate(req *http.Request) (*auth.Principal, error) {
+	token := strings.TrimSpace(req.Header.Get(a.tokenHeader))
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+	if !looksLikeJWT(token) {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	claims, err := a.verifier.Verify(req.Context(), token)
+	if errors.Is(err, ErrInvalidToken) {
+		a.logger.Debug(
+			"Rejected OIDC token",
+			zap.Error(err),
+			zap.String("path", req.URL.Path),
+		)
+		return nil, auth.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	scope, matches := a.policy.Scope(claims)
+	if !matches {
+		a.logger.Info(
+			"OIDC token does not match any policy rule",
+			zap.String("subject", subject),
+			zap.String("path", req.URL.Path),
+		)
+		return nil, auth.ErrForbidden
+	}
+
+	a.logger.Info(
+		"Authenticated request with OIDC token",
+		zap.String("subject", subject),
+		zap.String("path", req.URL.Path),
+	)
+
+	return &auth.Principal{
+		Subject: "oidc:" + subject,
+		Scope:   scope,
+	}, nil
+}
+
+// looksLikeJWT determines whether a value is a JSON Web Token by
+// checking for three segments where the first is a JSON object
+// with an alg field.
+func looksLikeJWT(value string) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+
+	header := &tokenHeader{}
+	if err := json.Unmarshal(headerBytes, header); err != nil {
+		return false
+	}
+
+	return header.Algorithm != ""
+}
@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+const testTokenHeader = "bluelink-gh-registry-token"
+
+type AuthenticatorTestSuite struct {
+	suite.Suite
+	rsaKey        *rsa.PrivateKey
+	authenticator auth.Authenticator
+}
+
+func (s *AuthenticatorTestSuite) SetupTest() {
+	var err error
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	keySet, err := LoadKeySetFile(writeTestFile(
+		s.T(),
+		"jwks.json",
+		testKeySetJSON(s.T(), map[string]*rsa.PrivateKey{"rsa-key": s.rsaKey}, nil),
+	))
+	s.Require().NoError(err)
+
+	s.authenticator = NewAuthenticator(
+		testTokenHeader,
+		NewVerifier(&VerifierConfig{Issuer: testIssuer, Audience: testAudience}, keySet),
+		&Policy{
+			Rules: []*PolicyRule{
+				{
+					Claims:        map[string]string{"repository_owner": "newstack-cloud"},
+					Organisations: []string{"newstack-cloud"},
+				},
+			},
+		},
+		zap.NewNop(),
+	)
+}
+
+func (s *AuthenticatorTestSuite) Test_authenticates_token_matching_policy() {
+	req := httptest.NewRequest("GET", "/plugins/newstack-cloud/aws/versions", nil)
+	req.Header.Set(testTokenHeader, "Bearer "+s.token("newstack-cloud"))
+
+	principal, err := s.authenticator.Authenticate(req)
+	s.Require().NoError(err)
+	s.Assert().Equal("oidc:repo:newstack-cloud/example:ref:refs/heads/main", principal.Subject)
+	s.Assert().Empty(principal.GitHubToken)
+	s.Assert().Equal(&auth.Scope{Organisations: []string{"newstack-cloud"}}, principal.Scope)
+}
+
+func (s *AuthenticatorTestSuite) Test_rejects_token_not_matching_policy() {
+	req := httptest.NewRequest("GET", "/plugins/other-org/aws/versions", nil)
+	req.Header.Set(testTokenHeader, s.token("other-org"))
+
+	_, err := s.authenticator.Authenticate(req)
+	s.Assert().ErrorIs(err, auth.ErrForbidden)
+}
+
+func (s *AuthenticatorTestSuite) Test_returns_unauthenticated_for_invalid_token() {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	req := httptest.NewRequest("GET", "/plugins/newstack-cloud/aws/versions", nil)
+	req.Header.Set(testTokenHeader, signTestRS256Token(s.T(), otherKey, "rsa-key", s.claims("newstack-cloud")))
+
+	_, err = s.authenticator.Authenticate(req)
+	s.Assert().ErrorIs(err, auth.ErrUnauthenticated)
+}
+
+func (s *AuthenticatorTestSuite) Test_ignores_credentials_that_are_not_tokens() {
+	req := httptest.NewRequest("GET", "/plugins/newstack-cloud/aws/versions", nil)
+	req.Header.Set(testTokenHeader, "blgr_0123456789abcdef")
+
+	_, err := s.authenticator.Authenticate(req)
+	s.Assert().ErrorIs(err, auth.ErrUnauthenticated)
+}
+
+func (s *AuthenticatorTestSuite) token(owner string) string {
+	return signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(owner))
+}
+
+func (s *AuthenticatorTestSuite) claims(owner string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":              testIssuer,
+		"aud":              testAudience,
+		"sub":              "repo:" + owner + "/example:ref:refs/heads/main",
+		"repository_owner": owner,
+		"iat":              now.Unix(),
+		"exp":              now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+func TestAuthenticatorTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthenticatorTestSuite))
+}
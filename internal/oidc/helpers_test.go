@@ -0,0 +1,120 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testIssuer   = "https://token.actions.example.com"
+	testAudience = "bluelink-github-registry"
+)
+
+func signTestRS256Token(
+	t *testing.T,
+	key *rsa.PrivateKey,
+	keyID string,
+	claims map[string]any,
+) string {
+	signingInput := testSigningInput(t, "RS256", keyID, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signTestES256Token(
+	t *testing.T,
+	key *ecdsa.PrivateKey,
+	keyID string,
+	claims map[string]any,
+) string {
+	signingInput := testSigningInput(t, "ES256", keyID, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testSigningInput(
+	t *testing.T,
+	algorithm string,
+	keyID string,
+	claims map[string]any,
+) string {
+	header, err := json.Marshal(map[string]string{
+		"alg": algorithm,
+		"typ": "JWT",
+		"kid": keyID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(header) +
+		"." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func testKeySetJSON(t *testing.T, rsaKeys map[string]*rsa.PrivateKey, ecKeys map[string]*ecdsa.PrivateKey) []byte {
+	keys := []map[string]string{}
+	for keyID, key := range rsaKeys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encodeBigInt(key.N),
+			"e":   encodeBigInt(big.NewInt(int64(key.E))),
+		})
+	}
+	for keyID, key := range ecKeys {
+		keys = append(keys, map[string]string{
+			"kty": "EC",
+			"kid": keyID,
+			"crv": "P-256",
+			"x":   encodeBigInt(key.X),
+			"y":   encodeBigInt(key.Y),
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	filePath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
@@ -0,0 +1,317 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJWKSMinRefreshInterval is the default minimum amount of time
+	// between fetches of a remote key set, this prevents tokens with
+	// unknown key IDs from causing a request to the issuer every time.
+	DefaultJWKSMinRefreshInterval = 5 * time.Minute
+)
+
+// ErrKeyNotFound is returned when a key set does not contain
+// a key with the requested ID.
+var ErrKeyNotFound = errors.New("signing key not found in key set")
+
+// KeySet provides an interface for a set of public keys
+// used to verify the signatures of tokens.
+type KeySet interface {
+	// Key returns the public key with the provided key ID.
+	Key(ctx context.Context, keyID string) (crypto.PublicKey, error)
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+type staticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// LoadKeySetFile loads a JSON Web Key Set from the provided file,
+// this allows tokens to be verified without access to the issuer,
+// for example, for testing.
+func LoadKeySetFile(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &staticKeySet{keys: keys}, nil
+}
+
+func (s *staticKeySet) Key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	key, exists := s.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	return key, nil
+}
+
+type remoteKeySet struct {
+	url                string
+	issuer             string
+	httpClient         *http.Client
+	minRefreshInterval time.Duration
+	clock              func() time.Time
+	mu                 sync.Mutex
+	keys               map[string]crypto.PublicKey
+	fetchedAt          time.Time
+}
+
+// RemoteKeySetOption is a function that configures a remote key set.
+type RemoteKeySetOption func(*remoteKeySet)
+
+// WithRemoteKeySetHTTPClient configures the HTTP client used
+// to fetch the key set.
+func WithRemoteKeySetHTTPClient(httpClient *http.Client) RemoteKeySetOption {
+	return func(s *remoteKeySet) {
+		s.httpClient = httpClient
+	}
+}
+
+// WithRemoteKeySetMinRefreshInterval configures the minimum amount
+// of time between fetches of the key set.
+func WithRemoteKeySetMinRefreshInterval(interval time.Duration) RemoteKeySetOption {
+	return func(s *remoteKeySet) {
+		s.minRefreshInterval = interval
+	}
+}
+
+// NewRemoteKeySet creates a key set that fetches a JSON Web Key Set
+// from the provided URL.
+// The key set is fetched when it is first used and is fetched again
+// when a token is signed with an unknown key, so keys rotated by the
+// issuer are picked up.
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) KeySet {
+	keySet := &remoteKeySet{
+		url:                url,
+		httpClient:         http.DefaultClient,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		clock:              time.Now,
+		keys:               map[string]crypto.PublicKey{},
+	}
+
+	for _, opt := range opts {
+		opt(keySet)
+	}
+
+	return keySet
+}
+
+// NewIssuerKeySet creates a key set that fetches the JSON Web Key Set
+// of an OpenID Connect issuer, the URL of the key set is discovered from
+// the issuer's discovery document when the key set is first used.
+func NewIssuerKeySet(issuer string, opts ...RemoteKeySetOption) KeySet {
+	keySet := NewRemoteKeySet("", opts...).(*remoteKeySet)
+	keySet.issuer = issuer
+	return keySet
+}
+
+func (s *remoteKeySet) Key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, exists := s.keys[keyID]; exists {
+		return key, nil
+	}
+
+	if !s.fetchedAt.IsZero() &&
+		s.clock().Sub(s.fetchedAt) < s.minRefreshInterval {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.keys = keys
+	s.fetchedAt = s.clock()
+
+	key, exists := s.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	return key, nil
+}
+
+func (s *remoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	if s.url == "" {
+		url, err := DiscoverJWKSURL(ctx, s.httpClient, s.issuer)
+		if err != nil {
+			return nil, err
+		}
+		s.url = url
+	}
+
+	data, err := getJSON(ctx, s.httpClient, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseKeySet(data)
+}
+
+// DiscoverJWKSURL fetches the OpenID Connect discovery document
+// for an issuer to find the URL of the issuer's key set.
+func DiscoverJWKSURL(
+	ctx context.Context,
+	httpClient *http.Client,
+	issuer string,
+) (string, error) {
+	data, err := getJSON(
+		ctx,
+		httpClient,
+		strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration",
+	)
+	if err != nil {
+		return "", err
+	}
+
+	discovery := struct {
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	if err := json.Unmarshal(data, &discovery); err != nil {
+		return "", err
+	}
+
+	if discovery.JWKSURI == "" {
+		return "", fmt.Errorf("discovery document for issuer %q has no jwks_uri", issuer)
+	}
+
+	return discovery.JWKSURI, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %q: status code: %s", url, resp.Status)
+	}
+
+	var data json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func parseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	keySet := &jsonWebKeySet{}
+	if err := json.Unmarshal(data, keySet); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON Web Key Set: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range keySet.Keys {
+		// Keys for purposes other than signatures and unsupported
+		// key types are skipped.
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, err := ellipticCurve(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+func ellipticCurve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", name)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
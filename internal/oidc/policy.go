@@ -0,0 +1,136 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+)
+
+// Policy maps the claims of verified tokens to the organisations
+// and plugins that the bearer of the token can access.
+type Policy struct {
+	// Rules are evaluated in order, the first rule that matches
+	// the claims of a token determines the scope of the token.
+	Rules []*PolicyRule `json:"rules"`
+}
+
+// PolicyRule grants access to organisations and plugins
+// for tokens with matching claims.
+type PolicyRule struct {
+	// Claims maps claim names to patterns (e.g. "repo:my-org/*"),
+	// a token matches the rule when the value of every claim matches
+	// the pattern for the claim.
+	// In claim patterns, "*" matches any sequence of characters,
+	// including "/", and "?" matches any single character.
+	// For claims with a list of values, at least one value must match.
+	Claims map[string]string `json:"claims"`
+	// Organisations that tokens matching the rule can access,
+	// all organisations can be accessed when empty.
+	Organisations []string `json:"organisations,omitempty"`
+	// Plugins that tokens matching the rule can access as glob patterns
+	// of plugin names, all plugins can be accessed when empty.
+	Plugins []string `json:"plugins,omitempty"`
+}
+
+// LoadPolicyFile loads and validates a JSON policy from the provided file.
+func LoadPolicyFile(filePath string) (*Policy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC policy file %q: %w", filePath, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid OIDC policy file %q: %w", filePath, err)
+	}
+
+	return policy, nil
+}
+
+// Validate makes sure the policy has at least one rule, that every
+// rule has at least one claim condition and that all the plugin
+// patterns in the policy are valid.
+func (p *Policy) Validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("policy must have at least one rule")
+	}
+
+	for i, rule := range p.Rules {
+		// Requiring claim conditions prevents a rule from granting
+		// access to every token issued by the issuer by accident.
+		if len(rule.Claims) == 0 {
+			return fmt.Errorf("rule %d must have at least one claim condition", i)
+		}
+
+		for _, pattern := range rule.Plugins {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d has an invalid plugin pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Scope returns the scope granted by the first rule that matches
+// the provided claims, returning false if no rule matches.
+func (p *Policy) Scope(claims Claims) (*auth.Scope, bool) {
+	for _, rule := range p.Rules {
+		if rule.matches(claims) {
+			return &auth.Scope{
+				Organisations: rule.Organisations,
+				Plugins:       rule.Plugins,
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *PolicyRule) matches(claims Claims) bool {
+	for claim, pattern := range r.Claims {
+		if !claimMatches(claims[claim], claimPattern(pattern)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// claimPattern converts a claim pattern to a regular expression,
+// unlike plugin patterns, "*" in claim patterns matches "/" as claims
+// such as sub contain paths (e.g. "repo:my-org/my-repo:ref:refs/heads/main").
+func claimPattern(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+func claimMatches(value any, pattern *regexp.Regexp) bool {
+	switch claimValue := value.(type) {
+	case string:
+		return pattern.MatchString(claimValue)
+	case bool, float64:
+		return pattern.MatchString(fmt.Sprint(claimValue))
+	case []any:
+		for _, item := range claimValue {
+			if claimMatches(item, pattern) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
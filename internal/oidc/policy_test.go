@@ -0,0 +1,94 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+	policy *Policy
+}
+
+func (s *PolicyTestSuite) SetupTest() {
+	policy, err := LoadPolicyFile(writeTestFile(s.T(), "policy.json", []byte(`{
+		"rules": [
+			{
+				"claims": {"sub": "repo:newstack-cloud/deploy-*", "environment": "production"},
+				"organisations": ["newstack-cloud"],
+				"plugins": ["aws", "azure-*"]
+			},
+			{
+				"claims": {"repository_owner": "newstack-cloud"},
+				"organisations": ["newstack-cloud"]
+			},
+			{
+				"claims": {"groups": "registry-admins"}
+			}
+		]
+	}`)))
+	s.Require().NoError(err)
+	s.policy = policy
+}
+
+func (s *PolicyTestSuite) Test_first_matching_rule_determines_scope() {
+	scope, matches := s.policy.Scope(Claims{
+		"sub":              "repo:newstack-cloud/deploy-infra:ref:refs/heads/main",
+		"environment":      "production",
+		"repository_owner": "newstack-cloud",
+	})
+	s.Require().True(matches)
+	s.Assert().Equal(
+		&auth.Scope{
+			Organisations: []string{"newstack-cloud"},
+			Plugins:       []string{"aws", "azure-*"},
+		},
+		scope,
+	)
+}
+
+func (s *PolicyTestSuite) Test_all_claim_conditions_must_match() {
+	scope, matches := s.policy.Scope(Claims{
+		"sub":              "repo:newstack-cloud/deploy-infra:ref:refs/heads/main",
+		"environment":      "staging",
+		"repository_owner": "newstack-cloud",
+	})
+	s.Require().True(matches)
+	s.Assert().Equal(&auth.Scope{Organisations: []string{"newstack-cloud"}}, scope)
+}
+
+func (s *PolicyTestSuite) Test_matches_any_value_of_list_claims() {
+	scope, matches := s.policy.Scope(Claims{
+		"groups": []any{"developers", "registry-admins"},
+	})
+	s.Require().True(matches)
+	s.Assert().True(scope.Allows(&auth.Resource{Owner: "other-org", Plugin: "aws"}))
+}
+
+func (s *PolicyTestSuite) Test_does_not_match_claims_without_matching_rule() {
+	_, matches := s.policy.Scope(Claims{
+		"sub":              "repo:other-org/deploy-infra:ref:refs/heads/main",
+		"repository_owner": "other-org",
+	})
+	s.Assert().False(matches)
+}
+
+func (s *PolicyTestSuite) Test_rejects_invalid_policies() {
+	policies := map[string]string{
+		"no rules":         `{"rules": []}`,
+		"no claims":        `{"rules": [{"organisations": ["newstack-cloud"]}]}`,
+		"bad plugin glob":  `{"rules": [{"claims": {"sub": "*"}, "plugins": ["[aws"]}]}`,
+		"malformed policy": `{"rules": `,
+	}
+
+	for name, policy := range policies {
+		_, err := LoadPolicyFile(writeTestFile(s.T(), "policy.json", []byte(policy)))
+		s.Assert().Error(err, name)
+	}
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
@@ -0,0 +1,257 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultClockSkew is the default amount of clock skew allowed
+	// between the registry and the issuer when validating the time
+	// based claims of a token.
+	DefaultClockSkew = 60 * time.Second
+)
+
+// ErrInvalidToken is returned when a token is malformed,
+// has an invalid signature or has claims that do not match
+// the configured issuer and audience.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims holds the claims of a verified token.
+type Claims map[string]any
+
+// VerifierConfig holds the configuration for verifying tokens.
+type VerifierConfig struct {
+	// Issuer is the expected value of the iss claim.
+	Issuer string
+	// Audience is the value that the aud claim
+	// of a token must contain.
+	Audience string
+}
+
+// Verifier provides an interface for verifying signed tokens.
+type Verifier interface {
+	// Verify verifies the signature and standard claims of a token,
+	// returning the claims of the token.
+	Verify(ctx context.Context, token string) (Claims, error)
+}
+
+type verifier struct {
+	config    *VerifierConfig
+	keySet    KeySet
+	clockSkew time.Duration
+	clock     func() time.Time
+}
+
+// VerifierOption is a function that configures a token verifier.
+type VerifierOption func(*verifier)
+
+// WithVerifierClockSkew configures the amount of clock skew allowed
+// when validating the exp, nbf and iat claims of a token.
+func WithVerifierClockSkew(clockSkew time.Duration) VerifierOption {
+	return func(v *verifier) {
+		v.clockSkew = clockSkew
+	}
+}
+
+// WithVerifierClock configures the function used to get the
+// current time, this is primarily useful for tests.
+func WithVerifierClock(clock func() time.Time) VerifierOption {
+	return func(v *verifier) {
+		v.clock = clock
+	}
+}
+
+// NewVerifier creates a verifier for JSON Web Tokens signed with
+// one of the keys in the provided key set.
+// RS256, ES256 and ES384 signed tokens are supported.
+func NewVerifier(
+	config *VerifierConfig,
+	keySet KeySet,
+	opts ...VerifierOption,
+) Verifier {
+	v := &verifier{
+		config:    config,
+		keySet:    keySet,
+		clockSkew: DefaultClockSkew,
+		clock:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+func (v *verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: token must have three parts", ErrInvalidToken)
+	}
+
+	header := &tokenHeader{}
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %w", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature: %w", ErrInvalidToken, err)
+	}
+
+	key, err := v.keySet.Key(ctx, header.KeyID)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	if err := verifySignature(header.Algorithm, key, signingInput, signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %w", ErrInvalidToken, err)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+func (v *verifier) validateClaims(claims Claims) error {
+	if issuer, _ := claims["iss"].(string); issuer != v.config.Issuer {
+		return fmt.Errorf("unexpected issuer %q", issuer)
+	}
+
+	if !slices.Contains(audiences(claims["aud"]), v.config.Audience) {
+		return fmt.Errorf("token is not intended for audience %q", v.config.Audience)
+	}
+
+	now := v.clock()
+	expiresAt, hasExpiry := numericDate(claims["exp"])
+	if !hasExpiry {
+		return errors.New("token does not have an expiry")
+	}
+	if !now.Before(expiresAt.Add(v.clockSkew)) {
+		return errors.New("token has expired")
+	}
+
+	if notBefore, hasNotBefore := numericDate(claims["nbf"]); hasNotBefore &&
+		now.Add(v.clockSkew).Before(notBefore) {
+		return errors.New("token is not valid yet")
+	}
+
+	if issuedAt, hasIssuedAt := numericDate(claims["iat"]); hasIssuedAt &&
+		now.Add(v.clockSkew).Before(issuedAt) {
+		return errors.New("token was issued in the future")
+	}
+
+	return nil
+}
+
+func verifySignature(
+	algorithm string,
+	key crypto.PublicKey,
+	signingInput string,
+	signature []byte,
+) error {
+	switch algorithm {
+	case "RS256":
+		rsaKey, isRSAKey := key.(*rsa.PublicKey)
+		if !isRSAKey {
+			return errors.New("RS256 tokens must be verified with an RSA key")
+		}
+		digest := hashInput(sha256.New(), signingInput)
+		return rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature)
+	case "ES256", "ES384":
+		ecKey, isECKey := key.(*ecdsa.PublicKey)
+		if !isECKey {
+			return fmt.Errorf("%s tokens must be verified with an EC key", algorithm)
+		}
+		hasher := sha256.New()
+		if algorithm == "ES384" {
+			hasher = sha512.New384()
+		}
+		// ECDSA signatures in JSON Web Tokens are the fixed size
+		// concatenation of r and s rather than ASN.1 encoded.
+		keySize := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*keySize {
+			return errors.New("signature has an unexpected length")
+		}
+		r := new(big.Int).SetBytes(signature[:keySize])
+		s := new(big.Int).SetBytes(signature[keySize:])
+		if !ecdsa.Verify(ecKey, hashInput(hasher, signingInput), r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+func hashInput(hasher hash.Hash, input string) []byte {
+	hasher.Write([]byte(input))
+	return hasher.Sum(nil)
+}
+
+func decodeSegment(segment string, target any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, target)
+}
+
+// audiences returns the audiences of a token,
+// the aud claim can be a single string or a list of strings.
+func audiences(value any) []string {
+	switch aud := value.(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		values := []string{}
+		for _, item := range aud {
+			if str, isString := item.(string); isString {
+				values = append(values, str)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func numericDate(value any) (time.Time, bool) {
+	seconds, isNumber := value.(float64)
+	if !isNumber {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
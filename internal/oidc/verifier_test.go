@@ -0,0 +1,180 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifierTestSuite struct {
+	suite.Suite
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	now      time.Time
+	verifier Verifier
+}
+
+func (s *VerifierTestSuite) SetupTest() {
+	var err error
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	keySet, err := LoadKeySetFile(writeTestFile(
+		s.T(),
+		"jwks.json",
+		testKeySetJSON(
+			s.T(),
+			map[string]*rsa.PrivateKey{"rsa-key": s.rsaKey},
+			map[string]*ecdsa.PrivateKey{"ec-key": s.ecKey},
+		),
+	))
+	s.Require().NoError(err)
+
+	s.now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.verifier = NewVerifier(
+		&VerifierConfig{Issuer: testIssuer, Audience: testAudience},
+		keySet,
+		WithVerifierClock(func() time.Time { return s.now }),
+	)
+}
+
+func (s *VerifierTestSuite) Test_verifies_rs256_token() {
+	token := signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(nil))
+
+	claims, err := s.verifier.Verify(context.Background(), token)
+	s.Require().NoError(err)
+	s.Assert().Equal("newstack-cloud", claims["repository_owner"])
+}
+
+func (s *VerifierTestSuite) Test_verifies_es256_token_with_audience_list() {
+	token := signTestES256Token(s.T(), s.ecKey, "ec-key", s.claims(map[string]any{
+		"aud": []string{"other-audience", testAudience},
+	}))
+
+	_, err := s.verifier.Verify(context.Background(), token)
+	s.Require().NoError(err)
+}
+
+func (s *VerifierTestSuite) Test_rejects_invalid_tokens() {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	tokens := map[string]string{
+		"wrong issuer": signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"iss": "https://other-issuer.example.com",
+		})),
+		"wrong audience": signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"aud": "other-audience",
+		})),
+		"expired": signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"exp": s.now.Add(-2 * time.Minute).Unix(),
+		})),
+		"not valid yet": signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"nbf": s.now.Add(5 * time.Minute).Unix(),
+		})),
+		"no expiry": signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"exp": nil,
+		})),
+		"unknown key":    signTestRS256Token(s.T(), s.rsaKey, "unknown-key", s.claims(nil)),
+		"wrong key":      signTestRS256Token(s.T(), otherKey, "rsa-key", s.claims(nil)),
+		"wrong key type": signTestES256Token(s.T(), s.ecKey, "rsa-key", s.claims(nil)),
+		"malformed":      "not.a-valid.token",
+	}
+
+	for name, token := range tokens {
+		_, err := s.verifier.Verify(context.Background(), token)
+		s.Assert().ErrorIs(err, ErrInvalidToken, name)
+	}
+}
+
+func (s *VerifierTestSuite) Test_allows_clock_skew_for_expiry() {
+	token := signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+		"exp": s.now.Add(-30 * time.Second).Unix(),
+	}))
+
+	_, err := s.verifier.Verify(context.Background(), token)
+	s.Require().NoError(err)
+}
+
+func (s *VerifierTestSuite) Test_discovers_and_fetches_issuer_key_set() {
+	requests := map[string]int{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path] += 1
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			w.Write([]byte(`{"jwks_uri": "` + server.URL + `/.well-known/jwks"}`))
+		case "/.well-known/jwks":
+			w.Write(testKeySetJSON(
+				s.T(),
+				map[string]*rsa.PrivateKey{"rsa-key": s.rsaKey},
+				nil,
+			))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	verifier := NewVerifier(
+		&VerifierConfig{Issuer: server.URL, Audience: testAudience},
+		NewIssuerKeySet(server.URL, WithRemoteKeySetHTTPClient(server.Client())),
+		WithVerifierClock(func() time.Time { return s.now }),
+	)
+
+	for range 2 {
+		token := signTestRS256Token(s.T(), s.rsaKey, "rsa-key", s.claims(map[string]any{
+			"iss": server.URL,
+		}))
+		_, err := verifier.Verify(context.Background(), token)
+		s.Require().NoError(err)
+	}
+
+	// A token signed with an unknown key should not cause the key set
+	// to be fetched again within the minimum refresh interval.
+	token := signTestRS256Token(s.T(), s.rsaKey, "unknown-key", s.claims(map[string]any{
+		"iss": server.URL,
+	}))
+	_, err := verifier.Verify(context.Background(), token)
+	s.Assert().ErrorIs(err, ErrInvalidToken)
+
+	s.Assert().Equal(1, requests["/.well-known/openid-configuration"])
+	s.Assert().Equal(1, requests["/.well-known/jwks"])
+}
+
+func (s *VerifierTestSuite) claims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"iss":              testIssuer,
+		"aud":              testAudience,
+		"sub":              "repo:newstack-cloud/example:ref:refs/heads/main",
+		"repository_owner": "newstack-cloud",
+		"iat":              s.now.Add(-time.Minute).Unix(),
+		"nbf":              s.now.Add(-time.Minute).Unix(),
+		"exp":              s.now.Add(5 * time.Minute).Unix(),
+	}
+
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifierTestSuite))
+}
@@ -0,0 +1,104 @@
+package plugins
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+)
+
+// ArtifactCacheTTLs holds the time-to-live values for
+// the different kinds of release artifacts that are cached.
+type ArtifactCacheTTLs struct {
+	RegistryInfo time.Duration
+	SHASums      time.Duration
+}
+
+const (
+	artifactKeyPrefix = "artifact"
+)
+
+type artifactCache struct {
+	store cache.Store
+	ttls  *ArtifactCacheTTLs
+}
+
+// NewArtifactCache creates a new cache for the contents of release
+// artifacts that is backed by the provided store.
+// Entries are keyed by a hash of the caller's token so that artifacts
+// are never served to a caller with a different token.
+func NewArtifactCache(
+	store cache.Store,
+	ttls *ArtifactCacheTTLs,
+) utils.ArtifactCache {
+	return &artifactCache{
+		store: store,
+		ttls:  ttls,
+	}
+}
+
+func (c *artifactCache) Get(
+	ctx context.Context,
+	key *utils.ArtifactKey,
+	token string,
+) ([]byte, bool) {
+	entry, ok := c.store.Get(artifactCacheKey(key, token))
+	if !ok {
+		return nil, false
+	}
+
+	contents, isBytes := entry.([]byte)
+	return contents, isBytes
+}
+
+func (c *artifactCache) Set(
+	ctx context.Context,
+	key *utils.ArtifactKey,
+	token string,
+	contents []byte,
+) {
+	c.store.Set(
+		artifactCacheKey(key, token),
+		contents,
+		c.ttlForKind(key.Kind),
+	)
+}
+
+func (c *artifactCache) ttlForKind(kind utils.ArtifactKind) time.Duration {
+	switch kind {
+	case utils.ArtifactKindRegistryInfo:
+		return c.ttls.RegistryInfo
+	case utils.ArtifactKindSHASums:
+		return c.ttls.SHASums
+	default:
+		return 0
+	}
+}
+
+// InvalidateCachedArtifacts removes all the cached release artifacts
+// for a repository from the store used by an artifact cache, for all tokens.
+func InvalidateCachedArtifacts(store cache.Store, owner string, repo string) {
+	store.DeletePrefix(
+		cache.Key(
+			artifactKeyPrefix,
+			strings.ToLower(owner),
+			strings.ToLower(repo),
+			"",
+		),
+	)
+}
+
+func artifactCacheKey(key *utils.ArtifactKey, token string) string {
+	return cache.Key(
+		artifactKeyPrefix,
+		strings.ToLower(key.Owner),
+		strings.ToLower(key.Repository),
+		key.Tag,
+		strconv.FormatInt(key.AssetID, 10),
+		string(key.Kind),
+		cache.TokenHash(token),
+	)
+}
@@ -0,0 +1,57 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type ArtifactCacheTestSuite struct {
+	suite.Suite
+	artifactCache utils.ArtifactCache
+}
+
+func (s *ArtifactCacheTestSuite) SetupTest() {
+	s.artifactCache = NewArtifactCache(
+		cache.NewInMemoryStore(),
+		&ArtifactCacheTTLs{
+			RegistryInfo: time.Hour,
+			SHASums:      time.Hour,
+		},
+	)
+}
+
+func (s *ArtifactCacheTestSuite) Test_returns_cached_artifact_for_the_same_token() {
+	key := testArtifactKey(utils.ArtifactKindRegistryInfo)
+	s.artifactCache.Set(context.Background(), key, "test-token", registryInfoContents())
+
+	contents, ok := s.artifactCache.Get(context.Background(), key, "test-token")
+	s.Require().True(ok)
+	s.Assert().Equal(registryInfoContents(), contents)
+}
+
+func (s *ArtifactCacheTestSuite) Test_does_not_return_cached_artifact_for_a_different_token() {
+	key := testArtifactKey(utils.ArtifactKindSHASums)
+	s.artifactCache.Set(context.Background(), key, "test-token", packageSHASumContents())
+
+	_, ok := s.artifactCache.Get(context.Background(), key, "other-token")
+	s.Assert().False(ok)
+}
+
+func testArtifactKey(kind utils.ArtifactKind) *utils.ArtifactKey {
+	return &utils.ArtifactKey{
+		Owner:      "newstack-cloud",
+		Repository: "bluelink-provider-example",
+		Tag:        "v1.0.1",
+		AssetID:    8,
+		Kind:       kind,
+	}
+}
+
+func TestArtifactCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ArtifactCacheTestSuite))
+}
@@ -0,0 +1,134 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+)
+
+// Headers from the GitHub response for a release asset
+// that are passed on to clients of the registry.
+var assetDownloadHeaders = []string{
+	"Accept-Ranges",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+// AssetDownloadParams holds the parameters required
+// to download a release asset for a plugin version.
+type AssetDownloadParams struct {
+	Organisation string
+	Plugin       string
+	// PluginType restricts the plugin repository to the repository for
+	// the provided plugin type, when empty, the repository for the
+	// first plugin type that exists is used.
+	PluginType string
+	Version    string
+	// Asset is the file name of the release asset.
+	Asset string
+	// Range is the value of the Range header of the client request,
+	// this is passed on to GitHub so partial downloads can be resumed.
+	Range string
+}
+
+// AssetDownload holds the response for a release asset download,
+// the caller is responsible for closing the body.
+type AssetDownload struct {
+	// StatusCode is 200 for a complete download, 206 for a partial
+	// download or 416 when the requested range can not be satisfied.
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
+
+func (s *serviceImpl) OpenAssetDownload(
+	ctx context.Context,
+	params *AssetDownloadParams,
+	token string,
+) (*AssetDownload, error) {
+	repository, err := s.getPluginRepo(
+		ctx,
+		params.Organisation,
+		params.Plugin,
+		params.PluginType,
+		token,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	release, resp, err := s.repoService.GetReleaseByTag(
+		ctx,
+		params.Organisation,
+		repository,
+		fmt.Sprintf("v%s", params.Version),
+		token,
+	)
+	if err != nil {
+		if isNotFound(resp) {
+			return nil, ErrAssetNotFound
+		}
+		return nil, handleGitHubErrorResponse(resp, err)
+	}
+
+	assetURL := ""
+	for _, asset := range release.Assets {
+		if asset.GetName() == params.Asset {
+			assetURL = asset.GetURL()
+		}
+	}
+	if assetURL == "" {
+		return nil, ErrAssetNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if params.Range != "" {
+		req.Header.Set("Range", params.Range)
+	}
+
+	// GitHub redirects asset downloads to storage that supports
+	// range requests, the Range header is kept when following the redirect
+	// while the Authorization header is dropped as the redirect
+	// is to a different host.
+	assetResp, err := s.assetDownloadClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if assetResp.StatusCode != http.StatusOK &&
+		assetResp.StatusCode != http.StatusPartialContent &&
+		assetResp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		assetResp.Body.Close()
+		return nil, handleDownloadError(&utils.DownloadStatusError{
+			URL:        assetURL,
+			StatusCode: assetResp.StatusCode,
+			Status:     assetResp.Status,
+			Header:     assetResp.Header,
+		})
+	}
+
+	header := http.Header{}
+	for _, name := range assetDownloadHeaders {
+		if value := assetResp.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	return &AssetDownload{
+		StatusCode: assetResp.StatusCode,
+		Header:     header,
+		Body:       assetResp.Body,
+	}, nil
+}
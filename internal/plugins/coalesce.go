@@ -0,0 +1,108 @@
+package plugins
+
+import (
+	"context"
+	"sync"
+)
+
+// coalescer shares a single in-flight computation between concurrent
+// callers that request the same key, in the same way as singleflight.
+//
+// Unlike singleflight, the shared computation is given its own context
+// that is only cancelled once every caller waiting on the result has
+// gone away, so one caller cancelling a request does not fail the
+// requests of the other callers sharing the computation.
+type coalescer[Value any] struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall[Value]
+}
+
+type coalescedCall[Value any] struct {
+	done    chan struct{}
+	value   Value
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newCoalescer[Value any]() *coalescer[Value] {
+	return &coalescer[Value]{
+		calls: map[string]*coalescedCall[Value]{},
+	}
+}
+
+// do runs fn for the given key, if there is already a call in flight
+// for the key, the caller will wait for and receive the result of
+// the in-flight call instead.
+// Results are shared between callers and must not be modified.
+func (c *coalescer[Value]) do(
+	ctx context.Context,
+	key string,
+	fn func(ctx context.Context) (Value, error),
+) (Value, error) {
+	c.mu.Lock()
+	call, inFlight := c.calls[key]
+	if !inFlight {
+		call = c.start(ctx, key, fn)
+	}
+	call.waiters += 1
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		c.leave(key, call)
+		var empty Value
+		return empty, ctx.Err()
+	}
+}
+
+// This must be called while holding the lock.
+func (c *coalescer[Value]) start(
+	ctx context.Context,
+	key string,
+	fn func(ctx context.Context) (Value, error),
+) *coalescedCall[Value] {
+	// The shared computation keeps the values of the context of the
+	// caller that started it but is detached from its cancellation.
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &coalescedCall[Value]{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	c.calls[key] = call
+
+	go func() {
+		defer cancel()
+		call.value, call.err = fn(callCtx)
+
+		c.mu.Lock()
+		c.forget(key, call)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	return call
+}
+
+func (c *coalescer[Value]) leave(key string, call *coalescedCall[Value]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	call.waiters -= 1
+	if call.waiters == 0 {
+		// Nobody is waiting for the result anymore, so the computation
+		// is cancelled and removed to make sure that new callers start
+		// a fresh computation instead of joining a cancelled one.
+		call.cancel()
+		c.forget(key, call)
+	}
+}
+
+// This must be called while holding the lock.
+func (c *coalescer[Value]) forget(key string, call *coalescedCall[Value]) {
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
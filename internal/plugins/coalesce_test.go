@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CoalescerTestSuite struct {
+	suite.Suite
+	coalescer *coalescer[string]
+}
+
+func (s *CoalescerTestSuite) SetupTest() {
+	s.coalescer = newCoalescer[string]()
+}
+
+func (s *CoalescerTestSuite) Test_shares_single_computation_between_concurrent_callers() {
+	calls := atomic.Int32{}
+	release := make(chan struct{})
+	results := make([]string, 10)
+
+	wg := sync.WaitGroup{}
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.coalescer.do(
+				context.Background(),
+				"ListVersions::org::plugin::token-hash",
+				func(ctx context.Context) (string, error) {
+					calls.Add(1)
+					<-release
+					return "result", nil
+				},
+			)
+			s.Assert().NoError(err)
+			results[i] = result
+		}()
+	}
+
+	// Give the callers time to join the in-flight computation.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	s.Assert().Equal(int32(1), calls.Load())
+	for _, result := range results {
+		s.Assert().Equal("result", result)
+	}
+}
+
+func (s *CoalescerTestSuite) Test_does_not_share_computations_between_different_keys() {
+	calls := atomic.Int32{}
+	release := make(chan struct{})
+
+	wg := sync.WaitGroup{}
+	for _, key := range []string{"token-hash-1", "token-hash-2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.coalescer.do(
+				context.Background(),
+				key,
+				func(ctx context.Context) (string, error) {
+					calls.Add(1)
+					<-release
+					return key, nil
+				},
+			)
+			s.Assert().NoError(err)
+			s.Assert().Equal(key, result)
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	s.Assert().Equal(int32(2), calls.Load())
+}
+
+func (s *CoalescerTestSuite) Test_cancelling_one_caller_does_not_fail_other_callers() {
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		select {
+		case <-release:
+			return "result", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error)
+	go func() {
+		_, err := s.coalescer.do(cancelledCtx, "key", fn)
+		cancelledErr <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	result := make(chan string)
+	go func() {
+		value, err := s.coalescer.do(context.Background(), "key", fn)
+		s.Assert().NoError(err)
+		result <- value
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	s.Assert().ErrorIs(<-cancelledErr, context.Canceled)
+
+	close(release)
+	s.Assert().Equal("result", <-result)
+}
+
+func (s *CoalescerTestSuite) Test_cancels_computation_when_all_callers_have_gone() {
+	computationErr := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := s.coalescer.do(
+		ctx,
+		"key",
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			computationErr <- ctx.Err()
+			return "", ctx.Err()
+		},
+	)
+	s.Assert().ErrorIs(err, context.Canceled)
+	s.Assert().ErrorIs(<-computationErr, context.Canceled)
+}
+
+func TestCoalescerTestSuite(t *testing.T) {
+	suite.Run(t, new(CoalescerTestSuite))
+}
@@ -0,0 +1,211 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/signedurls"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+)
+
+// DownloadAsset holds information about a release asset
+// for a plugin version that a client can download.
+type DownloadAsset struct {
+	Organisation string
+	Plugin       string
+	// PluginType is the plugin type that the asset was resolved for,
+	// this is empty when the plugin was resolved for any plugin type.
+	PluginType string
+	Version    string
+	// Name is the file name of the release asset.
+	Name string
+	// URL is the GitHub API URL of the release asset.
+	URL string
+}
+
+// DownloadURLResolver provides an interface for resolving the URLs
+// that clients use to download release assets.
+type DownloadURLResolver interface {
+	// ResolveDownloadURL returns the URL that a client should use
+	// to download the provided release asset.
+	ResolveDownloadURL(ctx context.Context, asset *DownloadAsset, token string) (string, error)
+}
+
+type presignedDownloadURLResolver struct {
+	client httputils.Client
+}
+
+// NewPresignedDownloadURLResolver creates a resolver that exchanges the
+// GitHub API URL of a release asset for the short-lived pre-signed URL
+// that GitHub redirects to, so clients can download assets without
+// holding a GitHub token.
+// The provided client must return redirect responses instead of
+// following them.
+func NewPresignedDownloadURLResolver(client httputils.Client) DownloadURLResolver {
+	return &presignedDownloadURLResolver{
+		client: client,
+	}
+}
+
+func (r *presignedDownloadURLResolver) ResolveDownloadURL(
+	ctx context.Context,
+	asset *DownloadAsset,
+	token string,
+) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	// Only the redirect location is needed, the body is drained
+	// so the connection can be reused.
+	io.Copy(io.Discard, resp.Body)
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+		return "", handleDownloadError(&utils.DownloadStatusError{
+			URL:        asset.URL,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+		})
+	}
+
+	return location, nil
+}
+
+type registryDownloadURLResolver struct {
+	registryBaseURL string
+}
+
+// NewRegistryDownloadURLResolver creates a resolver that points clients
+// at the download endpoint of the registry under the provided base URL
+// of the registry (e.g. https://registry.example.com),
+// so clients never need to talk to GitHub directly.
+// Assets resolved for a plugin type point at the download endpoint
+// for the plugin type.
+func NewRegistryDownloadURLResolver(registryBaseURL string) DownloadURLResolver {
+	return &registryDownloadURLResolver{
+		registryBaseURL: strings.TrimSuffix(registryBaseURL, "/"),
+	}
+}
+
+func (r *registryDownloadURLResolver) ResolveDownloadURL(
+	ctx context.Context,
+	asset *DownloadAsset,
+	token string,
+) (string, error) {
+	return registryDownloadPath(r.registryBaseURL, asset), nil
+}
+
+type signedDownloadURLResolver struct {
+	registryBaseURL string
+	signer          *signedurls.Signer
+}
+
+// NewSignedDownloadURLResolver creates a resolver that points clients
+// at the download endpoint of the registry with URLs that are signed
+// with the provided signer, so assets can be downloaded without
+// credentials until the URLs expire.
+func NewSignedDownloadURLResolver(
+	registryBaseURL string,
+	signer *signedurls.Signer,
+) DownloadURLResolver {
+	return &signedDownloadURLResolver{
+		registryBaseURL: strings.TrimSuffix(registryBaseURL, "/"),
+		signer:          signer,
+	}
+}
+
+func (r *signedDownloadURLResolver) ResolveDownloadURL(
+	ctx context.Context,
+	asset *DownloadAsset,
+	token string,
+) (string, error) {
+	query := r.signer.Sign(&signedurls.Asset{
+		Organisation: asset.Organisation,
+		Plugin:       asset.Plugin,
+		Version:      asset.Version,
+		Name:         asset.Name,
+	})
+
+	return registryDownloadPath(r.registryBaseURL, asset) + "?" + query.Encode(), nil
+}
+
+// registryDownloadPath returns the path of the registry download
+// endpoint for a release asset under the provided base URL.
+func registryDownloadPath(baseURL string, asset *DownloadAsset) string {
+	return fmt.Sprintf(
+		"%s%s/%s/%s/%s/download/%s",
+		baseURL,
+		EndpointPath(asset.PluginType),
+		url.PathEscape(asset.Organisation),
+		url.PathEscape(asset.Plugin),
+		url.PathEscape(asset.Version),
+		url.PathEscape(asset.Name),
+	)
+}
+
+func resolveDownloadURLs(
+	ctx context.Context,
+	resolver DownloadURLResolver,
+	params *PackageInfoParams,
+	release *github.RepositoryRelease,
+	packageInfo *types.PluginVersionPackage,
+	token string,
+) error {
+	urls := []*string{
+		&packageInfo.DownloadURL,
+		&packageInfo.SHASumsURL,
+		&packageInfo.SHASumsSignatureURL,
+	}
+
+	for _, assetURL := range urls {
+		if *assetURL == "" {
+			continue
+		}
+
+		resolved, err := resolver.ResolveDownloadURL(
+			ctx,
+			&DownloadAsset{
+				Organisation: params.Organisation,
+				Plugin:       params.Plugin,
+				PluginType:   params.PluginType,
+				Version:      params.Version,
+				Name:         assetName(release, *assetURL),
+				URL:          *assetURL,
+			},
+			token,
+		)
+		if err != nil {
+			return err
+		}
+		*assetURL = resolved
+	}
+
+	return nil
+}
+
+func assetName(release *github.RepositoryRelease, assetURL string) string {
+	for _, asset := range release.Assets {
+		if asset.GetURL() == assetURL {
+			return asset.GetName()
+		}
+	}
+
+	return ""
+}
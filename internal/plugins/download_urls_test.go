@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/stretchr/testify/suite"
+)
+
+type PresignedDownloadURLResolverTestSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	resolver DownloadURLResolver
+}
+
+func (s *PresignedDownloadURLResolverTestSuite) SetupTest() {
+	s.server = httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer test-token" ||
+					r.Header.Get("Accept") != "application/octet-stream" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if r.URL.Path == "/rate-limited" {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				w.Header().Set("Location", "https://objects.example.com/asset?signature=abc")
+				w.WriteHeader(http.StatusFound)
+			},
+		),
+	)
+	s.resolver = NewPresignedDownloadURLResolver(
+		httputils.NewNativeHTTPClient(
+			httputils.WithNativeHTTPClientNoRedirects(),
+		),
+	)
+}
+
+func (s *PresignedDownloadURLResolverTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *PresignedDownloadURLResolverTestSuite) Test_resolves_redirect_location() {
+	url, err := s.resolver.ResolveDownloadURL(
+		context.Background(),
+		&DownloadAsset{URL: s.server.URL + "/repos/newstack-cloud/bluelink-provider-aws/releases/assets/1"},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal("https://objects.example.com/asset?signature=abc", url)
+}
+
+func (s *PresignedDownloadURLResolverTestSuite) Test_returns_error_when_asset_is_not_redirected() {
+	_, err := s.resolver.ResolveDownloadURL(
+		context.Background(),
+		&DownloadAsset{URL: s.server.URL + "/repos/newstack-cloud/bluelink-provider-aws/releases/assets/1"},
+		"other-token",
+	)
+	s.Assert().Error(err)
+
+	_, err = s.resolver.ResolveDownloadURL(
+		context.Background(),
+		&DownloadAsset{URL: s.server.URL + "/rate-limited"},
+		"test-token",
+	)
+	s.Assert().ErrorIs(err, ErrRateLimited)
+}
+
+func TestPresignedDownloadURLResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(PresignedDownloadURLResolverTestSuite))
+}
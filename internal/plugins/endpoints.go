@@ -0,0 +1,18 @@
+package plugins
+
+import "github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+
+// EndpointPath returns the path that the registry protocol endpoints
+// for a plugin type are served under.
+// Endpoints that resolve plugins of any type are served under "/plugins",
+// this is used when no plugin type is provided.
+func EndpointPath(pluginType string) string {
+	switch pluginType {
+	case utils.PluginTypeProvider:
+		return "/providers"
+	case utils.PluginTypeTransformer:
+		return "/transformers"
+	default:
+		return "/plugins"
+	}
+}
@@ -1,6 +1,10 @@
 package plugins
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrUnauthorised is returned when a user is not authorised
@@ -14,4 +18,39 @@ var (
 	// ErrRepoNotFound is returned when a plugin repository
 	// cannot be found.
 	ErrRepoNotFound = errors.New("plugin repository not found")
+
+	// ErrVersionNotFound is returned when a version of a plugin
+	// that matches a version alias such as "latest" cannot be found.
+	ErrVersionNotFound = errors.New("plugin version not found")
+
+	// ErrAssetNotFound is returned when a release asset
+	// for a plugin version cannot be found.
+	ErrAssetNotFound = errors.New("plugin release asset not found")
+
+	// ErrRateLimited is returned when requests to GitHub
+	// on behalf of a user have been rate limited.
+	ErrRateLimited = errors.New("rate limited by GitHub")
 )
+
+// RateLimitError is returned when requests to GitHub on behalf of a user
+// have been rate limited, holding the amount of time to wait
+// before retrying.
+// This wraps ErrRateLimited so it can be checked with errors.Is.
+type RateLimitError struct {
+	// RetryAfter is the amount of time to wait before retrying,
+	// this will be zero when GitHub did not indicate when
+	// the caller can retry.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter == 0 {
+		return ErrRateLimited.Error()
+	}
+
+	return fmt.Sprintf("%s, retry after %s", ErrRateLimited, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
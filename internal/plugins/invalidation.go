@@ -0,0 +1,113 @@
+package plugins
+
+import (
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/repos"
+	"go.uber.org/zap"
+)
+
+// CacheInvalidator provides an interface for removing cached plugin
+// data in response to changes to plugin repositories and their releases.
+type CacheInvalidator interface {
+	// InvalidateRepository removes all cached repository, release
+	// and release artifact data for the given repository.
+	InvalidateRepository(owner string, repo string)
+
+	// RemoveRepository removes all cached data for the given repository
+	// along with release artifacts in persistent artifact stores,
+	// this is used when a repository no longer exists under the
+	// given name.
+	RemoveRepository(owner string, repo string)
+
+	// InvalidateOwnerRepos removes cached repository listings
+	// for the given owner.
+	InvalidateOwnerRepos(owner string)
+}
+
+// RepositoryArtifactStore provides an interface for a persistent
+// store of release artifacts that can remove all the artifacts
+// for a repository.
+// This is implemented by *artifactstore.Store.
+type RepositoryArtifactStore interface {
+	DeleteRepository(owner string, repo string) error
+}
+
+type storeCacheInvalidator struct {
+	store          cache.Store
+	artifactStores []RepositoryArtifactStore
+	logger         *zap.Logger
+}
+
+// CacheInvalidatorOption is a function that configures
+// the cache invalidator.
+type CacheInvalidatorOption func(*storeCacheInvalidator)
+
+// WithInvalidatedArtifactStore configures the cache invalidator to remove
+// the artifacts for a repository from the provided persistent artifact
+// store along with the in-memory cache entries for the repository.
+func WithInvalidatedArtifactStore(
+	artifactStore RepositoryArtifactStore,
+	logger *zap.Logger,
+) CacheInvalidatorOption {
+	return func(i *storeCacheInvalidator) {
+		i.artifactStores = append(i.artifactStores, artifactStore)
+		i.logger = logger
+	}
+}
+
+// NewCacheInvalidator creates a cache invalidator that removes
+// entries from the store shared by the caching repository service
+// and the artifact cache.
+// Entries are removed for all tokens.
+// When the provided store is nil, invalidation of in-memory entries
+// is a no-op.
+func NewCacheInvalidator(
+	store cache.Store,
+	opts ...CacheInvalidatorOption,
+) CacheInvalidator {
+	invalidator := &storeCacheInvalidator{
+		store:  store,
+		logger: zap.NewNop(),
+	}
+
+	for _, opt := range opts {
+		opt(invalidator)
+	}
+
+	return invalidator
+}
+
+func (i *storeCacheInvalidator) InvalidateRepository(owner string, repo string) {
+	if i.store == nil {
+		return
+	}
+
+	repos.InvalidateCachedRepository(i.store, owner, repo)
+	InvalidateCachedArtifacts(i.store, owner, repo)
+}
+
+func (i *storeCacheInvalidator) RemoveRepository(owner string, repo string) {
+	i.InvalidateRepository(owner, repo)
+
+	// Published release artifacts are immutable so they are only
+	// removed from persistent stores when the repository is gone.
+	for _, artifactStore := range i.artifactStores {
+		err := artifactStore.DeleteRepository(owner, repo)
+		if err != nil {
+			i.logger.Warn(
+				"Failed to remove stored artifacts for repository",
+				zap.String("owner", owner),
+				zap.String("repository", repo),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (i *storeCacheInvalidator) InvalidateOwnerRepos(owner string) {
+	if i.store == nil {
+		return
+	}
+
+	repos.InvalidateCachedOwnerRepos(i.store, owner)
+}
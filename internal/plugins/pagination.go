@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"github.com/google/go-github/v70/github"
+	"go.uber.org/zap"
+)
+
+const (
+	// The maximum page size supported by the GitHub API.
+	maxPageSize = 100
+	// The page size used when one is not configured.
+	defaultPageSize = 100
+	// The maximum number of pages to fetch when one is not configured.
+	defaultMaxPages = 50
+)
+
+type paginationConfig struct {
+	pageSize int
+	maxPages int
+}
+
+// paginate fetches all the pages of a GitHub list endpoint,
+// following the next page provided in each response until
+// there are no more pages or the configured maximum number of pages
+// has been fetched.
+func paginate[Item any](
+	config *paginationConfig,
+	logger *zap.Logger,
+	fetchPage func(opts github.ListOptions) ([]Item, *github.Response, error),
+) ([]Item, error) {
+	pageSize := resolvePageSize(config.pageSize)
+	maxPages := resolveMaxPages(config.maxPages)
+
+	allItems := []Item{}
+	page := 1
+	for pagesFetched := 0; ; pagesFetched += 1 {
+		if pagesFetched == maxPages {
+			logger.Warn(
+				"Reached the maximum number of pages to fetch from GitHub, "+
+					"results will be incomplete",
+				zap.Int("maxPages", maxPages),
+				zap.Int("pageSize", pageSize),
+			)
+			return allItems, nil
+		}
+
+		items, resp, err := fetchPage(github.ListOptions{
+			Page:    page,
+			PerPage: pageSize,
+		})
+		if err != nil {
+			return nil, handleGitHubErrorResponse(resp, err)
+		}
+		allItems = append(allItems, items...)
+
+		// A next page that does not move forward would cause
+		// the same pages to be requested indefinitely.
+		if resp == nil || resp.NextPage <= page {
+			return allItems, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+func resolvePageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return defaultPageSize
+	}
+
+	return min(pageSize, maxPageSize)
+}
+
+func resolveMaxPages(maxPages int) int {
+	if maxPages <= 0 {
+		return defaultMaxPages
+	}
+
+	return maxPages
+}
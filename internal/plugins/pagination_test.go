@@ -0,0 +1,205 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type PaginationTestSuite struct {
+	suite.Suite
+	repoService *pagedRepoService
+	config      core.Config
+	logger      *zap.Logger
+}
+
+func (s *PaginationTestSuite) SetupTest() {
+	logger, err := zap.NewDevelopment()
+	s.Require().NoError(err)
+	s.logger = logger
+
+	config, err := core.LoadConfigFromEnv()
+	s.Require().NoError(err)
+	config.RepoLookupMode = core.RepoLookupModeList
+	config.GitHubPageSize = 2
+	s.config = config
+
+	repos := []*github.Repository{
+		{
+			Name: github.Ptr("bluelink-provider-example"),
+			Owner: &github.User{
+				Login: github.Ptr("newstack-cloud"),
+			},
+		},
+	}
+	for i := range 4 {
+		repos = append(repos, &github.Repository{
+			Name: github.Ptr(fmt.Sprintf("other-repo-%d", i)),
+			Owner: &github.User{
+				Login: github.Ptr("newstack-cloud"),
+			},
+		})
+	}
+
+	releases := []*github.RepositoryRelease{}
+	for i := range 5 {
+		releases = append(releases, &github.RepositoryRelease{
+			TagName: github.Ptr(fmt.Sprintf("v1.0.%d", i)),
+			Assets: []*github.ReleaseAsset{
+				{
+					Name: github.Ptr(fmt.Sprintf("bluelink-provider-example_1.0.%d_registry_info.json", i)),
+					URL:  testutils.GithubAssetURL(i),
+				},
+			},
+		})
+	}
+
+	s.repoService = &pagedRepoService{
+		StubRepoService: testutils.NewStubRepoService(repos, nil),
+		repos:           repos,
+		releases:        releases,
+		requestedPages:  map[string][]int{},
+	}
+}
+
+func (s *PaginationTestSuite) Test_follows_next_page_for_repos_and_releases() {
+	service := s.createService()
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+
+	actualVersions := []string{}
+	for _, version := range versions.Versions {
+		actualVersions = append(actualVersions, version.Version)
+	}
+	s.Assert().Equal(
+		[]string{"1.0.0", "1.0.1", "1.0.2", "1.0.3", "1.0.4"},
+		actualVersions,
+	)
+	s.Assert().Equal([]int{1, 2, 3}, s.repoService.requestedPages["ListByOrg"])
+	s.Assert().Equal([]int{1, 2, 3}, s.repoService.requestedPages["ListReleases"])
+	s.Assert().Equal([]int{2}, s.repoService.requestedPageSizes())
+}
+
+func (s *PaginationTestSuite) Test_stops_at_the_maximum_number_of_pages() {
+	s.config.GitHubMaxPages = 2
+	service := s.createService()
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Len(versions.Versions, 4)
+	s.Assert().Equal([]int{1, 2}, s.repoService.requestedPages["ListReleases"])
+}
+
+func (s *PaginationTestSuite) Test_clamps_page_size_to_github_maximum() {
+	s.config.GitHubPageSize = 500
+	service := s.createService()
+
+	_, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal([]int{100}, s.repoService.requestedPageSizes())
+}
+
+func (s *PaginationTestSuite) createService() Service {
+	return NewDefaultService(
+		s.repoService,
+		&testutils.StubHTTPClient{
+			Contents: registryInfoContents(),
+		},
+		&s.config,
+		s.logger,
+	)
+}
+
+// pagedRepoService is a fake repository service that splits
+// repositories and releases into pages based on the requested page size.
+type pagedRepoService struct {
+	*testutils.StubRepoService
+	repos          []*github.Repository
+	releases       []*github.RepositoryRelease
+	requestedPages map[string][]int
+	pageSizes      map[int]struct{}
+}
+
+func (p *pagedRepoService) ListByOrg(
+	ctx context.Context,
+	org string,
+	opts *github.RepositoryListByOrgOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	p.recordRequest("ListByOrg", &opts.ListOptions)
+	items, resp := pageOf(p.repos, &opts.ListOptions)
+	return items, resp, nil
+}
+
+func (p *pagedRepoService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	p.recordRequest("ListReleases", opts)
+	items, resp := pageOf(p.releases, opts)
+	return items, resp, nil
+}
+
+func (p *pagedRepoService) recordRequest(method string, opts *github.ListOptions) {
+	p.requestedPages[method] = append(p.requestedPages[method], opts.Page)
+	if p.pageSizes == nil {
+		p.pageSizes = map[int]struct{}{}
+	}
+	p.pageSizes[opts.PerPage] = struct{}{}
+}
+
+func (p *pagedRepoService) requestedPageSizes() []int {
+	sizes := []int{}
+	for size := range p.pageSizes {
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+func pageOf[Item any](items []Item, opts *github.ListOptions) ([]Item, *github.Response) {
+	start := min((opts.Page-1)*opts.PerPage, len(items))
+	end := min(start+opts.PerPage, len(items))
+
+	nextPage := 0
+	if end < len(items) {
+		nextPage = opts.Page + 1
+	}
+
+	return items[start:end], &github.Response{
+		NextPage: nextPage,
+	}
+}
+
+func TestPaginationTestSuite(t *testing.T) {
+	suite.Run(t, new(PaginationTestSuite))
+}
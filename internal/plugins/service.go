@@ -2,10 +2,14 @@ package plugins
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/repos"
@@ -14,14 +18,32 @@ import (
 	"go.uber.org/zap"
 )
 
+// ListVersionsParams holds the parameters required
+// to list the versions of a plugin.
+type ListVersionsParams struct {
+	Organisation string
+	Plugin       string
+	// PluginType restricts the plugin repository to the repository for
+	// the provided plugin type, when empty, the repository for the
+	// first plugin type that exists is used.
+	PluginType string
+	// Channel is the release channel used to filter the versions,
+	// when empty, the default release channel for the registry is used.
+	Channel string
+}
+
 // PackageInfoParams holds the parameters required
 // to retrieve package information for a plugin version.
 type PackageInfoParams struct {
 	Organisation string
 	Plugin       string
-	Version      string
-	OS           string
-	Arch         string
+	// PluginType restricts the plugin repository to the repository for
+	// the provided plugin type, when empty, the repository for the
+	// first plugin type that exists is used.
+	PluginType string
+	Version    string
+	OS         string
+	Arch       string
 }
 
 // Service provides an interface for a service
@@ -34,8 +56,7 @@ type Service interface {
 	// for each version.
 	ListVersions(
 		ctx context.Context,
-		organisation string,
-		plugin string,
+		params *ListVersionsParams,
 		token string,
 	) (*types.PluginVersions, error)
 
@@ -47,13 +68,92 @@ type Service interface {
 		params *PackageInfoParams,
 		token string,
 	) (*types.PluginVersionPackage, error)
+
+	// OpenAssetDownload starts a download of a release asset
+	// for a plugin version, the body of the download is streamed
+	// from GitHub and must be closed by the caller.
+	OpenAssetDownload(
+		ctx context.Context,
+		params *AssetDownloadParams,
+		token string,
+	) (*AssetDownload, error)
 }
 
 type serviceImpl struct {
 	repoService repos.Service
 	httpClient  httputils.Client
-	config      *core.Config
-	logger      *zap.Logger
+	// The client used to stream release asset downloads,
+	// this defaults to httpClient.
+	assetDownloadClient httputils.Client
+	artifactCache       utils.ArtifactCache
+	// When set, the URLs of release assets in package information
+	// are replaced with the URLs provided by the resolver.
+	downloadURLResolver DownloadURLResolver
+	naming              *utils.NamingConvention
+	config              *core.Config
+	logger              *zap.Logger
+	// When set, the kinds of the owners of plugin repositories
+	// are cached for the repository list lookup mode.
+	ownerKindStore cache.Store
+	ownerKindTTL   time.Duration
+	// Concurrent calls for the same plugin information
+	// with the same token share a single upstream computation.
+	listVersionsCalls *coalescer[*types.PluginVersions]
+	packageInfoCalls  *coalescer[*types.PluginVersionPackage]
+}
+
+// ServiceOption is a function that configures the default
+// plugin service implementation.
+type ServiceOption func(*serviceImpl)
+
+// WithArtifactCache configures the plugin service to use
+// the provided cache for the contents of release artifacts
+// such as registry info and SHA256SUMS files.
+func WithArtifactCache(artifactCache utils.ArtifactCache) ServiceOption {
+	return func(s *serviceImpl) {
+		s.artifactCache = artifactCache
+	}
+}
+
+// WithAssetDownloadHTTPClient configures the HTTP client used to stream
+// release asset downloads to clients of the registry.
+// The bodies of asset downloads are read for as long as it takes to send
+// them to the client, so this client should not have an overall timeout
+// for requests.
+func WithAssetDownloadHTTPClient(httpClient httputils.Client) ServiceOption {
+	return func(s *serviceImpl) {
+		s.assetDownloadClient = httpClient
+	}
+}
+
+// WithDownloadURLResolver configures the plugin service to replace the
+// GitHub API URLs of release assets in package information with the URLs
+// provided by the resolver.
+func WithDownloadURLResolver(resolver DownloadURLResolver) ServiceOption {
+	return func(s *serviceImpl) {
+		s.downloadURLResolver = resolver
+	}
+}
+
+// WithOwnerKindCache configures the plugin service to cache whether the
+// owners of plugin repositories are organisations or users for the
+// repository list lookup mode, in the provided store.
+// Entries are keyed by a hash of the caller's token as whether an owner
+// is the authenticated user depends on the token.
+func WithOwnerKindCache(store cache.Store, ttl time.Duration) ServiceOption {
+	return func(s *serviceImpl) {
+		s.ownerKindStore = store
+		s.ownerKindTTL = ttl
+	}
+}
+
+// WithNamingConvention configures the naming convention used to find
+// plugin repositories and release assets, when not set,
+// the default naming convention is used.
+func WithNamingConvention(naming *utils.NamingConvention) ServiceOption {
+	return func(s *serviceImpl) {
+		s.naming = naming
+	}
 }
 
 // NewDefaultService creates a new instance of the default
@@ -65,25 +165,62 @@ func NewDefaultService(
 	httpClient httputils.Client,
 	config *core.Config,
 	logger *zap.Logger,
+	opts ...ServiceOption,
 ) Service {
-	return &serviceImpl{
-		repoService: repoService,
-		config:      config,
-		logger:      logger,
-		httpClient:  httpClient,
+	service := &serviceImpl{
+		repoService:       repoService,
+		config:            config,
+		logger:            logger,
+		httpClient:        httpClient,
+		naming:            utils.DefaultNamingConvention(),
+		listVersionsCalls: newCoalescer[*types.PluginVersions](),
+		packageInfoCalls:  newCoalescer[*types.PluginVersionPackage](),
+	}
+
+	for _, opt := range opts {
+		opt(service)
 	}
+
+	if service.assetDownloadClient == nil {
+		service.assetDownloadClient = service.httpClient
+	}
+
+	return service
 }
 
 func (s *serviceImpl) ListVersions(
 	ctx context.Context,
-	organisation string,
-	plugin string,
+	params *ListVersionsParams,
+	token string,
+) (*types.PluginVersions, error) {
+	key := cache.Key(
+		"ListVersions",
+		params.Organisation,
+		params.Plugin,
+		params.PluginType,
+		s.releaseChannel(params.Channel),
+		cache.TokenHash(token),
+	)
+	return s.listVersionsCalls.do(
+		ctx,
+		key,
+		func(ctx context.Context) (*types.PluginVersions, error) {
+			return s.listVersions(ctx, params, token)
+		},
+	)
+}
+
+func (s *serviceImpl) listVersions(
+	ctx context.Context,
+	params *ListVersionsParams,
 	token string,
 ) (*types.PluginVersions, error) {
+	organisation := params.Organisation
 	repository, err := s.getPluginRepo(
 		ctx,
 		organisation,
-		plugin,
+		params.Plugin,
+		params.PluginType,
 		token,
 	)
 	if err != nil {
@@ -100,60 +237,245 @@ func (s *serviceImpl) ListVersions(
 		return nil, err
 	}
 
-	return utils.ExtractPluginVersions(
+	// The target commitish of releases from the REST API is the branch
+	// or commit that the tag was created from, so the commits that tags
+	// point to are listed separately.
+	// Releases from the GraphQL API are provided with the commit
+	// of the release tag as the target commitish.
+	var tagCommitSHAs map[string]string
+	if s.config.GitHubAPI != core.GitHubAPIGraphQL {
+		tagCommitSHAs, err = s.listTagCommitSHAs(
+			ctx,
+			organisation,
+			repository,
+			token,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	versions, err := utils.ExtractPluginVersions(
 		ctx,
-		repository,
-		releases,
+		&utils.ExtractPluginVersionsParams{
+			Owner:         organisation,
+			Repository:    repository,
+			Releases:      releases,
+			ArtifactCache: s.artifactCache,
+			Concurrency:   s.config.RegistryInfoConcurrency,
+			Naming:        s.naming,
+			Channel:       s.releaseChannel(params.Channel),
+			IncludeDrafts: s.config.IncludeDraftReleases,
+			TagCommitSHAs: tagCommitSHAs,
+		},
 		s.httpClient,
 		token,
 	)
+	if err != nil {
+		return nil, handleDownloadError(err)
+	}
+
+	return versions, nil
+}
+
+func (s *serviceImpl) releaseChannel(channel string) string {
+	if channel != "" {
+		return channel
+	}
+
+	if s.config.DefaultReleaseChannel != "" {
+		return s.config.DefaultReleaseChannel
+	}
+
+	return utils.ReleaseChannelStable
 }
 
 func (s *serviceImpl) GetPackageInfo(
 	ctx context.Context,
 	params *PackageInfoParams,
 	token string,
+) (*types.PluginVersionPackage, error) {
+	key := cache.Key(
+		"GetPackageInfo",
+		params.Organisation,
+		params.Plugin,
+		params.PluginType,
+		params.Version,
+		params.OS,
+		params.Arch,
+		cache.TokenHash(token),
+	)
+	return s.packageInfoCalls.do(
+		ctx,
+		key,
+		func(ctx context.Context) (*types.PluginVersionPackage, error) {
+			return s.getPackageInfo(ctx, params, token)
+		},
+	)
+}
+
+func (s *serviceImpl) getPackageInfo(
+	ctx context.Context,
+	params *PackageInfoParams,
+	token string,
 ) (*types.PluginVersionPackage, error) {
 	repository, err := s.getPluginRepo(
 		ctx,
 		params.Organisation,
 		params.Plugin,
+		params.PluginType,
 		token,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	release, _, err := s.repoService.GetReleaseByTag(
-		ctx,
-		params.Organisation,
-		repository,
-		fmt.Sprintf("v%s", params.Version),
-		token,
-	)
+	release, version, err := s.getPackageRelease(ctx, params, repository, token)
 	if err != nil {
 		return nil, err
 	}
+	// Version aliases are replaced with the concrete version
+	// of the release for the package.
+	versionParams := *params
+	versionParams.Version = version
 
-	return utils.ExtractPluginVersionPackage(
+	packageInfo, err := utils.ExtractPluginVersionPackage(
 		ctx,
 		&utils.ExtractPluginVersionPackageParams{
+			Owner:                 params.Organisation,
 			Repository:            repository,
 			Release:               release,
-			Version:               params.Version,
+			Version:               version,
 			OS:                    params.OS,
 			Arch:                  params.Arch,
 			SigningKeysSerialised: s.config.PublicSigningKeysString,
+			ArtifactCache:         s.artifactCache,
+			Naming:                s.naming,
 		},
 		s.httpClient,
 		token,
 	)
+	if err != nil {
+		return nil, handleDownloadError(err)
+	}
+
+	if s.downloadURLResolver != nil {
+		err = resolveDownloadURLs(
+			ctx,
+			s.downloadURLResolver,
+			&versionParams,
+			release,
+			packageInfo,
+			token,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return packageInfo, nil
+}
+
+func (s *serviceImpl) getPackageRelease(
+	ctx context.Context,
+	params *PackageInfoParams,
+	repository string,
+	token string,
+) (*github.RepositoryRelease, string, error) {
+	if !utils.IsVersionAlias(params.Version) {
+		release, resp, err := s.repoService.GetReleaseByTag(
+			ctx,
+			params.Organisation,
+			repository,
+			fmt.Sprintf("v%s", params.Version),
+			token,
+		)
+		if err != nil {
+			return nil, "", handleGitHubErrorResponse(resp, err)
+		}
+		return release, params.Version, nil
+	}
+
+	releases, err := s.listReleases(
+		ctx,
+		params.Organisation,
+		repository,
+		token,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	release, version := utils.FindLatestRelease(
+		&utils.FindLatestReleaseParams{
+			Owner:              params.Organisation,
+			Repository:         repository,
+			Releases:           releases,
+			OS:                 params.OS,
+			Arch:               params.Arch,
+			IncludePrereleases: params.Version == utils.VersionAliasLatestPrerelease,
+			IncludeDrafts:      s.config.IncludeDraftReleases,
+			Naming:             s.naming,
+		},
+	)
+	if release == nil {
+		return nil, "", ErrVersionNotFound
+	}
+
+	return release, version, nil
 }
 
 func (s *serviceImpl) getPluginRepo(
 	ctx context.Context,
 	organisation string,
 	plugin string,
+	pluginType string,
+	token string,
+) (string, error) {
+	if s.config.RepoLookupMode == core.RepoLookupModeList {
+		return s.findPluginRepoInList(ctx, organisation, plugin, pluginType, token)
+	}
+
+	return s.getPluginRepoByName(ctx, organisation, plugin, pluginType, token)
+}
+
+// getPluginRepoByName fetches the candidate repositories for
+// the plugin directly, returning the first one that exists.
+func (s *serviceImpl) getPluginRepoByName(
+	ctx context.Context,
+	organisation string,
+	plugin string,
+	pluginType string,
+	token string,
+) (string, error) {
+	candidateRepos := s.naming.CandidateRepoNames(organisation, plugin, pluginType)
+	for _, repoName := range candidateRepos {
+		repo, resp, err := s.repoService.GetRepository(
+			ctx,
+			organisation,
+			repoName,
+			token,
+		)
+		if err != nil {
+			if isNotFound(resp) {
+				continue
+			}
+			return "", handleGitHubErrorResponse(resp, err)
+		}
+
+		return repo.GetName(), nil
+	}
+
+	return "", ErrRepoNotFound
+}
+
+// findPluginRepoInList lists all the repositories for the organisation
+// and searches for the plugin repository in the list.
+func (s *serviceImpl) findPluginRepoInList(
+	ctx context.Context,
+	organisation string,
+	plugin string,
+	pluginType string,
 	token string,
 ) (string, error) {
 	repos, err := s.listRepos(
@@ -169,6 +491,8 @@ func (s *serviceImpl) getPluginRepo(
 		repos,
 		organisation,
 		plugin,
+		pluginType,
+		s.naming,
 	)
 	if repo == nil {
 		return "", ErrRepoNotFound
@@ -182,27 +506,125 @@ func (s *serviceImpl) listRepos(
 	organisation string,
 	token string,
 ) ([]*github.Repository, error) {
-	hasMorePages := true
-	allRepos := []*github.Repository{}
-	for hasMorePages {
-		repos, resp, err := s.repoService.ListByOrg(
-			ctx,
-			organisation,
-			&github.RepositoryListByOrgOptions{
-				ListOptions: github.ListOptions{
-					Page:    1,
-					PerPage: 30,
-				},
-			},
-			token,
-		)
-		if err != nil {
-			return nil, handleGitHubErrorResponse(resp, err)
+	kind, err := s.getOwnerKind(ctx, organisation, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(
+		s.paginationConfig(),
+		s.logger.With(zap.String("organisation", organisation)),
+		func(opts github.ListOptions) ([]*github.Repository, *github.Response, error) {
+			switch kind {
+			case ownerKindOrganisation:
+				return s.repoService.ListByOrg(
+					ctx,
+					organisation,
+					&github.RepositoryListByOrgOptions{
+						ListOptions: opts,
+					},
+					token,
+				)
+			case ownerKindAuthenticatedUser:
+				// Only repositories owned by the user are listed, not those
+				// the user can access as a collaborator or through
+				// organisation membership.
+				return s.repoService.ListByAuthenticatedUser(
+					ctx,
+					&github.RepositoryListByAuthenticatedUserOptions{
+						Affiliation: "owner",
+						ListOptions: opts,
+					},
+					token,
+				)
+			default:
+				return s.repoService.ListByUser(
+					ctx,
+					organisation,
+					&github.RepositoryListByUserOptions{
+						ListOptions: opts,
+					},
+					token,
+				)
+			}
+		},
+	)
+}
+
+type ownerKind string
+
+const ownerKindKeyPrefix = "owner-kind"
+
+const (
+	ownerKindOrganisation      ownerKind = "organisation"
+	ownerKindUser              ownerKind = "user"
+	ownerKindAuthenticatedUser ownerKind = "authenticatedUser"
+)
+
+// getOwnerKind determines whether the owner of plugin repositories
+// is an organisation or a user account.
+// The private repositories of a user can only be listed by the
+// user themselves, so users that are the owner of the token
+// are distinguished from other users.
+func (s *serviceImpl) getOwnerKind(
+	ctx context.Context,
+	owner string,
+	token string,
+) (ownerKind, error) {
+	if s.ownerKindStore == nil {
+		return s.fetchOwnerKind(ctx, owner, token)
+	}
+
+	key := cache.Key(
+		ownerKindKeyPrefix,
+		strings.ToLower(owner),
+		cache.TokenHash(token),
+	)
+	if entry, ok := s.ownerKindStore.Get(key); ok {
+		if kind, isKind := entry.(ownerKind); isKind {
+			return kind, nil
+		}
+	}
+
+	// Owner kinds are cached instead of the user lookups they are
+	// determined from, as the lookup of the authenticated user fails
+	// for tokens that do not belong to a user and failures
+	// are not cached.
+	kind, err := s.fetchOwnerKind(ctx, owner, token)
+	if err != nil {
+		return "", err
+	}
+
+	s.ownerKindStore.Set(key, kind, s.ownerKindTTL)
+	return kind, nil
+}
+
+func (s *serviceImpl) fetchOwnerKind(
+	ctx context.Context,
+	owner string,
+	token string,
+) (ownerKind, error) {
+	account, resp, err := s.repoService.GetUser(ctx, owner, token)
+	if err != nil {
+		if isNotFound(resp) {
+			return "", ErrRepoNotFound
 		}
-		allRepos = append(allRepos, repos...)
-		hasMorePages = resp.NextPage > 0
+		return "", handleGitHubErrorResponse(resp, err)
+	}
+
+	if account.GetType() == "Organization" {
+		return ownerKindOrganisation, nil
+	}
+
+	// Tokens that do not belong to a user, such as GitHub App
+	// installation tokens, can not fetch the authenticated user,
+	// so failures fall back to listing the user's repositories.
+	authenticated, _, err := s.repoService.GetUser(ctx, "", token)
+	if err == nil && strings.EqualFold(authenticated.GetLogin(), owner) {
+		return ownerKindAuthenticatedUser, nil
 	}
-	return allRepos, nil
+
+	return ownerKindUser, nil
 }
 
 func (s *serviceImpl) listReleases(
@@ -211,31 +633,87 @@ func (s *serviceImpl) listReleases(
 	repository string,
 	token string,
 ) ([]*github.RepositoryRelease, error) {
-	hasMorePages := true
-	allReleases := []*github.RepositoryRelease{}
-	for hasMorePages {
-		releases, resp, err := s.repoService.ListReleases(
-			ctx,
-			organisation,
-			repository,
-			&github.ListOptions{
-				Page:    1,
-				PerPage: 30,
-			},
-			token,
-		)
-		if err != nil {
-			return nil, handleGitHubErrorResponse(resp, err)
-		}
+	return paginate(
+		s.paginationConfig(),
+		s.logger.With(
+			zap.String("organisation", organisation),
+			zap.String("repository", repository),
+		),
+		func(opts github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+			return s.repoService.ListReleases(
+				ctx,
+				organisation,
+				repository,
+				&opts,
+				token,
+			)
+		},
+	)
+}
 
-		hasMorePages = resp.NextPage > 0
-		allReleases = append(allReleases, releases...)
+// listTagCommitSHAs lists the tags of a repository, returning the
+// SHA of the commit that each tag points to keyed by tag name.
+func (s *serviceImpl) listTagCommitSHAs(
+	ctx context.Context,
+	organisation string,
+	repository string,
+	token string,
+) (map[string]string, error) {
+	tags, err := paginate(
+		s.paginationConfig(),
+		s.logger.With(
+			zap.String("organisation", organisation),
+			zap.String("repository", repository),
+		),
+		func(opts github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+			return s.repoService.ListTags(
+				ctx,
+				organisation,
+				repository,
+				&opts,
+				token,
+			)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	commitSHAs := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		commitSHAs[tag.GetName()] = tag.GetCommit().GetSHA()
 	}
 
-	return allReleases, nil
+	return commitSHAs, nil
+}
+
+func (s *serviceImpl) paginationConfig() *paginationConfig {
+	return &paginationConfig{
+		pageSize: s.config.GitHubPageSize,
+		maxPages: s.config.GitHubMaxPages,
+	}
+}
+
+func isNotFound(resp *github.Response) bool {
+	return resp != nil &&
+		resp.Response != nil &&
+		resp.StatusCode == http.StatusNotFound
 }
 
 func handleGitHubErrorResponse(resp *github.Response, err error) error {
+	var httpResp *http.Response
+	if resp != nil {
+		httpResp = resp.Response
+	}
+
+	// Rate limits must be checked first as GitHub responds with a 403
+	// for primary rate limits, which would otherwise be treated
+	// as the user not having access to the repository.
+	retryAfter, isRateLimited := repos.RateLimitRetryAfter(httpResp, err)
+	if isRateLimited {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+
 	if resp == nil {
 		return err
 	}
@@ -250,3 +728,26 @@ func handleGitHubErrorResponse(resp *github.Response, err error) error {
 
 	return err
 }
+
+// handleDownloadError maps errors for file downloads from GitHub
+// that were rate limited so they can be reported to the user
+// in the same way as rate limited API requests.
+func handleDownloadError(err error) error {
+	var statusErr *utils.DownloadStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	retryAfter, isRateLimited := repos.RateLimitRetryAfter(
+		&http.Response{
+			StatusCode: statusErr.StatusCode,
+			Header:     statusErr.Header,
+		},
+		nil,
+	)
+	if isRateLimited {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	return err
+}
@@ -2,13 +2,19 @@ package plugins
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 )
@@ -16,33 +22,43 @@ import (
 type DefaultServiceTestSuite struct {
 	suite.Suite
 	service Service
+	logger  *zap.Logger
+	config  core.Config
 }
 
 func (s *DefaultServiceTestSuite) SetupTest() {
 	logger, err := zap.NewDevelopment()
 	s.Require().NoError(err)
+	s.logger = logger
 
 	config, err := core.LoadConfigFromEnv()
 	s.Require().NoError(err)
+	s.config = config
 
-	s.service = NewDefaultService(
+	s.service = s.createService(&s.config)
+}
+
+func (s *DefaultServiceTestSuite) createService(config *core.Config) Service {
+	return NewDefaultService(
 		testutils.NewStubRepoService(
 			stubRepos(),
 			stubRepoReleases(),
-		),
+		).WithTags(stubRepoTags()),
 		&testutils.StubHTTPClient{
 			ContentsProvider: contentsProvider,
 		},
-		&config,
-		logger,
+		config,
+		s.logger,
 	)
 }
 
 func (s *DefaultServiceTestSuite) TestListVersions() {
 	versions, err := s.service.ListVersions(
 		context.Background(),
-		"newstack-cloud",
-		"example",
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
 		"test-token",
 	)
 	s.Require().NoError(err)
@@ -66,6 +82,7 @@ func (s *DefaultServiceTestSuite) TestListVersions() {
 							Arch: "amd64",
 						},
 					},
+					CommitSHA: "8d2e4f6a0b1c3d5e7f9a2b4c6d8e0f1a3b5c7d9e",
 				},
 				{
 					Version:            "1.0.1",
@@ -84,6 +101,90 @@ func (s *DefaultServiceTestSuite) TestListVersions() {
 							Arch: "amd64",
 						},
 					},
+					CommitSHA: "1a3c5e7f9b2d4f6a8c0e1b3d5f7a9c2e4b6d8f0a",
+				},
+			},
+		},
+		versions,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_includes_commit_sha_of_tags_with_rest_api() {
+	// The target commitish of a release from the REST API is the branch
+	// or commit that the tag was created from, which is not necessarily
+	// the commit that the release tag points to.
+	s.config.GitHubAPI = core.GitHubAPIREST
+	service := s.createService(&s.config)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 2)
+	s.Assert().Equal("1.0.0", versions.Versions[0].Version)
+	s.Assert().Equal(
+		"8d2e4f6a0b1c3d5e7f9a2b4c6d8e0f1a3b5c7d9e",
+		versions.Versions[0].CommitSHA,
+	)
+	s.Assert().Equal("1.0.1", versions.Versions[1].Version)
+	s.Assert().Equal(
+		"1a3c5e7f9b2d4f6a8c0e1b3d5f7a9c2e4b6d8f0a",
+		versions.Versions[1].CommitSHA,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_includes_commit_sha_with_graphql_api() {
+	s.config.GitHubAPI = core.GitHubAPIGraphQL
+	service := s.createService(&s.config)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 2)
+	s.Assert().Equal("1.0.0", versions.Versions[0].Version)
+	s.Assert().Empty(versions.Versions[0].CommitSHA)
+	s.Assert().Equal("1.0.1", versions.Versions[1].Version)
+	s.Assert().Equal(
+		"5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394",
+		versions.Versions[1].CommitSHA,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_for_release_channel() {
+	versions, err := s.service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			Channel:      utils.ReleaseChannelPrerelease,
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		&types.PluginVersions{
+			Versions: []*types.PluginVersion{
+				{
+					Version:            "1.1.0-beta.1",
+					SupportedProtocols: []string{"1.4", "2.1"},
+					SupportedPlatforms: []*types.PluginVersionPlatform{
+						{
+							OS:   "linux",
+							Arch: "amd64",
+						},
+					},
+					Prerelease: true,
 				},
 			},
 		},
@@ -91,6 +192,238 @@ func (s *DefaultServiceTestSuite) TestListVersions() {
 	)
 }
 
+func (s *DefaultServiceTestSuite) TestListVersions_with_configured_default_release_channel() {
+	s.config.DefaultReleaseChannel = utils.ReleaseChannelAll
+	s.config.IncludeDraftReleases = true
+	service := s.createService(&s.config)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+
+	actualVersions := []string{}
+	for _, version := range versions.Versions {
+		actualVersions = append(actualVersions, version.Version)
+	}
+	s.Assert().Equal(
+		[]string{"1.0.0", "1.0.1", "1.1.0-beta.1", "1.1.0"},
+		actualVersions,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_with_list_repo_lookup_mode() {
+	s.config.RepoLookupMode = core.RepoLookupModeList
+	service := s.createService(&s.config)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "exampleTransform",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 2)
+	s.Assert().Equal("1.0.0", versions.Versions[0].Version)
+	s.Assert().Equal("1.1.0", versions.Versions[1].Version)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_with_list_repo_lookup_mode_for_user_owner() {
+	s.config.RepoLookupMode = core.RepoLookupModeList
+	service := s.createService(&s.config)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "jane-doe",
+			Plugin:       "personal",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 1)
+	s.Assert().Equal("1.0.0", versions.Versions[0].Version)
+
+	// Private repositories of other users can not be listed.
+	_, err = service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "jane-doe",
+			Plugin:       "secret",
+		},
+		"test-token",
+	)
+	s.Assert().ErrorIs(err, ErrRepoNotFound)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_with_list_repo_lookup_mode_caches_owner_kind() {
+	s.config.RepoLookupMode = core.RepoLookupModeList
+	repoService := &userLookupCountingRepoService{
+		StubRepoService: testutils.NewStubRepoService(
+			stubRepos(),
+			stubRepoReleases(),
+		),
+	}
+	service := NewDefaultService(
+		repoService,
+		&testutils.StubHTTPClient{
+			ContentsProvider: contentsProvider,
+		},
+		&s.config,
+		s.logger,
+		WithOwnerKindCache(cache.NewInMemoryStore(), time.Minute),
+	)
+
+	for range 3 {
+		versions, err := service.ListVersions(
+			context.Background(),
+			&ListVersionsParams{
+				Organisation: "jane-doe",
+				Plugin:       "personal",
+			},
+			"test-token",
+		)
+		s.Require().NoError(err)
+		s.Require().Len(versions.Versions, 1)
+	}
+
+	// The owner and the authenticated user are only looked up for the
+	// first call, the lookup of the authenticated user fails as the token
+	// of the stub service does not belong to a user.
+	s.Assert().Equal(2, repoService.userLookups)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_with_list_repo_lookup_mode_for_authenticated_user_owner() {
+	s.config.RepoLookupMode = core.RepoLookupModeList
+	service := NewDefaultService(
+		testutils.NewStubRepoService(
+			stubRepos(),
+			stubRepoReleases(),
+		).WithAuthenticatedUser("jane-doe"),
+		&testutils.StubHTTPClient{
+			ContentsProvider: contentsProvider,
+		},
+		&s.config,
+		s.logger,
+	)
+
+	versions, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "jane-doe",
+			Plugin:       "secret",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 1)
+	s.Assert().Equal("2.0.0", versions.Versions[0].Version)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_resolves_transformer_repo_by_name() {
+	versions, err := s.service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "exampleTransform",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(versions.Versions, 2)
+	s.Assert().Equal("1.1.0", versions.Versions[1].Version)
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_returns_not_found_error_for_missing_plugin() {
+	for _, mode := range []string{core.RepoLookupModeDirect, core.RepoLookupModeList} {
+		s.config.RepoLookupMode = mode
+		service := s.createService(&s.config)
+
+		_, err := service.ListVersions(
+			context.Background(),
+			&ListVersionsParams{
+				Organisation: "newstack-cloud",
+				Plugin:       "missing",
+			},
+			"test-token",
+		)
+		s.Assert().ErrorIs(err, ErrRepoNotFound)
+	}
+}
+
+func (s *DefaultServiceTestSuite) TestListVersions_returns_rate_limit_error_for_rate_limited_request() {
+	service := NewDefaultService(
+		&rateLimitedRepoService{
+			StubRepoService: testutils.NewStubRepoService(
+				stubRepos(),
+				stubRepoReleases(),
+			),
+		},
+		&testutils.StubHTTPClient{
+			ContentsProvider: contentsProvider,
+		},
+		&s.config,
+		s.logger,
+	)
+
+	_, err := service.ListVersions(
+		context.Background(),
+		&ListVersionsParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+		},
+		"test-token",
+	)
+	s.Require().ErrorIs(err, ErrRateLimited)
+	s.Assert().NotErrorIs(err, ErrForbidden)
+
+	rateLimitErr := &RateLimitError{}
+	s.Require().ErrorAs(err, &rateLimitErr)
+	s.Assert().Equal(30*time.Second, rateLimitErr.RetryAfter)
+}
+
+func (s *DefaultServiceTestSuite) TestGetPackageInfo_for_latest_version() {
+	packageInfo, err := s.service.GetPackageInfo(
+		context.Background(),
+		&PackageInfoParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			Version:      utils.VersionAliasLatest,
+			OS:           "linux",
+			Arch:         "amd64",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	// The prerelease and draft releases are not used for
+	// the latest version.
+	s.Assert().Equal("1.0.1", packageInfo.Version)
+	s.Assert().Equal("bluelink-provider-example_1.0.1_linux_amd64.zip", packageInfo.Filename)
+	s.Assert().Equal("c635e6201021832cc1f4cfe5345", packageInfo.SHASum)
+}
+
+func (s *DefaultServiceTestSuite) TestGetPackageInfo_fails_for_latest_version_without_platform_archive() {
+	_, err := s.service.GetPackageInfo(
+		context.Background(),
+		&PackageInfoParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			Version:      utils.VersionAliasLatest,
+			OS:           "linux",
+			Arch:         "arm64",
+		},
+		"test-token",
+	)
+	s.Assert().ErrorIs(err, ErrVersionNotFound)
+}
+
 func (s *DefaultServiceTestSuite) TestGetPackageInfo() {
 	signingKeysInfo, err := testutils.GetSigningKeysFromEnv()
 	s.Require().NoError(err)
@@ -109,10 +442,12 @@ func (s *DefaultServiceTestSuite) TestGetPackageInfo() {
 	s.Require().NoError(err)
 	s.Assert().Equal(
 		&types.PluginVersionPackage{
+			Version:            "1.0.1",
 			SupportedProtocols: []string{"1.4", "2.1"},
 			OS:                 "linux",
 			Arch:               "amd64",
 			Filename:           "bluelink-provider-example_1.0.1_linux_amd64.zip",
+			ArchiveFormat:      "zip",
 			// See the stubRepoReleases function for the URL in the source github releases.
 			DownloadURL:         *testutils.GithubAssetURL(6),
 			SHASumsURL:          packageInfoRegistrySHA256SumsURL(),
@@ -127,6 +462,155 @@ func (s *DefaultServiceTestSuite) TestGetPackageInfo() {
 	)
 }
 
+func (s *DefaultServiceTestSuite) TestListVersions_only_resolves_repo_for_plugin_type() {
+	for _, lookupMode := range []string{core.RepoLookupModeDirect, core.RepoLookupModeList} {
+		s.Run(lookupMode, func() {
+			s.config.RepoLookupMode = lookupMode
+			service := s.createService(&s.config)
+
+			versions, err := service.ListVersions(
+				context.Background(),
+				&ListVersionsParams{
+					Organisation: "newstack-cloud",
+					Plugin:       "exampleTransform",
+					PluginType:   utils.PluginTypeTransformer,
+				},
+				"test-token",
+			)
+			s.Require().NoError(err)
+			s.Assert().Len(versions.Versions, 2)
+
+			_, err = service.ListVersions(
+				context.Background(),
+				&ListVersionsParams{
+					Organisation: "newstack-cloud",
+					Plugin:       "exampleTransform",
+					PluginType:   utils.PluginTypeProvider,
+				},
+				"test-token",
+			)
+			s.Assert().ErrorIs(err, ErrRepoNotFound)
+		})
+	}
+}
+
+func (s *DefaultServiceTestSuite) TestGetPackageInfo_with_registry_download_urls_for_plugin_type() {
+	service := NewDefaultService(
+		testutils.NewStubRepoService(
+			stubRepos(),
+			stubRepoReleases(),
+		),
+		&testutils.StubHTTPClient{
+			ContentsProvider: contentsProvider,
+		},
+		&s.config,
+		s.logger,
+		WithDownloadURLResolver(
+			NewRegistryDownloadURLResolver("https://registry.example.com"),
+		),
+	)
+
+	packageInfo, err := service.GetPackageInfo(
+		context.Background(),
+		&PackageInfoParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			PluginType:   utils.PluginTypeProvider,
+			Version:      "1.0.1",
+			OS:           "linux",
+			Arch:         "amd64",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		"https://registry.example.com/providers/newstack-cloud/example/1.0.1/download/"+
+			"bluelink-provider-example_1.0.1_linux_amd64.zip",
+		packageInfo.DownloadURL,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestGetPackageInfo_with_registry_download_urls() {
+	service := NewDefaultService(
+		testutils.NewStubRepoService(
+			stubRepos(),
+			stubRepoReleases(),
+		),
+		&testutils.StubHTTPClient{
+			ContentsProvider: contentsProvider,
+		},
+		&s.config,
+		s.logger,
+		WithDownloadURLResolver(
+			NewRegistryDownloadURLResolver("https://registry.example.com"),
+		),
+	)
+
+	packageInfo, err := service.GetPackageInfo(
+		context.Background(),
+		&PackageInfoParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			Version:      "1.0.1",
+			OS:           "linux",
+			Arch:         "amd64",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+
+	baseURL := "https://registry.example.com/plugins/newstack-cloud/example/1.0.1/download/"
+	s.Assert().Equal(
+		baseURL+"bluelink-provider-example_1.0.1_linux_amd64.zip",
+		packageInfo.DownloadURL,
+	)
+	s.Assert().Equal(
+		baseURL+"bluelink-provider-example_1.0.1_SHA256SUMS",
+		packageInfo.SHASumsURL,
+	)
+}
+
+func (s *DefaultServiceTestSuite) TestOpenAssetDownload() {
+	download, err := s.service.OpenAssetDownload(
+		context.Background(),
+		&AssetDownloadParams{
+			Organisation: "newstack-cloud",
+			Plugin:       "example",
+			Version:      "1.0.1",
+			Asset:        "bluelink-provider-example_1.0.1_SHA256SUMS",
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	defer download.Body.Close()
+
+	contents, err := io.ReadAll(download.Body)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusOK, download.StatusCode)
+	s.Assert().Equal(packageSHASumContents(), contents)
+}
+
+func (s *DefaultServiceTestSuite) TestOpenAssetDownload_returns_not_found_error_for_missing_asset() {
+	assets := map[string]string{
+		"1.0.1": "bluelink-provider-example_1.0.1_plan9_amd64.zip",
+		"9.9.9": "bluelink-provider-example_9.9.9_linux_amd64.zip",
+	}
+
+	for version, asset := range assets {
+		_, err := s.service.OpenAssetDownload(
+			context.Background(),
+			&AssetDownloadParams{
+				Organisation: "newstack-cloud",
+				Plugin:       "example",
+				Version:      version,
+				Asset:        asset,
+			},
+			"test-token",
+		)
+		s.Assert().ErrorIs(err, ErrAssetNotFound)
+	}
+}
+
 func stubRepos() []*github.Repository {
 	return []*github.Repository{
 		{
@@ -147,6 +631,26 @@ func stubRepos() []*github.Repository {
 				Login: github.Ptr("newstack-cloud"),
 			},
 		},
+		{
+			Name:        github.Ptr("bluelink-provider-personal"),
+			FullName:    github.Ptr("jane-doe/bluelink-provider-personal"),
+			Description: github.Ptr("A plugin for Bluelink owned by a user"),
+			Private:     github.Ptr(false),
+			Owner: &github.User{
+				Login: github.Ptr("jane-doe"),
+				Type:  github.Ptr("User"),
+			},
+		},
+		{
+			Name:        github.Ptr("bluelink-provider-secret"),
+			FullName:    github.Ptr("jane-doe/bluelink-provider-secret"),
+			Description: github.Ptr("A private plugin for Bluelink owned by a user"),
+			Private:     github.Ptr(true),
+			Owner: &github.User{
+				Login: github.Ptr("jane-doe"),
+				Type:  github.Ptr("User"),
+			},
+		},
 	}
 }
 
@@ -154,7 +658,8 @@ func stubRepoReleases() map[string][]*github.RepositoryRelease {
 	return map[string][]*github.RepositoryRelease{
 		"bluelink-provider-example": {
 			{
-				TagName: github.Ptr("v1.0.0"),
+				TagName:         github.Ptr("v1.0.0"),
+				TargetCommitish: github.Ptr("main"),
 				Assets: []*github.ReleaseAsset{
 					{
 						Name: github.Ptr("bluelink-provider-example_1.0.0_darwin_amd64.zip"),
@@ -175,7 +680,8 @@ func stubRepoReleases() map[string][]*github.RepositoryRelease {
 				},
 			},
 			{
-				TagName: github.Ptr("v1.0.1"),
+				TagName:         github.Ptr("v1.0.1"),
+				TargetCommitish: github.Ptr("5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394"),
 				Assets: []*github.ReleaseAsset{
 					{
 						Name: github.Ptr("bluelink-provider-example_1.0.1_darwin_amd64.zip"),
@@ -206,6 +712,61 @@ func stubRepoReleases() map[string][]*github.RepositoryRelease {
 					},
 				},
 			},
+			{
+				TagName:    github.Ptr("v1.1.0-beta.1"),
+				Prerelease: github.Ptr(true),
+				Assets: []*github.ReleaseAsset{
+					{
+						Name: github.Ptr("bluelink-provider-example_1.1.0-beta.1_linux_amd64.zip"),
+						URL:  testutils.GithubAssetURL(21),
+					},
+					{
+						Name: github.Ptr("bluelink-provider-example_1.1.0-beta.1_registry_info.json"),
+						URL:  testutils.GithubAssetURL(22),
+					},
+				},
+			},
+			{
+				// Draft releases are never listed unless configured.
+				TagName: github.Ptr("v1.1.0"),
+				Draft:   github.Ptr(true),
+				Assets: []*github.ReleaseAsset{
+					{
+						Name: github.Ptr("bluelink-provider-example_1.1.0_registry_info.json"),
+						URL:  testutils.GithubAssetURL(23),
+					},
+				},
+			},
+		},
+		"bluelink-provider-personal": {
+			{
+				TagName: github.Ptr("v1.0.0"),
+				Assets: []*github.ReleaseAsset{
+					{
+						Name: github.Ptr("bluelink-provider-personal_1.0.0_linux_amd64.zip"),
+						URL:  testutils.GithubAssetURL(17),
+					},
+					{
+						Name: github.Ptr("bluelink-provider-personal_1.0.0_registry_info.json"),
+						URL:  testutils.GithubAssetURL(18),
+					},
+				},
+			},
+		},
+		"bluelink-provider-secret": {
+			{
+				TagName: github.Ptr("v2.0.0"),
+				Assets: []*github.ReleaseAsset{
+					{
+						Name: github.Ptr("bluelink-provider-secret_2.0.0_linux_amd64.zip"),
+						URL:  testutils.GithubAssetURL(19),
+					},
+					{
+						Name: github.Ptr("bluelink-provider-secret_2.0.0_registry_info.json"),
+						URL:  testutils.GithubAssetURL(20),
+					},
+				},
+			},
 		},
 		"bluelink-transformer-exampleTransform": {
 			{
@@ -254,6 +815,25 @@ func stubRepoReleases() map[string][]*github.RepositoryRelease {
 	}
 }
 
+func stubRepoTags() map[string][]*github.RepositoryTag {
+	return map[string][]*github.RepositoryTag{
+		"bluelink-provider-example": {
+			{
+				Name: github.Ptr("v1.0.1"),
+				Commit: &github.Commit{
+					SHA: github.Ptr("1a3c5e7f9b2d4f6a8c0e1b3d5f7a9c2e4b6d8f0a"),
+				},
+			},
+			{
+				Name: github.Ptr("v1.0.0"),
+				Commit: &github.Commit{
+					SHA: github.Ptr("8d2e4f6a0b1c3d5e7f9a2b4c6d8e0f1a3b5c7d9e"),
+				},
+			},
+		},
+	}
+}
+
 func registryInfoContents() []byte {
 	return []byte(`
 	{
@@ -290,6 +870,41 @@ func packageSHASumContents() []byte {
 	`)
 }
 
+// rateLimitedRepoService responds to repository lookups in the same
+// way as GitHub does when the primary rate limit has been exceeded.
+type rateLimitedRepoService struct {
+	*testutils.StubRepoService
+}
+
+func (r *rateLimitedRepoService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+	}
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("Retry-After", "30")
+
+	return nil, &github.Response{Response: resp}, errors.New("API rate limit exceeded")
+}
+
+type userLookupCountingRepoService struct {
+	*testutils.StubRepoService
+	userLookups int
+}
+
+func (r *userLookupCountingRepoService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	r.userLookups += 1
+	return r.StubRepoService.GetUser(ctx, user, token)
+}
+
 func TestDefaultServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(DefaultServiceTestSuite))
 }
@@ -1,30 +1,470 @@
 package registry
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/apikeys"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/artifactstore"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/oidc"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/repos"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/signedurls"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 	"go.uber.org/zap"
 )
 
+// The amount of time to wait before the first retry of a rate limited
+// request when GitHub does not indicate when the caller can retry.
+const rateLimitRetryBaseBackoff = time.Second
+
 // GetDependencies retrieves the dependencies for the registry application
 // endpoint handlers.
 func GetDependencies(
 	config *core.Config,
 	logger *zap.Logger,
-) *registryDependencies {
+) (*registryDependencies, error) {
+	err := utils.ValidateReleaseChannel(config.DefaultReleaseChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms, err := utils.NewPlatforms(&utils.PlatformsConfig{
+		OperatingSystems:       config.OperatingSystems,
+		Architectures:          config.Architectures,
+		OperatingSystemAliases: config.OperatingSystemAliases,
+		ArchitectureAliases:    config.ArchitectureAliases,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	naming, err := utils.NewNamingConvention(
+		&utils.NamingTemplates{
+			Repo:      config.RepoNameTemplate,
+			Archive:   config.ArchiveNameTemplate,
+			Checksums: config.ChecksumsNameTemplate,
+		},
+		utils.WithArchiveFormats(config.ArchiveFormats),
+		utils.WithPlatforms(platforms),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The time to wait for a response is bounded by the transport as
+	// release asset downloads are streamed to clients without an overall
+	// timeout, the default transport bounds connecting to a host and
+	// TLS handshakes.
+	baseTransport := http.DefaultTransport.(*http.Transport).Clone()
+	baseTransport.ResponseHeaderTimeout = seconds(config.HTTPClientTimeout)
+
+	var transport http.RoundTripper = baseTransport
+	if config.ConditionalRequests {
+		transport = httputils.NewETagTransport(
+			transport,
+			cache.NewInMemoryStore(
+				cache.WithInMemoryStoreMaxEntries(config.ETagCacheMaxEntries),
+			),
+			seconds(config.ETagCacheTTL),
+		)
+	}
+
+	// The transport is shared by the GitHub API client and
+	// for downloading release artifacts, so both make use of the
+	// same retries and circuit breakers.
+	decoratedTransport := httputils.NewClientTransport(
+		decorateHTTPClient(
+			httputils.NewTransportClient(transport),
+			config,
+			logger,
+		),
+	)
 	httpClient := httputils.NewNativeHTTPClient(
 		httputils.WithNativeHTTPClientTimeout(config.HTTPClientTimeout),
+		httputils.WithNativeHTTPClientTransport(decoratedTransport),
+	)
+	repoService := createRepoService(config, httpClient)
+	if config.RateLimitMaxRetries > 0 {
+		repoService = repos.NewRateLimitRetryingService(
+			repoService,
+			&repos.RateLimitRetryConfig{
+				MaxRetries:  config.RateLimitMaxRetries,
+				MaxWait:     seconds(config.RateLimitMaxRetryWait),
+				BaseBackoff: rateLimitRetryBaseBackoff,
+			},
+		)
+	}
+	artifactCaches := []utils.ArtifactCache{}
+
+	// The store is left as nil when caching is disabled,
+	// making cache invalidation a no-op.
+	var store cache.Store
+	if config.CacheEnabled {
+		store = cache.NewInMemoryStore(
+			cache.WithInMemoryStoreMaxEntries(config.CacheMaxEntries),
+		)
+		repoService = repos.NewCachedService(
+			repoService,
+			store,
+			&repos.CacheTTLs{
+				Repos:    seconds(config.CacheRepoTTL),
+				Releases: seconds(config.CacheReleasesTTL),
+			},
+		)
+		artifactCaches = append(
+			artifactCaches,
+			plugins.NewArtifactCache(
+				store,
+				&plugins.ArtifactCacheTTLs{
+					RegistryInfo: seconds(config.CacheRegistryInfoTTL),
+					SHASums:      seconds(config.CacheSHASumsTTL),
+				},
+			),
+		)
+	}
+
+	cacheInvalidatorOpts := []plugins.CacheInvalidatorOption{}
+	if config.ArtifactStoreDir != "" {
+		// The artifact store is kept open for the lifetime of the process,
+		// bbolt commits each write transaction to disk so there is no
+		// risk of losing data when the process exits without closing it.
+		artifactStore, err := artifactstore.Open(config.ArtifactStoreDir)
+		if err != nil {
+			return nil, err
+		}
+		artifactCaches = append(artifactCaches, artifactStore)
+		cacheInvalidatorOpts = append(
+			cacheInvalidatorOpts,
+			plugins.WithInvalidatedArtifactStore(artifactStore, logger),
+		)
+	}
+
+	pluginServiceOpts := []plugins.ServiceOption{
+		plugins.WithNamingConvention(naming),
+		plugins.WithAssetDownloadHTTPClient(
+			httputils.NewNativeHTTPClient(
+				httputils.WithNativeHTTPClientNoTimeout(),
+				httputils.WithNativeHTTPClientTransport(decoratedTransport),
+			),
+		),
+	}
+	if store != nil {
+		pluginServiceOpts = append(
+			pluginServiceOpts,
+			plugins.WithOwnerKindCache(store, seconds(config.CacheRepoTTL)),
+		)
+	}
+	tokenResolver := auth.NewTokenResolver(
+		auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+		auth.NewPassthroughTokenSource(),
 	)
-	repoService := repos.NewGitHubService()
+	// The token source for the registry's own credentials for GitHub,
+	// this is only set in the GitHub App auth mode.
+	var appTokenSource auth.TokenSource
+	if config.AuthMode == core.AuthModeGitHubApp {
+		var err error
+		appTokenSource, err = createGitHubAppTokenSource(config, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		clientAuthenticator, err := createClientAuthenticator(config, httpClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		tokenResolver = auth.NewTokenResolver(
+			clientAuthenticator,
+			appTokenSource,
+		)
+	}
+
+	downloadTokenResolver := tokenResolver
+	if len(config.DownloadURLSigningKeys) > 0 {
+		signer, err := createDownloadURLSigner(config, appTokenSource)
+		if err != nil {
+			return nil, err
+		}
+		downloadTokenResolver = signedurls.NewTokenResolver(
+			signer,
+			appTokenSource,
+			tokenResolver,
+		)
+		pluginServiceOpts = append(
+			pluginServiceOpts,
+			plugins.WithDownloadURLResolver(
+				plugins.NewSignedDownloadURLResolver(config.RegistryBaseURL, signer),
+			),
+		)
+	} else if config.DownloadProxyEnabled {
+		pluginServiceOpts = append(
+			pluginServiceOpts,
+			plugins.WithDownloadURLResolver(
+				plugins.NewRegistryDownloadURLResolver(config.RegistryBaseURL),
+			),
+		)
+	} else if config.AuthMode == core.AuthModeGitHubApp {
+		// Clients do not hold a GitHub token to download release assets
+		// with, so they are given the pre-signed URLs that GitHub
+		// redirects to instead.
+		pluginServiceOpts = append(
+			pluginServiceOpts,
+			plugins.WithDownloadURLResolver(
+				plugins.NewPresignedDownloadURLResolver(
+					httputils.NewNativeHTTPClient(
+						httputils.WithNativeHTTPClientTimeout(config.HTTPClientTimeout),
+						httputils.WithNativeHTTPClientTransport(decoratedTransport),
+						httputils.WithNativeHTTPClientNoRedirects(),
+					),
+				),
+			),
+		)
+	}
+
+	if len(artifactCaches) > 0 {
+		pluginServiceOpts = append(
+			pluginServiceOpts,
+			plugins.WithArtifactCache(
+				utils.NewTieredArtifactCache(artifactCaches...),
+			),
+		)
+	}
+
 	pluginService := plugins.NewDefaultService(
 		repoService,
 		httpClient,
 		config,
 		logger,
+		pluginServiceOpts...,
 	)
+
 	return &registryDependencies{
-		pluginService: pluginService,
+		pluginService:         pluginService,
+		cacheInvalidator:      plugins.NewCacheInvalidator(store, cacheInvalidatorOpts...),
+		tokenResolver:         tokenResolver,
+		downloadTokenResolver: downloadTokenResolver,
+		naming:                naming,
+		prewarmTokenSource:    appTokenSource,
+	}, nil
+}
+
+// createClientAuthenticator creates the authenticator for clients of the
+// registry when the registry holds its own credentials for GitHub.
+func createClientAuthenticator(
+	config *core.Config,
+	httpClient *http.Client,
+	logger *zap.Logger,
+) (auth.Authenticator, error) {
+	authenticators := []auth.Authenticator{}
+	if config.OIDCIssuer != "" {
+		oidcAuthenticator, err := createOIDCAuthenticator(config, httpClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, oidcAuthenticator)
+	}
+
+	if config.APIKeysFile != "" {
+		authenticators = append(
+			authenticators,
+			apikeys.NewAuthenticator(
+				config.AuthTokenHeader,
+				apikeys.NewFileStore(config.APIKeysFile),
+				logger,
+			),
+		)
+	}
+
+	if len(config.ClientTokens) > 0 {
+		authenticators = append(
+			authenticators,
+			auth.NewStaticTokenAuthenticator(config.AuthTokenHeader, config.ClientTokens),
+		)
+	}
+
+	return auth.NewChainAuthenticator(authenticators...), nil
+}
+
+func createOIDCAuthenticator(
+	config *core.Config,
+	httpClient *http.Client,
+	logger *zap.Logger,
+) (auth.Authenticator, error) {
+	if config.OIDCAudience == "" || config.OIDCPolicyFile == "" {
+		return nil, errors.New(
+			"an audience and policy file must be configured " +
+				"when an OIDC issuer is configured",
+		)
 	}
+
+	policy, err := oidc.LoadPolicyFile(config.OIDCPolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet oidc.KeySet
+	switch {
+	case config.OIDCJWKSFile != "":
+		keySet, err = oidc.LoadKeySetFile(config.OIDCJWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OIDC JWKS file: %w", err)
+		}
+	case config.OIDCJWKSURL != "":
+		keySet = oidc.NewRemoteKeySet(
+			config.OIDCJWKSURL,
+			oidc.WithRemoteKeySetHTTPClient(httpClient),
+		)
+	default:
+		keySet = oidc.NewIssuerKeySet(
+			config.OIDCIssuer,
+			oidc.WithRemoteKeySetHTTPClient(httpClient),
+		)
+	}
+
+	return oidc.NewAuthenticator(
+		config.AuthTokenHeader,
+		oidc.NewVerifier(
+			&oidc.VerifierConfig{
+				Issuer:   config.OIDCIssuer,
+				Audience: config.OIDCAudience,
+			},
+			keySet,
+		),
+		policy,
+		logger,
+	), nil
+}
+
+// createDownloadURLSigner creates the signer for download URLs,
+// requests with signed URLs do not have client credentials, so they
+// must be served with the registry's own credentials for GitHub.
+func createDownloadURLSigner(
+	config *core.Config,
+	appTokenSource auth.TokenSource,
+) (*signedurls.Signer, error) {
+	if !config.DownloadProxyEnabled || appTokenSource == nil {
+		return nil, errors.New(
+			"the download proxy must be enabled and the github_app auth mode " +
+				"must be used when download URL signing keys are configured",
+		)
+	}
+
+	keys, err := signedurls.ParseKeys(config.DownloadURLSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return signedurls.NewSigner(keys, seconds(config.DownloadURLTTL))
+}
+
+func createGitHubAppTokenSource(
+	config *core.Config,
+	httpClient *http.Client,
+) (auth.TokenSource, error) {
+	if config.GitHubAppID == 0 ||
+		config.GitHubAppPrivateKeyFile == "" ||
+		len(config.GitHubAppInstallations) == 0 {
+		return nil, errors.New(
+			"the GitHub App ID, private key file and installation IDs " +
+				"must be configured for the github_app auth mode",
+		)
+	}
+
+	if len(config.ClientTokens) == 0 &&
+		config.APIKeysFile == "" &&
+		config.OIDCIssuer == "" {
+		return nil, errors.New(
+			"at least one client token, an API keys file or an OIDC issuer " +
+				"must be configured for the github_app auth mode",
+		)
+	}
+
+	privateKey, err := auth.LoadGitHubAppPrivateKey(config.GitHubAppPrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GitHub App private key: %w", err)
+	}
+
+	return auth.NewGitHubAppTokenSource(
+		&auth.GitHubAppConfig{
+			AppID:           config.GitHubAppID,
+			PrivateKey:      privateKey,
+			InstallationIDs: config.GitHubAppInstallations,
+		},
+		auth.WithGitHubAppHTTPClient(httpClient),
+	), nil
+}
+
+func createRepoService(
+	config *core.Config,
+	httpClient *http.Client,
+) repos.Service {
+	if config.GitHubAPI == core.GitHubAPIGraphQL {
+		return repos.NewGraphQLService(
+			repos.WithGraphQLHTTPClient(httpClient),
+			repos.WithGraphQLMaxPages(config.GitHubMaxPages),
+			repos.WithGraphQLRESTService(
+				repos.NewGitHubService(
+					repos.WithGitHubHTTPClient(httpClient),
+				),
+			),
+		)
+	}
+
+	return repos.NewGitHubService(
+		repos.WithGitHubHTTPClient(httpClient),
+	)
+}
+
+func decorateHTTPClient(
+	client httputils.Client,
+	config *core.Config,
+	logger *zap.Logger,
+) httputils.Client {
+	if config.CircuitBreakerThreshold > 0 {
+		client = httputils.NewCircuitBreakerClient(
+			client,
+			httputils.WithCircuitBreakerFailureThreshold(config.CircuitBreakerThreshold),
+			httputils.WithCircuitBreakerOpenDuration(seconds(config.CircuitBreakerOpenTime)),
+		)
+	}
+
+	if config.HTTPRetryMaxAttempts > 1 {
+		client = httputils.NewRetryingClient(
+			client,
+			httputils.WithRetryMaxAttempts(config.HTTPRetryMaxAttempts),
+		)
+	}
+
+	return httputils.NewInstrumentedClient(client, logRequestOutcome(logger))
+}
+
+func logRequestOutcome(logger *zap.Logger) httputils.RequestObserver {
+	return func(outcome *httputils.RequestOutcome) {
+		fields := []zap.Field{
+			zap.String("method", outcome.Method),
+			zap.String("host", outcome.Host),
+			zap.String("path", outcome.Path),
+			zap.Int("statusCode", outcome.StatusCode),
+			zap.Duration("duration", outcome.Duration),
+		}
+
+		if outcome.Err != nil || outcome.StatusCode >= 500 {
+			logger.Warn(
+				"HTTP request to GitHub failed",
+				append(fields, zap.Error(outcome.Err))...,
+			)
+			return
+		}
+
+		logger.Debug("HTTP request to GitHub completed", fields...)
+	}
+}
+
+func seconds(value int) time.Duration {
+	return time.Duration(value) * time.Second
 }
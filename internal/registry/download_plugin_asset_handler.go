@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"go.uber.org/zap"
+)
+
+// DownloadPluginAssetHandler streams a release asset for a plugin version
+// from GitHub using the registry's credentials for GitHub,
+// so clients never need to talk to GitHub directly.
+// When a plugin type is provided, only the repository for the
+// plugin type is used to resolve the plugin.
+func DownloadPluginAssetHandler(
+	config *core.Config,
+	logger *zap.Logger,
+	pluginService plugins.Service,
+	tokenResolver auth.TokenResolver,
+	pluginType string,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			params := mux.Vars(req)
+			organisation := params["organisation"]
+			plugin := params["plugin"]
+
+			token, err := tokenResolver.ResolveToken(
+				withBearerCredentials(req, config.AuthTokenHeader),
+				&auth.Resource{
+					Owner:   organisation,
+					Plugin:  plugin,
+					Version: params["version"],
+					Asset:   params["asset"],
+				},
+			)
+			if err != nil {
+				handleAuthError(w, err, logger)
+				return
+			}
+
+			download, err := pluginService.OpenAssetDownload(
+				req.Context(),
+				&plugins.AssetDownloadParams{
+					Organisation: organisation,
+					Plugin:       plugin,
+					PluginType:   pluginType,
+					Version:      params["version"],
+					Asset:        params["asset"],
+					Range:        req.Header.Get("Range"),
+				},
+				token,
+			)
+			if err != nil {
+				handlePluginError(
+					w,
+					err,
+					logger,
+				)
+				return
+			}
+			defer download.Body.Close()
+
+			for name, values := range download.Header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(download.StatusCode)
+
+			// The response has already started at this point,
+			// so a failure part way through can only be logged.
+			if _, err := io.Copy(w, download.Body); err != nil {
+				logger.Warn(
+					"Error streaming plugin release asset",
+					zap.String("organisation", organisation),
+					zap.String("plugin", plugin),
+					zap.String("asset", params["asset"]),
+					zap.Error(err),
+				)
+			}
+		},
+	)
+}
+
+// withBearerCredentials returns a request with the credentials from
+// the `Authorization: Bearer <token>` header copied to the auth token
+// header, when the auth token header is not already set.
+// Clients send credentials for downloads as bearer tokens as per the
+// `downloadAuth` field of the service discovery document.
+func withBearerCredentials(req *http.Request, tokenHeader string) *http.Request {
+	if req.Header.Get(tokenHeader) != "" {
+		return req
+	}
+
+	bearerToken, isBearer := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !isBearer || strings.TrimSpace(bearerToken) == "" {
+		return req
+	}
+
+	withCredentials := req.Clone(req.Context())
+	withCredentials.Header.Set(tokenHeader, strings.TrimSpace(bearerToken))
+	return withCredentials
+}
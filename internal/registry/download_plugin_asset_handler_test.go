@@ -0,0 +1,274 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/signedurls"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type DownloadPluginAssetHandlerTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) SetupTest() {
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_DOWNLOAD_PROXY_ENABLED", "true")
+	router := mux.NewRouter()
+
+	getDeps := func(
+		config *core.Config,
+		logger *zap.Logger,
+	) (*registryDependencies, error) {
+		return &registryDependencies{
+			pluginService: &stubPluginService{},
+			tokenResolver: auth.NewTokenResolver(
+				auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+				auth.NewPassthroughTokenSource(),
+			),
+		}, nil
+	}
+
+	_, _, err := Setup(router, getDeps)
+	s.Require().NoError(err)
+
+	s.server = httptest.NewServer(router)
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_streams_plugin_asset() {
+	req := s.newRequest("bluelink-provider-aws_3.0.1_linux_amd64.zip")
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Assert().Equal(testAssetContents, string(respBytes))
+	s.Assert().Equal(int64(len(testAssetContents)), resp.ContentLength)
+	s.Assert().Equal("application/octet-stream", resp.Header.Get("Content-Type"))
+	s.Assert().Equal("bytes", resp.Header.Get("Accept-Ranges"))
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_streams_range_of_plugin_asset_with_bearer_token() {
+	req := s.newRequest("bluelink-provider-aws_3.0.1_linux_amd64.zip")
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Range", "bytes=7-")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusPartialContent, resp.StatusCode)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Assert().Equal(testAssetContents[7:], string(respBytes))
+	s.Assert().Equal(int64(len(testAssetContents)-7), resp.ContentLength)
+	s.Assert().Equal("bytes 7-22/23", resp.Header.Get("Content-Range"))
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_returns_401_response_for_missing_token() {
+	resp, err := http.DefaultClient.Do(
+		s.newRequest("bluelink-provider-aws_3.0.1_linux_amd64.zip"),
+	)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_returns_404_response_for_missing_asset() {
+	req := s.newRequest("bluelink-provider-aws_3.0.1_plan9_amd64.zip")
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Assert().Equal(`{"message":"Plugin release asset not found"}`, string(respBytes))
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_streams_plugin_asset_for_signed_url_without_credentials() {
+	signer, err := signedurls.NewSigner(
+		[]*signedurls.Key{{ID: "key-1", Secret: []byte(strings.Repeat("s", 32))}},
+		time.Minute,
+	)
+	s.Require().NoError(err)
+
+	router := mux.NewRouter()
+	_, _, err = Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			tokenResolver := auth.NewTokenResolver(
+				auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+				auth.NewPassthroughTokenSource(),
+			)
+			return &registryDependencies{
+				pluginService: &stubPluginService{},
+				tokenResolver: tokenResolver,
+				downloadTokenResolver: signedurls.NewTokenResolver(
+					signer,
+					&stubInstallationTokenSource{owner: "newstack-cloud"},
+					tokenResolver,
+				),
+			}, nil
+		},
+	)
+	s.Require().NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	asset := "bluelink-provider-aws_3.0.1_linux_amd64.zip"
+	query := signer.Sign(&signedurls.Asset{
+		Organisation: "newstack-cloud",
+		Plugin:       "aws",
+		Version:      "3.0.1",
+		Name:         asset,
+	})
+
+	resp, err := http.Get(fmt.Sprintf(
+		"%s/plugins/newstack-cloud/aws/3.0.1/download/%s?%s",
+		server.URL,
+		asset,
+		query.Encode(),
+	))
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+
+	// The signature only covers the asset it was created for.
+	resp, err = http.Get(fmt.Sprintf(
+		"%s/plugins/newstack-cloud/aws/3.0.2/download/%s?%s",
+		server.URL,
+		asset,
+		query.Encode(),
+	))
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) Test_streams_large_plugin_asset_through_timeout_handler() {
+	// The first chunk is larger than the buffers used to copy and
+	// write the response body, so it must reach the client before the
+	// rest of the asset is written if the response is streamed.
+	firstChunk := bytes.Repeat([]byte("a"), 256*1024)
+	secondChunk := bytes.Repeat([]byte("b"), 256*1024)
+	body, bodyWriter := io.Pipe()
+	firstChunkReceived := make(chan struct{})
+	go func() {
+		bodyWriter.Write(firstChunk)
+		select {
+		case <-firstChunkReceived:
+			bodyWriter.Write(secondChunk)
+			bodyWriter.Close()
+		case <-time.After(5 * time.Second):
+			bodyWriter.CloseWithError(errors.New("first chunk was not streamed to the client"))
+		}
+	}()
+
+	router := mux.NewRouter()
+	_, _, err := Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			return &registryDependencies{
+				pluginService: &pipedAssetPluginService{
+					body:          body,
+					contentLength: len(firstChunk) + len(secondChunk),
+				},
+				tokenResolver: auth.NewTokenResolver(
+					auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+					auth.NewPassthroughTokenSource(),
+				),
+			}, nil
+		},
+	)
+	s.Require().NoError(err)
+	// A buffered response would not be sent until the timeout.
+	server := httptest.NewServer(TimeoutHandler(router, 10*time.Second, "Timeout!\n"))
+	defer server.Close()
+
+	req, err := http.NewRequest(
+		http.MethodGet,
+		server.URL+"/plugins/newstack-cloud/aws/3.0.1/download/bluelink-provider-aws_3.0.1_linux_amd64.zip",
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(int64(len(firstChunk)+len(secondChunk)), resp.ContentLength)
+
+	received := make([]byte, len(firstChunk))
+	_, err = io.ReadFull(resp.Body, received)
+	s.Require().NoError(err)
+	s.Assert().Equal(firstChunk, received)
+	close(firstChunkReceived)
+
+	rest, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Assert().Equal(secondChunk, rest)
+}
+
+func (s *DownloadPluginAssetHandlerTestSuite) newRequest(asset string) *http.Request {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/aws/3.0.1/download/%s", s.server.URL, asset),
+		nil,
+	)
+	s.Require().NoError(err)
+	return req
+}
+
+// pipedAssetPluginService serves a release asset
+// from a body that is written to while it is being read.
+type pipedAssetPluginService struct {
+	stubPluginService
+	body          io.ReadCloser
+	contentLength int
+}
+
+func (s *pipedAssetPluginService) OpenAssetDownload(
+	ctx context.Context,
+	params *plugins.AssetDownloadParams,
+	token string,
+) (*plugins.AssetDownload, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Length", strconv.Itoa(s.contentLength))
+	return &plugins.AssetDownload{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       s.body,
+	}, nil
+}
+
+func TestDownloadPluginAssetHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(DownloadPluginAssetHandlerTestSuite))
+}
@@ -2,11 +2,12 @@ package registry
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 )
 
 // Manifest is the JSON manifest
@@ -48,21 +49,21 @@ const (
 func GetManifestHandler(config *core.Config) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, req *http.Request) {
-			pluginsBaseURL := fmt.Sprintf("%s/plugins", config.RegistryBaseURL)
+			// Each plugin type is advertised with the endpoints for the
+			// plugin type so that clients never resolve a plugin of
+			// the wrong type when a provider and a transformer share a name.
 			manifest := &Manifest{
 				ProviderV1: &PluginTypeManifestInfo{
-					Endpoint:                  pluginsBaseURL,
+					Endpoint:                  pluginTypeEndpoint(config, utils.PluginTypeProvider),
 					DownloadAcceptContentType: DownloadContentType,
 				},
 				TransformerV1: &PluginTypeManifestInfo{
-					Endpoint:                  pluginsBaseURL,
+					Endpoint:                  pluginTypeEndpoint(config, utils.PluginTypeTransformer),
 					DownloadAcceptContentType: DownloadContentType,
 				},
 				AuthV1: &AuthManifestInfo{
 					APIKeyHeader: config.AuthTokenHeader,
-					// GitHub expects the `Authorization: Bearer <token>` header
-					// to be set when downloading artifacts from private repositories.
-					DownloadAuth: "bearer",
+					DownloadAuth: downloadAuth(config),
 				},
 			}
 
@@ -82,3 +83,31 @@ func GetManifestHandler(config *core.Config) http.Handler {
 		},
 	)
 }
+
+func pluginTypeEndpoint(config *core.Config, pluginType string) string {
+	return config.RegistryBaseURL + plugins.EndpointPath(pluginType)
+}
+
+func downloadAuth(config *core.Config) string {
+	// Signed download URLs carry their own authorisation,
+	// so clients do not need to send credentials.
+	if len(config.DownloadURLSigningKeys) > 0 {
+		return ""
+	}
+
+	// When downloads are proxied, clients send the same credentials
+	// to the registry's download endpoint as a bearer token.
+	if config.DownloadProxyEnabled {
+		return "bearer"
+	}
+
+	// In the GitHub App auth mode, clients download artifacts from
+	// pre-signed URLs that must not be sent an `Authorization` header.
+	if config.AuthMode == core.AuthModeGitHubApp {
+		return ""
+	}
+
+	// GitHub expects the `Authorization: Bearer <token>` header
+	// to be set when downloading artifacts from private repositories.
+	return "bearer"
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -24,11 +25,11 @@ func (s *GetManifestHandlerTestSuite) SetupTest() {
 	getDeps := func(
 		config *core.Config,
 		logger *zap.Logger,
-	) *registryDependencies {
+	) (*registryDependencies, error) {
 		// Empty dependencies are fine for the get manifest handler
 		// as it doesn't make use of the dependencies to generate
 		// the manifest.
-		return &registryDependencies{}
+		return &registryDependencies{}, nil
 	}
 
 	_, _, err := Setup(router, getDeps)
@@ -58,11 +59,11 @@ func (s *GetManifestHandlerTestSuite) TestGetManifest() {
 	s.Require().Equal(
 		&Manifest{
 			ProviderV1: &PluginTypeManifestInfo{
-				Endpoint:                  "http://gh-registry.bluelink.local/plugins",
+				Endpoint:                  "http://gh-registry.bluelink.local/providers",
 				DownloadAcceptContentType: DownloadContentType,
 			},
 			TransformerV1: &PluginTypeManifestInfo{
-				Endpoint:                  "http://gh-registry.bluelink.local/plugins",
+				Endpoint:                  "http://gh-registry.bluelink.local/transformers",
 				DownloadAcceptContentType: DownloadContentType,
 			},
 			AuthV1: &AuthManifestInfo{
@@ -74,6 +75,97 @@ func (s *GetManifestHandlerTestSuite) TestGetManifest() {
 	)
 }
 
+func (s *GetManifestHandlerTestSuite) TestGetManifest_omits_download_auth_for_github_app_auth_mode() {
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_AUTH_MODE", core.AuthModeGitHubApp)
+	router := mux.NewRouter()
+	_, _, err := Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			return &registryDependencies{}, nil
+		},
+	)
+	s.Require().NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/bluelink-services.json")
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	manifest := &Manifest{}
+	err = json.NewDecoder(resp.Body).Decode(manifest)
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		&AuthManifestInfo{
+			APIKeyHeader: "bluelink-gh-registry-token",
+		},
+		manifest.AuthV1,
+	)
+}
+
+func (s *GetManifestHandlerTestSuite) TestGetManifest_includes_download_auth_for_download_proxy() {
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_AUTH_MODE", core.AuthModeGitHubApp)
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_DOWNLOAD_PROXY_ENABLED", "true")
+	router := mux.NewRouter()
+	_, _, err := Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			return &registryDependencies{}, nil
+		},
+	)
+	s.Require().NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/bluelink-services.json")
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	manifest := &Manifest{}
+	err = json.NewDecoder(resp.Body).Decode(manifest)
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		&AuthManifestInfo{
+			APIKeyHeader: "bluelink-gh-registry-token",
+			DownloadAuth: "bearer",
+		},
+		manifest.AuthV1,
+	)
+}
+
+func (s *GetManifestHandlerTestSuite) TestGetManifest_omits_download_auth_for_signed_download_urls() {
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_AUTH_MODE", core.AuthModeGitHubApp)
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_DOWNLOAD_PROXY_ENABLED", "true")
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_DOWNLOAD_URL_SIGNING_KEYS", "key-1:"+strings.Repeat("s", 32))
+	router := mux.NewRouter()
+	_, _, err := Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			return &registryDependencies{}, nil
+		},
+	)
+	s.Require().NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/bluelink-services.json")
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	manifest := &Manifest{}
+	err = json.NewDecoder(resp.Body).Decode(manifest)
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		&AuthManifestInfo{
+			APIKeyHeader: "bluelink-gh-registry-token",
+		},
+		manifest.AuthV1,
+	)
+}
+
 func TestGetManifestHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(GetManifestHandlerTestSuite))
 }
@@ -2,45 +2,81 @@ package registry
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 	"go.uber.org/zap"
 )
 
+// GetPluginPackageHandler retrieves the package information for
+// a plugin version and platform, when a plugin type is provided,
+// only the repository for the plugin type is used to resolve the plugin.
+// Requests for operating systems and architectures that are not
+// recognised are rejected, aliases are resolved to the recognised names.
+// The version can be "latest" or "latest-prerelease" to retrieve the package
+// for the latest version with an archive for the platform, the concrete
+// version is included in the response.
 func GetPluginPackageHandler(
 	config *core.Config,
 	logger *zap.Logger,
 	pluginService plugins.Service,
+	tokenResolver auth.TokenResolver,
+	pluginType string,
+	platforms *utils.Platforms,
 ) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, req *http.Request) {
-			token := req.Header.Get(config.AuthTokenHeader)
-			if strings.TrimSpace(token) == "" {
+			params := mux.Vars(req)
+			organisation := params["organisation"]
+			plugin := params["plugin"]
+
+			// The platform is validated before resolving the token
+			// as resolving the token can require a request to GitHub.
+			os, isRecognisedOS := platforms.OS(params["os"])
+			if !isRecognisedOS {
 				httputils.HTTPError(
 					w,
-					http.StatusUnauthorized,
-					"Unauthorized",
+					http.StatusBadRequest,
+					fmt.Sprintf("Unsupported operating system %q", params["os"]),
 				)
 				return
 			}
 
-			params := mux.Vars(req)
-			organisation := params["organisation"]
-			plugin := params["plugin"]
+			arch, isRecognisedArch := platforms.Arch(params["arch"])
+			if !isRecognisedArch {
+				httputils.HTTPError(
+					w,
+					http.StatusBadRequest,
+					fmt.Sprintf("Unsupported architecture %q", params["arch"]),
+				)
+				return
+			}
+
+			token, err := tokenResolver.ResolveToken(
+				req,
+				&auth.Resource{
+					Owner:  organisation,
+					Plugin: plugin,
+				},
+			)
+			if err != nil {
+				handleAuthError(w, err, logger)
+				return
+			}
 			version := params["version"]
-			os := params["os"]
-			arch := params["arch"]
 
 			packageInfo, err := pluginService.GetPackageInfo(
 				req.Context(),
 				&plugins.PackageInfoParams{
 					Organisation: organisation,
 					Plugin:       plugin,
+					PluginType:   pluginType,
 					Version:      version,
 					OS:           os,
 					Arch:         arch,
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
 	"github.com/stretchr/testify/suite"
@@ -26,13 +27,17 @@ func (s *GetPluginPackageHandlerTestSuite) SetupTest() {
 	getDeps := func(
 		config *core.Config,
 		logger *zap.Logger,
-	) *registryDependencies {
+	) (*registryDependencies, error) {
 		// Empty dependencies are fine for the get manifest handler
 		// as it doesn't make use of the dependencies to generate
 		// the manifest.
 		return &registryDependencies{
 			pluginService: &stubPluginService{},
-		}
+			tokenResolver: auth.NewTokenResolver(
+				auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+				auth.NewPassthroughTokenSource(),
+			),
+		}, nil
 	}
 
 	_, _, err := Setup(router, getDeps)
@@ -73,6 +78,75 @@ func (s *GetPluginPackageHandlerTestSuite) Test_get_plugin_package() {
 	)
 }
 
+func (s *GetPluginPackageHandlerTestSuite) Test_get_plugin_package_for_architecture_alias() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/aws/1.0.1/package/linux/x86_64", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	pkg := &types.PluginVersionPackage{}
+	err = json.Unmarshal(respBytes, pkg)
+	s.Require().NoError(err)
+
+	s.Require().Equal(
+		expectedVersionPackage,
+		pkg,
+	)
+}
+
+func (s *GetPluginPackageHandlerTestSuite) Test_returns_400_response_for_unsupported_os() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/aws/1.0.1/package/plan9/amd64", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	// The platform is validated before the token is checked.
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(400, resp.StatusCode)
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(
+		`{"message":"Unsupported operating system \"plan9\""}`,
+		string(respBytes),
+	)
+}
+
+func (s *GetPluginPackageHandlerTestSuite) Test_returns_400_response_for_unsupported_arch() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/aws/1.0.1/package/linux/riscv64", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(400, resp.StatusCode)
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(
+		`{"message":"Unsupported architecture \"riscv64\""}`,
+		string(respBytes),
+	)
+}
+
 func (s *GetPluginPackageHandlerTestSuite) Test_returns_401_response_for_missing_token() {
 	req, err := http.NewRequest(
 		http.MethodGet,
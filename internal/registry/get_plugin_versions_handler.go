@@ -2,41 +2,66 @@ package registry
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 	"go.uber.org/zap"
 )
 
+// GetPluginVersionsHandler lists the versions of a plugin,
+// when a plugin type is provided, only the repository for the
+// plugin type is used to resolve the plugin.
+// The release channel can be selected with the "channel" query parameter,
+// when not provided, the default release channel for the registry is used.
 func GetPluginVersionsHandler(
 	config *core.Config,
 	logger *zap.Logger,
 	pluginService plugins.Service,
+	tokenResolver auth.TokenResolver,
+	pluginType string,
 ) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, req *http.Request) {
-			token := req.Header.Get(config.AuthTokenHeader)
-			if strings.TrimSpace(token) == "" {
+			params := mux.Vars(req)
+			organisation := params["organisation"]
+			plugin := params["plugin"]
+
+			channel := req.URL.Query().Get("channel")
+			if channel != "" && utils.ValidateReleaseChannel(channel) != nil {
 				httputils.HTTPError(
 					w,
-					http.StatusUnauthorized,
-					"Unauthorized",
+					http.StatusBadRequest,
+					fmt.Sprintf("Unsupported release channel %q", channel),
 				)
 				return
 			}
 
-			params := mux.Vars(req)
-			organisation := params["organisation"]
-			plugin := params["plugin"]
+			token, err := tokenResolver.ResolveToken(
+				req,
+				&auth.Resource{
+					Owner:  organisation,
+					Plugin: plugin,
+				},
+			)
+			if err != nil {
+				handleAuthError(w, err, logger)
+				return
+			}
 
 			pluginVersions, err := pluginService.ListVersions(
 				req.Context(),
-				organisation,
-				plugin,
+				&plugins.ListVersionsParams{
+					Organisation: organisation,
+					Plugin:       plugin,
+					PluginType:   pluginType,
+					Channel:      channel,
+				},
 				token,
 			)
 			if err != nil {
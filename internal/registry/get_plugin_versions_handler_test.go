@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
 	"github.com/stretchr/testify/suite"
@@ -26,13 +27,17 @@ func (s *GetPluginVersionsHandlerTestSuite) SetupTest() {
 	getDeps := func(
 		config *core.Config,
 		logger *zap.Logger,
-	) *registryDependencies {
+	) (*registryDependencies, error) {
 		// Empty dependencies are fine for the get manifest handler
 		// as it doesn't make use of the dependencies to generate
 		// the manifest.
 		return &registryDependencies{
 			pluginService: &stubPluginService{},
-		}
+			tokenResolver: auth.NewTokenResolver(
+				auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+				auth.NewPassthroughTokenSource(),
+			),
+		}, nil
 	}
 
 	_, _, err := Setup(router, getDeps)
@@ -73,6 +78,69 @@ func (s *GetPluginVersionsHandlerTestSuite) Test_get_plugin_versions() {
 	)
 }
 
+func (s *GetPluginVersionsHandlerTestSuite) Test_get_plugin_versions_for_plugin_type() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/providers/newstack-cloud/aws/versions", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	versions := &types.PluginVersions{}
+	err = json.Unmarshal(respBytes, versions)
+	s.Require().NoError(err)
+
+	s.Require().Equal(
+		expectedVersions,
+		versions,
+	)
+}
+
+func (s *GetPluginVersionsHandlerTestSuite) Test_returns_404_response_for_plugin_of_other_type() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/transformers/newstack-cloud/aws/versions", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(404, resp.StatusCode)
+}
+
+func (s *GetPluginVersionsHandlerTestSuite) Test_returns_400_response_for_unsupported_release_channel() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/aws/versions?channel=nightly", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(400, resp.StatusCode)
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(
+		`{"message":"Unsupported release channel \"nightly\""}`,
+		string(respBytes),
+	)
+}
+
 func (s *GetPluginVersionsHandlerTestSuite) Test_returns_401_response_for_missing_token() {
 	req, err := http.NewRequest(
 		http.MethodGet,
@@ -157,6 +225,76 @@ func (s *GetPluginVersionsHandlerTestSuite) Test_returns_403_response_for_a_forb
 	)
 }
 
+func (s *GetPluginVersionsHandlerTestSuite) Test_returns_429_response_for_a_rate_limited_request() {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/plugins/newstack-cloud/rate-limited-plugin/versions", s.server.URL),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(429, resp.StatusCode)
+	s.Assert().Equal("2", resp.Header.Get("Retry-After"))
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(
+		`{"message":"Rate limited by GitHub, try again later"}`,
+		string(respBytes),
+	)
+}
+
+func (s *GetPluginVersionsHandlerTestSuite) Test_authenticates_client_tokens_for_github_app_auth_mode() {
+	router := mux.NewRouter()
+	_, _, err := Setup(
+		router,
+		func(config *core.Config, logger *zap.Logger) (*registryDependencies, error) {
+			return &registryDependencies{
+				pluginService: &stubPluginService{},
+				tokenResolver: auth.NewTokenResolver(
+					auth.NewStaticTokenAuthenticator(
+						config.AuthTokenHeader,
+						[]string{"client-token"},
+					),
+					&stubInstallationTokenSource{
+						owner: "newstack-cloud",
+					},
+				),
+			}, nil
+		},
+	)
+	s.Require().NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	cases := []struct {
+		org                string
+		clientToken        string
+		expectedStatusCode int
+	}{
+		{org: "newstack-cloud", clientToken: "client-token", expectedStatusCode: 200},
+		{org: "newstack-cloud", clientToken: "ghp_github-token", expectedStatusCode: 401},
+		{org: "other-owner", clientToken: "client-token", expectedStatusCode: 404},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(
+			http.MethodGet,
+			fmt.Sprintf("%s/plugins/%s/aws/versions", server.URL, c.org),
+			nil,
+		)
+		s.Require().NoError(err)
+		req.Header.Set("bluelink-gh-registry-token", c.clientToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Assert().Equal(c.expectedStatusCode, resp.StatusCode)
+	}
+}
+
 func TestGetPluginVersionsHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(GetPluginVersionsHandlerTestSuite))
 }
@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// GitHub caps webhook payloads at 25MB.
+	maxWebhookPayloadSize = 25 * 1024 * 1024
+
+	// The maximum amount of time to spend pre-warming
+	// the cache for a plugin after a release event.
+	prewarmTimeout = 60 * time.Second
+
+	// The subject of the principal that cached plugin
+	// versions are pre-warmed on behalf of.
+	prewarmSubject = "github-webhook-prewarm"
+)
+
+var (
+	handledReleaseActions = []string{
+		"published",
+		"edited",
+		"deleted",
+	}
+
+	// Release actions after which the plugin versions
+	// can be fetched to pre-warm the cache.
+	prewarmReleaseActions = []string{
+		"published",
+		"edited",
+	}
+
+	handledRepositoryActions = []string{
+		"renamed",
+		"archived",
+		"deleted",
+	}
+)
+
+// GitHubWebhookHandler handles webhook events from GitHub to invalidate
+// cached plugin data when releases or plugin repositories change.
+// Payloads must be signed with the configured webhook secret and
+// delivered with the `application/json` content type.
+// The naming convention is used to determine the plugin that a
+// repository is for when pre-warming cached plugin versions.
+// Cached plugin versions are only pre-warmed when a token source for
+// pre-warming is provided, cached data is keyed by token so the token
+// source must provide the same tokens that are used for client requests.
+func GitHubWebhookHandler(
+	config *core.Config,
+	logger *zap.Logger,
+	cacheInvalidator plugins.CacheInvalidator,
+	pluginService plugins.Service,
+	naming *utils.NamingConvention,
+	prewarmTokenSource auth.TokenSource,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			payload, err := io.ReadAll(
+				http.MaxBytesReader(w, req.Body, maxWebhookPayloadSize),
+			)
+			if err != nil {
+				httputils.HTTPError(
+					w,
+					http.StatusBadRequest,
+					"Failed to read webhook payload",
+				)
+				return
+			}
+
+			signature := req.Header.Get(github.SHA256SignatureHeader)
+			if signature == "" ||
+				github.ValidateSignature(
+					signature,
+					payload,
+					[]byte(config.GitHubWebhookSecret),
+				) != nil {
+				httputils.HTTPError(
+					w,
+					http.StatusUnauthorized,
+					"Invalid webhook signature",
+				)
+				return
+			}
+
+			event, err := github.ParseWebHook(github.WebHookType(req), payload)
+			if err != nil {
+				// Events that are not relevant to the registry
+				// (including unknown event types) are acknowledged
+				// so GitHub does not mark the deliveries as failed.
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			switch event := event.(type) {
+			case *github.ReleaseEvent:
+				handleReleaseEvent(
+					event,
+					config,
+					logger,
+					cacheInvalidator,
+					pluginService,
+					naming,
+					prewarmTokenSource,
+				)
+			case *github.RepositoryEvent:
+				handleRepositoryEvent(event, logger, cacheInvalidator)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+func handleReleaseEvent(
+	event *github.ReleaseEvent,
+	config *core.Config,
+	logger *zap.Logger,
+	cacheInvalidator plugins.CacheInvalidator,
+	pluginService plugins.Service,
+	naming *utils.NamingConvention,
+	prewarmTokenSource auth.TokenSource,
+) {
+	action := event.GetAction()
+	if !slices.Contains(handledReleaseActions, action) {
+		return
+	}
+
+	owner := event.GetRepo().GetOwner().GetLogin()
+	repo := event.GetRepo().GetName()
+	logger.Info(
+		"Invalidating cached plugin data for release event",
+		zap.String("action", action),
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+		zap.String("tag", event.GetRelease().GetTagName()),
+	)
+	cacheInvalidator.InvalidateRepository(owner, repo)
+
+	if prewarmTokenSource != nil &&
+		slices.Contains(prewarmReleaseActions, action) {
+		go prewarmPluginVersions(
+			owner,
+			repo,
+			config,
+			logger,
+			pluginService,
+			naming,
+			prewarmTokenSource,
+		)
+	}
+}
+
+func handleRepositoryEvent(
+	event *github.RepositoryEvent,
+	logger *zap.Logger,
+	cacheInvalidator plugins.CacheInvalidator,
+) {
+	action := event.GetAction()
+	if !slices.Contains(handledRepositoryActions, action) {
+		return
+	}
+
+	owner := event.GetRepo().GetOwner().GetLogin()
+	repo := event.GetRepo().GetName()
+	logger.Info(
+		"Invalidating cached plugin data for repository event",
+		zap.String("action", action),
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	if action == "deleted" {
+		cacheInvalidator.RemoveRepository(owner, repo)
+	} else {
+		cacheInvalidator.InvalidateRepository(owner, repo)
+	}
+	cacheInvalidator.InvalidateOwnerRepos(owner)
+
+	previousName := event.GetChanges().GetRepo().GetName().GetFrom()
+	if action == "renamed" && previousName != "" {
+		cacheInvalidator.RemoveRepository(owner, previousName)
+	}
+}
+
+func prewarmPluginVersions(
+	owner string,
+	repo string,
+	config *core.Config,
+	logger *zap.Logger,
+	pluginService plugins.Service,
+	naming *utils.NamingConvention,
+	prewarmTokenSource auth.TokenSource,
+) {
+	plugin, pluginType, isPluginRepo := naming.ParseRepoName(owner, repo)
+	if !isPluginRepo {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prewarmTimeout)
+	defer cancel()
+
+	token, err := prewarmTokenSource.Token(
+		ctx,
+		&auth.Principal{
+			Subject:     prewarmSubject,
+			GitHubToken: config.WebhookPrewarmToken,
+		},
+		owner,
+	)
+	if err != nil {
+		logger.Warn(
+			"Failed to obtain token to pre-warm cached plugin versions",
+			zap.String("owner", owner),
+			zap.String("plugin", plugin),
+			zap.Error(err),
+		)
+		return
+	}
+
+	_, err = pluginService.ListVersions(
+		ctx,
+		&plugins.ListVersionsParams{
+			Organisation: owner,
+			Plugin:       plugin,
+			PluginType:   pluginType,
+		},
+		token,
+	)
+	if err != nil {
+		logger.Warn(
+			"Failed to pre-warm cached plugin versions",
+			zap.String("owner", owner),
+			zap.String("plugin", plugin),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,295 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+const testWebhookSecret = "test-webhook-secret"
+
+type GitHubWebhookHandlerTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	invalidator *stubCacheInvalidator
+}
+
+func (s *GitHubWebhookHandlerTestSuite) SetupTest() {
+	s.T().Setenv("BLUELINK_GITHUB_REGISTRY_GITHUB_WEBHOOK_SECRET", testWebhookSecret)
+	s.invalidator = &stubCacheInvalidator{}
+
+	router := mux.NewRouter()
+	getDeps := func(
+		config *core.Config,
+		logger *zap.Logger,
+	) (*registryDependencies, error) {
+		return &registryDependencies{
+			pluginService:    &stubPluginService{},
+			cacheInvalidator: s.invalidator,
+		}, nil
+	}
+
+	_, _, err := Setup(router, getDeps)
+	s.Require().NoError(err)
+
+	server := httptest.NewServer(router)
+	s.server = server
+}
+
+func (s *GitHubWebhookHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_invalidates_repository_for_release_event() {
+	payload := []byte(`{
+		"action": "published",
+		"release": {"tag_name": "v1.2.0"},
+		"repository": {
+			"name": "bluelink-provider-aws",
+			"owner": {"login": "newstack-cloud"}
+		}
+	}`)
+
+	resp := s.sendEvent("release", payload, sign(payload, testWebhookSecret))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	s.Assert().Equal(
+		[]string{"newstack-cloud/bluelink-provider-aws"},
+		s.invalidator.invalidatedRepos(),
+	)
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_invalidates_new_name_and_removes_old_name_for_renamed_repository() {
+	payload := []byte(`{
+		"action": "renamed",
+		"changes": {"repository": {"name": {"from": "bluelink-provider-old"}}},
+		"repository": {
+			"name": "bluelink-provider-aws",
+			"owner": {"login": "newstack-cloud"}
+		}
+	}`)
+
+	resp := s.sendEvent("repository", payload, sign(payload, testWebhookSecret))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	s.Assert().Equal(
+		[]string{"newstack-cloud/bluelink-provider-aws"},
+		s.invalidator.invalidatedRepos(),
+	)
+	s.Assert().Equal(
+		[]string{"newstack-cloud/bluelink-provider-old"},
+		s.invalidator.removedRepos(),
+	)
+	s.Assert().Equal([]string{"newstack-cloud"}, s.invalidator.invalidatedOwners())
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_removes_deleted_repository() {
+	payload := []byte(`{
+		"action": "deleted",
+		"repository": {
+			"name": "bluelink-provider-aws",
+			"owner": {"login": "newstack-cloud"}
+		}
+	}`)
+
+	resp := s.sendEvent("repository", payload, sign(payload, testWebhookSecret))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	s.Assert().Empty(s.invalidator.invalidatedRepos())
+	s.Assert().Equal(
+		[]string{"newstack-cloud/bluelink-provider-aws"},
+		s.invalidator.removedRepos(),
+	)
+	s.Assert().Equal([]string{"newstack-cloud"}, s.invalidator.invalidatedOwners())
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_prewarms_plugin_versions_with_installation_token() {
+	pluginService := &recordingPluginService{}
+	router := mux.NewRouter()
+	getDeps := func(
+		config *core.Config,
+		logger *zap.Logger,
+	) (*registryDependencies, error) {
+		return &registryDependencies{
+			pluginService:    pluginService,
+			cacheInvalidator: &stubCacheInvalidator{},
+			prewarmTokenSource: &stubInstallationTokenSource{
+				owner: "newstack-cloud",
+			},
+		}, nil
+	}
+	_, _, err := Setup(router, getDeps)
+	s.Require().NoError(err)
+	s.server.Close()
+	s.server = httptest.NewServer(router)
+
+	payload := []byte(`{
+		"action": "published",
+		"release": {"tag_name": "v1.2.0"},
+		"repository": {
+			"name": "bluelink-provider-aws",
+			"owner": {"login": "newstack-cloud"}
+		}
+	}`)
+
+	resp := s.sendEvent("release", payload, sign(payload, testWebhookSecret))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	s.Assert().Eventually(
+		func() bool {
+			return slices.Equal(
+				pluginService.listVersionsTokens(),
+				[]string{"ghs_installation-token"},
+			)
+		},
+		time.Second,
+		10*time.Millisecond,
+	)
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_ignores_unhandled_release_actions() {
+	payload := []byte(`{
+		"action": "created",
+		"release": {"tag_name": "v1.2.0"},
+		"repository": {
+			"name": "bluelink-provider-aws",
+			"owner": {"login": "newstack-cloud"}
+		}
+	}`)
+
+	resp := s.sendEvent("release", payload, sign(payload, testWebhookSecret))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	s.Assert().Empty(s.invalidator.invalidatedRepos())
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_returns_401_response_for_invalid_signature() {
+	payload := []byte(`{"action": "published"}`)
+
+	resp := s.sendEvent("release", payload, sign(payload, "other-secret"))
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+	s.Assert().Empty(s.invalidator.invalidatedRepos())
+}
+
+func (s *GitHubWebhookHandlerTestSuite) Test_returns_401_response_for_missing_signature() {
+	payload := []byte(`{"action": "published"}`)
+
+	resp := s.sendEvent("release", payload, "")
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (s *GitHubWebhookHandlerTestSuite) sendEvent(
+	eventType string,
+	payload []byte,
+	signature string,
+) *http.Response {
+	req, err := http.NewRequest(
+		http.MethodPost,
+		s.server.URL+"/webhooks/github",
+		bytes.NewReader(payload),
+	)
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", eventType)
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type stubCacheInvalidator struct {
+	mu      sync.Mutex
+	repos   []string
+	removed []string
+	owners  []string
+}
+
+func (s *stubCacheInvalidator) InvalidateRepository(owner string, repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = append(s.repos, owner+"/"+repo)
+}
+
+func (s *stubCacheInvalidator) RemoveRepository(owner string, repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = append(s.removed, owner+"/"+repo)
+}
+
+func (s *stubCacheInvalidator) InvalidateOwnerRepos(owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owners = append(s.owners, owner)
+}
+
+func (s *stubCacheInvalidator) invalidatedRepos() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repos
+}
+
+func (s *stubCacheInvalidator) removedRepos() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removed
+}
+
+func (s *stubCacheInvalidator) invalidatedOwners() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owners
+}
+
+// recordingPluginService records the tokens used to list plugin versions.
+type recordingPluginService struct {
+	stubPluginService
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (s *recordingPluginService) ListVersions(
+	ctx context.Context,
+	params *plugins.ListVersionsParams,
+	token string,
+) (*types.PluginVersions, error) {
+	s.mu.Lock()
+	s.tokens = append(s.tokens, token)
+	s.mu.Unlock()
+	return s.stubPluginService.ListVersions(ctx, params, token)
+}
+
+func (s *recordingPluginService) listVersionsTokens() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.tokens)
+}
+
+func TestGitHubWebhookHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(GitHubWebhookHandlerTestSuite))
+}
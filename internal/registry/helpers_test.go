@@ -2,10 +2,18 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 )
 
 type stubPluginService struct{}
@@ -55,15 +63,20 @@ var (
 
 func (s *stubPluginService) ListVersions(
 	ctx context.Context,
-	organisation string,
-	plugin string,
+	params *plugins.ListVersionsParams,
 	token string,
 ) (*types.PluginVersions, error) {
+	organisation := params.Organisation
+	plugin := params.Plugin
 	if plugin == "forbidden-plugin" {
 		return nil, plugins.ErrForbidden
 	}
 
-	if plugin != "aws" {
+	if plugin == "rate-limited-plugin" {
+		return nil, &plugins.RateLimitError{RetryAfter: 1500 * time.Millisecond}
+	}
+
+	if !isStubProviderPlugin(plugin, params.PluginType) {
 		return nil, plugins.ErrRepoNotFound
 	}
 
@@ -76,10 +89,12 @@ func (s *stubPluginService) ListVersions(
 
 var (
 	expectedVersionPackage = &types.PluginVersionPackage{
+		Version:             "3.0.1",
 		SupportedProtocols:  []string{"1.5", "2.1"},
 		OS:                  "linux",
 		Arch:                "amd64",
 		Filename:            "bluelink-provider-aws_3.0.1_linux_amd64.zip",
+		ArchiveFormat:       "zip",
 		DownloadURL:         *testutils.GithubAssetURL(1),
 		SHASumsURL:          *testutils.GithubAssetURL(2),
 		SHASumsSignatureURL: *testutils.GithubAssetURL(3),
@@ -104,8 +119,76 @@ func (s *stubPluginService) GetPackageInfo(
 	params *plugins.PackageInfoParams,
 	token string,
 ) (*types.PluginVersionPackage, error) {
-	if params.Plugin != "aws" {
+	if !isStubProviderPlugin(params.Plugin, params.PluginType) {
 		return nil, plugins.ErrRepoNotFound
 	}
+
+	if params.OS != "linux" || params.Arch != "amd64" {
+		return nil, plugins.ErrAssetNotFound
+	}
 	return expectedVersionPackage, nil
 }
+
+// The "aws" plugin is the only plugin that exists in the stub service,
+// it is a provider plugin so it can not be resolved as a transformer.
+func isStubProviderPlugin(plugin string, pluginType string) bool {
+	return plugin == "aws" &&
+		(pluginType == "" || pluginType == utils.PluginTypeProvider)
+}
+
+const testAssetContents = "plugin-archive-contents"
+
+func (s *stubPluginService) OpenAssetDownload(
+	ctx context.Context,
+	params *plugins.AssetDownloadParams,
+	token string,
+) (*plugins.AssetDownload, error) {
+	if !isStubProviderPlugin(params.Plugin, params.PluginType) {
+		return nil, plugins.ErrRepoNotFound
+	}
+
+	if params.Asset != "bluelink-provider-aws_3.0.1_linux_amd64.zip" {
+		return nil, plugins.ErrAssetNotFound
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Accept-Ranges", "bytes")
+	// Only open-ended ranges are needed to test
+	// resuming downloads.
+	if start, isRange := strings.CutPrefix(params.Range, "bytes="); isRange {
+		offset, _ := strconv.Atoi(strings.TrimSuffix(start, "-"))
+		header.Set("Content-Length", strconv.Itoa(len(testAssetContents)-offset))
+		header.Set(
+			"Content-Range",
+			fmt.Sprintf("bytes %d-%d/%d", offset, len(testAssetContents)-1, len(testAssetContents)),
+		)
+		return &plugins.AssetDownload{
+			StatusCode: http.StatusPartialContent,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(testAssetContents[offset:])),
+		}, nil
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(testAssetContents)))
+	return &plugins.AssetDownload{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(testAssetContents)),
+	}, nil
+}
+
+type stubInstallationTokenSource struct {
+	owner string
+}
+
+func (s *stubInstallationTokenSource) Token(
+	ctx context.Context,
+	principal *auth.Principal,
+	owner string,
+) (string, error) {
+	if owner != s.owner {
+		return "", auth.ErrNoInstallation
+	}
+	return "ghs_installation-token", nil
+}
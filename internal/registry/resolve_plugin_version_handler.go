@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
+	"go.uber.org/zap"
+)
+
+// ResolvePluginVersionHandler resolves the plugin version with the highest
+// precedence that satisfies the version constraint and supports the platform
+// and protocol provided in the "constraint", "os", "arch" and "protocol"
+// query parameters, responding with the resolved version and its package
+// information.
+// When no version can be resolved, the response explains why each of the
+// versions of the plugin was not resolved.
+func ResolvePluginVersionHandler(
+	config *core.Config,
+	logger *zap.Logger,
+	pluginService plugins.Service,
+	tokenResolver auth.TokenResolver,
+	pluginType string,
+	platforms *utils.Platforms,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			params := mux.Vars(req)
+			organisation := params["organisation"]
+			plugin := params["plugin"]
+
+			// The query parameters are validated before resolving the token
+			// as resolving the token can require a request to GitHub.
+			resolveParams, errMessage := resolveVersionParams(req, platforms)
+			if errMessage != "" {
+				httputils.HTTPError(w, http.StatusBadRequest, errMessage)
+				return
+			}
+
+			token, err := tokenResolver.ResolveToken(
+				req,
+				&auth.Resource{
+					Owner:  organisation,
+					Plugin: plugin,
+				},
+			)
+			if err != nil {
+				handleAuthError(w, err, logger)
+				return
+			}
+
+			// Whether prereleases can be resolved is determined
+			// by the version constraint.
+			pluginVersions, err := pluginService.ListVersions(
+				req.Context(),
+				&plugins.ListVersionsParams{
+					Organisation: organisation,
+					Plugin:       plugin,
+					PluginType:   pluginType,
+					Channel:      utils.ReleaseChannelAll,
+				},
+				token,
+			)
+			if err != nil {
+				handlePluginError(w, err, logger)
+				return
+			}
+
+			resolved, unresolved := utils.ResolveVersion(
+				pluginVersions.Versions,
+				resolveParams,
+			)
+			if resolved == nil {
+				httputils.HTTPErrorWithFields(
+					w,
+					http.StatusNotFound,
+					fmt.Sprintf(
+						"No version of the plugin satisfies %q for %s/%s and protocol %s",
+						resolveParams.Constraint,
+						resolveParams.OS,
+						resolveParams.Arch,
+						resolveParams.Protocol,
+					),
+					map[string]any{
+						"candidates": unresolved,
+					},
+				)
+				return
+			}
+
+			packageInfo, err := pluginService.GetPackageInfo(
+				req.Context(),
+				&plugins.PackageInfoParams{
+					Organisation: organisation,
+					Plugin:       plugin,
+					PluginType:   pluginType,
+					Version:      resolved.Version,
+					OS:           resolveParams.OS,
+					Arch:         resolveParams.Arch,
+				},
+				token,
+			)
+			if err != nil {
+				handlePluginError(w, err, logger)
+				return
+			}
+
+			respBytes, err := json.Marshal(&types.PluginVersionResolution{
+				Version: resolved,
+				Package: packageInfo,
+			})
+			if err != nil {
+				logger.Error(
+					"Error marshalling resolved plugin version information",
+					zap.Error(err),
+				)
+				httputils.HTTPError(
+					w,
+					http.StatusInternalServerError,
+					"An unexpected error occurred",
+				)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(respBytes)
+		},
+	)
+}
+
+// resolveVersionParams extracts the requirements for resolving a plugin
+// version from the query parameters of a request, returning a message
+// describing the problem when a parameter is missing or invalid.
+func resolveVersionParams(
+	req *http.Request,
+	platforms *utils.Platforms,
+) (*utils.ResolveVersionParams, string) {
+	query := req.URL.Query()
+	for _, name := range []string{"constraint", "os", "arch", "protocol"} {
+		if query.Get(name) == "" {
+			return nil, fmt.Sprintf("Missing required query parameter %q", name)
+		}
+	}
+
+	constraint, err := utils.ParseVersionConstraint(query.Get("constraint"))
+	if err != nil {
+		return nil, fmt.Sprintf("Invalid version constraint: %s", err)
+	}
+
+	os, isRecognisedOS := platforms.OS(query.Get("os"))
+	if !isRecognisedOS {
+		return nil, fmt.Sprintf("Unsupported operating system %q", query.Get("os"))
+	}
+
+	arch, isRecognisedArch := platforms.Arch(query.Get("arch"))
+	if !isRecognisedArch {
+		return nil, fmt.Sprintf("Unsupported architecture %q", query.Get("arch"))
+	}
+
+	return &utils.ResolveVersionParams{
+		Constraint: constraint,
+		OS:         os,
+		Arch:       arch,
+		Protocol:   query.Get("protocol"),
+	}, ""
+}
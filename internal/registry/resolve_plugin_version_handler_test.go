@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ResolvePluginVersionHandlerTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) SetupTest() {
+	router := mux.NewRouter()
+
+	getDeps := func(
+		config *core.Config,
+		logger *zap.Logger,
+	) (*registryDependencies, error) {
+		return &registryDependencies{
+			pluginService: &stubPluginService{},
+			tokenResolver: auth.NewTokenResolver(
+				auth.NewPassthroughAuthenticator(config.AuthTokenHeader),
+				auth.NewPassthroughTokenSource(),
+			),
+		}, nil
+	}
+
+	_, _, err := Setup(router, getDeps)
+	s.Require().NoError(err)
+
+	server := httptest.NewServer(router)
+	s.server = server
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) Test_resolves_plugin_version() {
+	resp := s.resolve("/plugins/newstack-cloud/aws/resolve", url.Values{
+		"constraint": {"~3.0.0"},
+		"os":         {"linux"},
+		"arch":       {"x86_64"},
+		"protocol":   {"1.5"},
+	})
+	s.Require().Equal(200, resp.StatusCode)
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	resolution := &types.PluginVersionResolution{}
+	err = json.Unmarshal(respBytes, resolution)
+	s.Require().NoError(err)
+
+	s.Require().Equal(
+		&types.PluginVersionResolution{
+			Version: expectedVersions.Versions[0],
+			Package: expectedVersionPackage,
+		},
+		resolution,
+	)
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) Test_returns_404_response_with_reasons_when_no_version_matches() {
+	resp := s.resolve("/providers/newstack-cloud/aws/resolve", url.Values{
+		"constraint": {"^3.1.0"},
+		"os":         {"linux"},
+		"arch":       {"arm64"},
+		"protocol":   {"1.5"},
+	})
+	s.Require().Equal(404, resp.StatusCode)
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().JSONEq(
+		`{
+			"message": "No version of the plugin satisfies \"^3.1.0\" for linux/arm64 and protocol 1.5",
+			"candidates": [
+				{
+					"version": "3.0.1",
+					"reasons": ["constraint_not_satisfied", "platform_not_supported"]
+				},
+				{
+					"version": "3.1.0",
+					"reasons": ["platform_not_supported"]
+				}
+			]
+		}`,
+		string(respBytes),
+	)
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) Test_returns_400_response_for_invalid_query_parameters() {
+	testCases := map[string]struct {
+		query           url.Values
+		expectedMessage string
+	}{
+		"missing constraint": {
+			query: url.Values{
+				"os":       {"linux"},
+				"arch":     {"amd64"},
+				"protocol": {"1.5"},
+			},
+			expectedMessage: `Missing required query parameter \"constraint\"`,
+		},
+		"invalid constraint": {
+			query: url.Values{
+				"constraint": {"latest"},
+				"os":         {"linux"},
+				"arch":       {"amd64"},
+				"protocol":   {"1.5"},
+			},
+			expectedMessage: `Invalid version constraint: invalid version constraint comparator \"latest\"`,
+		},
+		"unsupported os": {
+			query: url.Values{
+				"constraint": {"^3.0.0"},
+				"os":         {"plan9"},
+				"arch":       {"amd64"},
+				"protocol":   {"1.5"},
+			},
+			expectedMessage: `Unsupported operating system \"plan9\"`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		s.Run(name, func() {
+			resp := s.resolve("/plugins/newstack-cloud/aws/resolve", testCase.query)
+			s.Require().Equal(400, resp.StatusCode)
+			defer resp.Body.Close()
+
+			respBytes, err := io.ReadAll(resp.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(
+				fmt.Sprintf(`{"message":"%s"}`, testCase.expectedMessage),
+				string(respBytes),
+			)
+		})
+	}
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) Test_returns_404_response_for_missing_plugin_repo() {
+	resp := s.resolve("/transformers/newstack-cloud/aws/resolve", url.Values{
+		"constraint": {"^3.0.0"},
+		"os":         {"linux"},
+		"arch":       {"amd64"},
+		"protocol":   {"1.5"},
+	})
+	defer resp.Body.Close()
+	s.Require().Equal(404, resp.StatusCode)
+}
+
+func (s *ResolvePluginVersionHandlerTestSuite) resolve(path string, query url.Values) *http.Response {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s%s?%s", s.server.URL, path, query.Encode()),
+		nil,
+	)
+	s.Require().NoError(err)
+	req.Header.Set("bluelink-gh-registry-token", "test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func TestResolvePluginVersionHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolvePluginVersionHandlerTestSuite))
+}
@@ -4,23 +4,39 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/core"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/utils"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type registryDependencies struct {
-	pluginService plugins.Service
+	pluginService    plugins.Service
+	cacheInvalidator plugins.CacheInvalidator
+	tokenResolver    auth.TokenResolver
+	// downloadTokenResolver resolves tokens for release asset downloads,
+	// when nil, tokenResolver is used for downloads.
+	downloadTokenResolver auth.TokenResolver
+	// naming is the naming convention for plugin repositories,
+	// when nil, the default naming convention is used.
+	naming *utils.NamingConvention
+	// prewarmTokenSource provides the tokens used to pre-warm cached
+	// plugin versions after release events, when nil, the configured
+	// webhook pre-warm token is used if one is set.
+	prewarmTokenSource auth.TokenSource
 }
 
 type dependenciesRetriever func(
 	config *core.Config,
 	logger *zap.Logger,
-) *registryDependencies
+) (*registryDependencies, error)
 
 // Setup initialises and configures the http endpoint
 // handlers for the registry.
@@ -55,7 +71,15 @@ func Setup(
 		return 0, nil, err
 	}
 
-	deps := getDeps(&config, appLogger)
+	deps, err := getDeps(&config, appLogger)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	naming := deps.naming
+	if naming == nil {
+		naming = utils.DefaultNamingConvention()
+	}
 
 	// Writes access logs to the io.Writer in the Apache Combined Log Format.
 	router.Use(func(next http.Handler) http.Handler {
@@ -70,23 +94,161 @@ func Setup(
 		GetManifestHandler(&config),
 	).Methods("GET")
 
-	// The registry protocol endpoints come under the "/plugins/" path prefix.
-	// The auth token provided by the client will be passed through to make requests
-	// to the underlying repositories, if those requests fail due to auth issues,
-	// then those errors will be returned to the client.
-	protocolRouter := router.PathPrefix("/plugins/").Subrouter()
+	// The registry protocol endpoints come under the "/plugins/" path prefix,
+	// which resolves plugins of any type, along with the "/providers/" and
+	// "/transformers/" path prefixes which only resolve plugins of a single type.
+	// In the default passthrough auth mode, the auth token provided by the client
+	// will be passed through to make requests to the underlying repositories,
+	// if those requests fail due to auth issues, then those errors will be returned
+	// to the client.
+	// In the GitHub App auth mode, clients authenticate with a client token and
+	// requests to the underlying repositories are made with installation tokens.
+	for _, pluginType := range append([]string{""}, utils.PluginTypes...) {
+		protocolRouter := router.PathPrefix(
+			plugins.EndpointPath(pluginType) + "/",
+		).Subrouter()
+		setupProtocolRoutes(
+			protocolRouter,
+			pluginType,
+			&config,
+			appLogger,
+			deps,
+			naming.Platforms(),
+		)
+	}
+
+	// The webhook receiver is only enabled when a secret is configured
+	// as the signature of every delivery must be verified.
+	if config.GitHubWebhookSecret != "" {
+		router.Handle(
+			"/webhooks/github",
+			GitHubWebhookHandler(
+				&config,
+				appLogger,
+				deps.cacheInvalidator,
+				deps.pluginService,
+				naming,
+				prewarmTokenSource(&config, deps),
+			),
+		).Methods("POST")
+	}
+
+	return config.Port, accessLogWriter, nil
+}
+
+// prewarmTokenSource returns the token source used to pre-warm cached
+// plugin versions, in the GitHub App auth mode, this is the installation
+// token source so the tokens match those used for client requests.
+// Otherwise, the configured pre-warm token is passed through, this must be
+// the same token that clients send for pre-warmed data to be used.
+func prewarmTokenSource(
+	config *core.Config,
+	deps *registryDependencies,
+) auth.TokenSource {
+	if deps.prewarmTokenSource != nil {
+		return deps.prewarmTokenSource
+	}
+
+	if config.WebhookPrewarmToken != "" {
+		return auth.NewPassthroughTokenSource()
+	}
+
+	return nil
+}
+
+// The path of the route for release asset downloads,
+// relative to the path prefix for a plugin type.
+const downloadAssetPath = "/{organisation}/{plugin}/{version}/download/{asset}"
+
+// TimeoutHandler wraps the router in a http.TimeoutHandler for all routes
+// apart from release asset downloads.
+// The timeout handler buffers responses until the handler returns,
+// release assets are streamed to clients instead so that large downloads
+// do not have to fit within the timeout or in memory.
+func TimeoutHandler(router *mux.Router, timeout time.Duration, message string) http.Handler {
+	timeoutHandler := http.TimeoutHandler(router, timeout, message)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		match := &mux.RouteMatch{}
+		if router.Match(req, match) && isDownloadAssetRoute(match.Route) {
+			router.ServeHTTP(w, req)
+			return
+		}
+
+		timeoutHandler.ServeHTTP(w, req)
+	})
+}
+
+func isDownloadAssetRoute(route *mux.Route) bool {
+	if route == nil {
+		return false
+	}
+
+	pathTemplate, err := route.GetPathTemplate()
+	return err == nil && strings.HasSuffix(pathTemplate, downloadAssetPath)
+}
 
+func setupProtocolRoutes(
+	protocolRouter *mux.Router,
+	pluginType string,
+	config *core.Config,
+	logger *zap.Logger,
+	deps *registryDependencies,
+	platforms *utils.Platforms,
+) {
 	protocolRouter.Handle(
 		"/{organisation}/{plugin}/versions",
-		GetPluginVersionsHandler(&config, appLogger, deps.pluginService),
+		GetPluginVersionsHandler(
+			config,
+			logger,
+			deps.pluginService,
+			deps.tokenResolver,
+			pluginType,
+		),
 	).Methods("GET")
 
 	protocolRouter.Handle(
 		"/{organisation}/{plugin}/{version}/package/{os}/{arch}",
-		GetPluginPackageHandler(&config, appLogger, deps.pluginService),
+		GetPluginPackageHandler(
+			config,
+			logger,
+			deps.pluginService,
+			deps.tokenResolver,
+			pluginType,
+			platforms,
+		),
 	).Methods("GET")
 
-	return config.Port, accessLogWriter, nil
+	protocolRouter.Handle(
+		"/{organisation}/{plugin}/resolve",
+		ResolvePluginVersionHandler(
+			config,
+			logger,
+			deps.pluginService,
+			deps.tokenResolver,
+			pluginType,
+			platforms,
+		),
+	).Methods("GET")
+
+	// Release assets are only served by the registry when the download
+	// proxy is enabled, otherwise clients download assets from GitHub.
+	if config.DownloadProxyEnabled {
+		downloadTokenResolver := deps.downloadTokenResolver
+		if downloadTokenResolver == nil {
+			downloadTokenResolver = deps.tokenResolver
+		}
+
+		protocolRouter.Handle(
+			downloadAssetPath,
+			DownloadPluginAssetHandler(
+				config,
+				logger,
+				deps.pluginService,
+				downloadTokenResolver,
+				pluginType,
+			),
+		).Methods("GET")
+	}
 }
 
 func getAccessLogWriter(config *core.Config) (io.WriteCloser, error) {
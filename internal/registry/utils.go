@@ -2,8 +2,11 @@ package registry
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/plugins"
 	"go.uber.org/zap"
@@ -23,6 +26,24 @@ func handlePluginError(
 		return
 	}
 
+	if errors.Is(err, plugins.ErrVersionNotFound) {
+		httputils.HTTPError(
+			w,
+			http.StatusNotFound,
+			"Plugin version not found",
+		)
+		return
+	}
+
+	if errors.Is(err, plugins.ErrAssetNotFound) {
+		httputils.HTTPError(
+			w,
+			http.StatusNotFound,
+			"Plugin release asset not found",
+		)
+		return
+	}
+
 	if errors.Is(err, plugins.ErrUnauthorised) {
 		httputils.HTTPError(
 			w,
@@ -41,6 +62,22 @@ func handlePluginError(
 		return
 	}
 
+	var rateLimitErr *plugins.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		if rateLimitErr.RetryAfter > 0 {
+			w.Header().Set(
+				"Retry-After",
+				strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))),
+			)
+		}
+		httputils.HTTPError(
+			w,
+			http.StatusTooManyRequests,
+			"Rate limited by GitHub, try again later",
+		)
+		return
+	}
+
 	logger.Error(
 		"Error retrieving plugin version information",
 		zap.Error(err),
@@ -51,3 +88,40 @@ func handlePluginError(
 		"An unexpected error occurred",
 	)
 }
+
+func handleAuthError(
+	w http.ResponseWriter,
+	err error,
+	logger *zap.Logger,
+) {
+	if errors.Is(err, auth.ErrUnauthenticated) {
+		httputils.HTTPError(
+			w,
+			http.StatusUnauthorized,
+			"Unauthorized",
+		)
+		return
+	}
+
+	if errors.Is(err, auth.ErrForbidden) {
+		httputils.HTTPError(
+			w,
+			http.StatusForbidden,
+			"Forbidden",
+		)
+		return
+	}
+
+	// The registry can not see any repositories for an owner
+	// without an installation of the GitHub App.
+	if errors.Is(err, auth.ErrNoInstallation) {
+		httputils.HTTPError(
+			w,
+			http.StatusNotFound,
+			"Plugin repository not found",
+		)
+		return
+	}
+
+	handlePluginError(w, err, logger)
+}
@@ -0,0 +1,303 @@
+package repos
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+)
+
+// CacheTTLs holds the time-to-live values for the different
+// kinds of data that are cached by the caching repository service.
+type CacheTTLs struct {
+	// Repos is the time-to-live for repository listings,
+	// repository lookups and owner account lookups.
+	Repos time.Duration
+	// Releases is the time-to-live for release listings,
+	// releases fetched by tag and tag listings.
+	Releases time.Duration
+}
+
+const (
+	reposKeyPrefix              = "repos"
+	authenticatedReposKeyPrefix = "authenticated-repos"
+	userKeyPrefix               = "user"
+	repoKeyPrefix               = "repo"
+	releasesKeyPrefix           = "releases"
+	releaseKeyPrefix            = "release"
+	tagsKeyPrefix               = "tags"
+)
+
+type cachedResult[Value any] struct {
+	value Value
+	resp  *github.Response
+}
+
+type cachedService struct {
+	service Service
+	store   cache.Store
+	ttls    *CacheTTLs
+}
+
+// NewCachedService creates a new repository service that caches
+// successful responses from the provided service.
+// Entries are keyed by a hash of the caller's token so that results
+// are never shared between callers with different tokens.
+func NewCachedService(
+	service Service,
+	store cache.Store,
+	ttls *CacheTTLs,
+) Service {
+	return &cachedService{
+		service: service,
+		store:   store,
+		ttls:    ttls,
+	}
+}
+
+func (c *cachedService) ListByOrg(
+	ctx context.Context,
+	org string,
+	opts *github.RepositoryListByOrgOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := (*github.ListOptions)(nil)
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+	key := cache.Key(
+		reposKeyPrefix,
+		strings.ToLower(org),
+		cache.TokenHash(token),
+		listOptionsKey(listOpts),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Repos,
+		func() ([]*github.Repository, *github.Response, error) {
+			return c.service.ListByOrg(ctx, org, opts, token)
+		},
+	)
+}
+
+func (c *cachedService) ListByUser(
+	ctx context.Context,
+	user string,
+	opts *github.RepositoryListByUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := (*github.ListOptions)(nil)
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+	// User listings share the key prefix of organisation listings
+	// so they are invalidated in the same way for an owner,
+	// the "user" segment keeps the two kinds of listing apart.
+	key := cache.Key(
+		reposKeyPrefix,
+		strings.ToLower(user),
+		"user",
+		cache.TokenHash(token),
+		listOptionsKey(listOpts),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Repos,
+		func() ([]*github.Repository, *github.Response, error) {
+			return c.service.ListByUser(ctx, user, opts, token)
+		},
+	)
+}
+
+func (c *cachedService) ListByAuthenticatedUser(
+	ctx context.Context,
+	opts *github.RepositoryListByAuthenticatedUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := (*github.ListOptions)(nil)
+	affiliation := ""
+	if opts != nil {
+		listOpts = &opts.ListOptions
+		affiliation = opts.Affiliation
+	}
+	key := cache.Key(
+		authenticatedReposKeyPrefix,
+		cache.TokenHash(token),
+		affiliation,
+		listOptionsKey(listOpts),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Repos,
+		func() ([]*github.Repository, *github.Response, error) {
+			return c.service.ListByAuthenticatedUser(ctx, opts, token)
+		},
+	)
+}
+
+func (c *cachedService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	key := cache.Key(
+		userKeyPrefix,
+		strings.ToLower(user),
+		cache.TokenHash(token),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Repos,
+		func() (*github.User, *github.Response, error) {
+			return c.service.GetUser(ctx, user, token)
+		},
+	)
+}
+
+func (c *cachedService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	key := cache.Key(
+		repoKeyPrefix,
+		strings.ToLower(owner),
+		strings.ToLower(repo),
+		cache.TokenHash(token),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Repos,
+		func() (*github.Repository, *github.Response, error) {
+			return c.service.GetRepository(ctx, owner, repo, token)
+		},
+	)
+}
+
+func (c *cachedService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	key := cache.Key(
+		releasesKeyPrefix,
+		strings.ToLower(owner),
+		strings.ToLower(repo),
+		cache.TokenHash(token),
+		listOptionsKey(opts),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Releases,
+		func() ([]*github.RepositoryRelease, *github.Response, error) {
+			return c.service.ListReleases(ctx, owner, repo, opts, token)
+		},
+	)
+}
+
+func (c *cachedService) GetReleaseByTag(
+	ctx context.Context,
+	owner, repo, tag string,
+	token string,
+) (*github.RepositoryRelease, *github.Response, error) {
+	key := cache.Key(
+		releaseKeyPrefix,
+		strings.ToLower(owner),
+		strings.ToLower(repo),
+		tag,
+		cache.TokenHash(token),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Releases,
+		func() (*github.RepositoryRelease, *github.Response, error) {
+			return c.service.GetReleaseByTag(ctx, owner, repo, tag, token)
+		},
+	)
+}
+
+func (c *cachedService) ListTags(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryTag, *github.Response, error) {
+	key := cache.Key(
+		tagsKeyPrefix,
+		strings.ToLower(owner),
+		strings.ToLower(repo),
+		cache.TokenHash(token),
+		listOptionsKey(opts),
+	)
+	return getOrFetch(
+		c.store,
+		key,
+		c.ttls.Releases,
+		func() ([]*github.RepositoryTag, *github.Response, error) {
+			return c.service.ListTags(ctx, owner, repo, opts, token)
+		},
+	)
+}
+
+// InvalidateCachedRepository removes all the cached data for a repository
+// from the store used by a caching repository service, for all tokens.
+// Owner and repository names are case-insensitive.
+func InvalidateCachedRepository(store cache.Store, owner string, repo string) {
+	owner = strings.ToLower(owner)
+	repo = strings.ToLower(repo)
+	for _, prefix := range []string{repoKeyPrefix, releasesKeyPrefix, releaseKeyPrefix, tagsKeyPrefix} {
+		store.DeletePrefix(cache.Key(prefix, owner, repo, ""))
+	}
+}
+
+// InvalidateCachedOwnerRepos removes the cached repository listings for
+// an owner from the store used by a caching repository service, for all tokens.
+// Listings for authenticated users are not keyed by owner, so all of them
+// are removed as any of them could be for the owner.
+func InvalidateCachedOwnerRepos(store cache.Store, owner string) {
+	store.DeletePrefix(cache.Key(reposKeyPrefix, strings.ToLower(owner), ""))
+	store.DeletePrefix(cache.Key(authenticatedReposKeyPrefix, ""))
+}
+
+func getOrFetch[Value any](
+	store cache.Store,
+	key string,
+	ttl time.Duration,
+	fetch func() (Value, *github.Response, error),
+) (Value, *github.Response, error) {
+	if entry, ok := store.Get(key); ok {
+		if result, isResult := entry.(*cachedResult[Value]); isResult {
+			return result.value, result.resp, nil
+		}
+	}
+
+	value, resp, err := fetch()
+	if err != nil {
+		// Errors are never cached so that failures caused by
+		// transient issues or changes in permissions are not
+		// served to subsequent callers.
+		return value, resp, err
+	}
+
+	store.Set(key, &cachedResult[Value]{value: value, resp: resp}, ttl)
+	return value, resp, nil
+}
+
+func listOptionsKey(opts *github.ListOptions) string {
+	if opts == nil {
+		return "0:0"
+	}
+
+	return strconv.Itoa(opts.Page) + ":" + strconv.Itoa(opts.PerPage)
+}
@@ -0,0 +1,215 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/cache"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+type CachedServiceTestSuite struct {
+	suite.Suite
+	counter *callCountingService
+	service Service
+}
+
+func (s *CachedServiceTestSuite) SetupTest() {
+	s.counter = &callCountingService{
+		Service: testutils.NewStubRepoService(
+			[]*github.Repository{
+				{
+					Name: github.Ptr("bluelink-provider-example"),
+					Owner: &github.User{
+						Login: github.Ptr("newstack-cloud"),
+					},
+				},
+			},
+			map[string][]*github.RepositoryRelease{
+				"bluelink-provider-example": {
+					{
+						TagName: github.Ptr("v1.0.0"),
+					},
+				},
+			},
+		),
+		calls: map[string]int{},
+	}
+	s.service = NewCachedService(
+		s.counter,
+		cache.NewInMemoryStore(),
+		&CacheTTLs{
+			Repos:    time.Minute,
+			Releases: time.Minute,
+		},
+	)
+}
+
+func (s *CachedServiceTestSuite) Test_serves_repeat_calls_from_the_cache() {
+	for range 3 {
+		repos, _, err := s.service.ListByOrg(
+			context.Background(),
+			"newstack-cloud",
+			&github.RepositoryListByOrgOptions{},
+			"test-token",
+		)
+		s.Require().NoError(err)
+		s.Assert().Len(repos, 1)
+
+		releases, _, err := s.service.ListReleases(
+			context.Background(),
+			"newstack-cloud",
+			"bluelink-provider-example",
+			&github.ListOptions{},
+			"test-token",
+		)
+		s.Require().NoError(err)
+		s.Assert().Len(releases, 1)
+	}
+
+	s.Assert().Equal(1, s.counter.calls["ListByOrg"])
+	s.Assert().Equal(1, s.counter.calls["ListReleases"])
+}
+
+func (s *CachedServiceTestSuite) Test_does_not_share_entries_between_tokens() {
+	for _, token := range []string{"test-token-1", "test-token-2"} {
+		_, _, err := s.service.ListReleases(
+			context.Background(),
+			"newstack-cloud",
+			"bluelink-provider-example",
+			&github.ListOptions{},
+			token,
+		)
+		s.Require().NoError(err)
+	}
+
+	s.Assert().Equal(2, s.counter.calls["ListReleases"])
+}
+
+func (s *CachedServiceTestSuite) Test_does_not_cache_errors() {
+	for range 2 {
+		_, _, err := s.service.GetReleaseByTag(
+			context.Background(),
+			"newstack-cloud",
+			"bluelink-provider-example",
+			"v2.0.0",
+			"test-token",
+		)
+		s.Require().Error(err)
+	}
+
+	s.Assert().Equal(2, s.counter.calls["GetReleaseByTag"])
+}
+
+func (s *CachedServiceTestSuite) Test_invalidates_cached_repository_for_all_tokens() {
+	store := cache.NewInMemoryStore()
+	service := NewCachedService(
+		s.counter,
+		store,
+		&CacheTTLs{
+			Repos:    time.Minute,
+			Releases: time.Minute,
+		},
+	)
+
+	listReleases := func(token string) {
+		_, _, err := service.ListReleases(
+			context.Background(),
+			"newstack-cloud",
+			"bluelink-provider-example",
+			&github.ListOptions{},
+			token,
+		)
+		s.Require().NoError(err)
+	}
+
+	listReleases("test-token-1")
+	listReleases("test-token-2")
+	InvalidateCachedRepository(store, "Newstack-Cloud", "bluelink-provider-example")
+	listReleases("test-token-1")
+	listReleases("test-token-2")
+
+	s.Assert().Equal(4, s.counter.calls["ListReleases"])
+}
+
+func (s *CachedServiceTestSuite) Test_refetches_entries_evicted_from_bounded_store() {
+	// The clock moves forward each time it is read so that entries
+	// for earlier calls are the closest to expiring.
+	now := time.Now()
+	service := NewCachedService(
+		s.counter,
+		cache.NewInMemoryStore(
+			cache.WithInMemoryStoreClock(func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			}),
+			cache.WithInMemoryStoreMaxEntries(2),
+		),
+		&CacheTTLs{
+			Repos:    time.Minute,
+			Releases: time.Minute,
+		},
+	)
+
+	listReleases := func(token string) {
+		_, _, err := service.ListReleases(
+			context.Background(),
+			"newstack-cloud",
+			"bluelink-provider-example",
+			&github.ListOptions{},
+			token,
+		)
+		s.Require().NoError(err)
+	}
+
+	// The entry for the first token is evicted to make room
+	// for the entry for the third token.
+	listReleases("test-token-1")
+	listReleases("test-token-2")
+	listReleases("test-token-3")
+	listReleases("test-token-3")
+	listReleases("test-token-1")
+
+	s.Assert().Equal(4, s.counter.calls["ListReleases"])
+}
+
+type callCountingService struct {
+	Service
+	calls map[string]int
+}
+
+func (c *callCountingService) ListByOrg(
+	ctx context.Context,
+	org string,
+	opts *github.RepositoryListByOrgOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	c.calls["ListByOrg"] += 1
+	return c.Service.ListByOrg(ctx, org, opts, token)
+}
+
+func (c *callCountingService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	c.calls["ListReleases"] += 1
+	return c.Service.ListReleases(ctx, owner, repo, opts, token)
+}
+
+func (c *callCountingService) GetReleaseByTag(
+	ctx context.Context,
+	owner, repo, tag string,
+	token string,
+) (*github.RepositoryRelease, *github.Response, error) {
+	c.calls["GetReleaseByTag"] += 1
+	return c.Service.GetReleaseByTag(ctx, owner, repo, tag, token)
+}
+
+func TestCachedServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CachedServiceTestSuite))
+}
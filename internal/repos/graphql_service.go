@@ -0,0 +1,994 @@
+package repos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+)
+
+const (
+	// DefaultGitHubAPIBaseURL is the default base URL for the GitHub API,
+	// used for the GraphQL endpoint and to build REST API URLs
+	// for release assets.
+	DefaultGitHubAPIBaseURL = "https://api.github.com/"
+
+	// DefaultGraphQLMaxPages is the default maximum number of pages
+	// that will be fetched from the GraphQL API for a single listing.
+	DefaultGraphQLMaxPages = 50
+
+	// The maximum number of nodes that can be requested in
+	// a single page of a GraphQL connection.
+	graphQLMaxPageSize = 100
+)
+
+// Matches the REST API URL of a release asset,
+// capturing the database ID of the asset.
+var releaseAssetAPIURLPattern = regexp.MustCompile(`/releases/assets/(\d+)$`)
+
+const repositoryFieldsFragment = `
+fragment repositoryFields on Repository {
+	databaseId
+	name
+	nameWithOwner
+	url
+	isPrivate
+	isArchived
+	owner { login }
+}`
+
+// Up to 100 assets are fetched for each release, releases with more
+// assets than this are fetched from the REST API instead.
+const releaseFieldsFragment = `
+fragment releaseFields on Release {
+	databaseId
+	name
+	tagName
+	isDraft
+	isPrerelease
+	createdAt
+	publishedAt
+	url
+	tagCommit { oid }
+	releaseAssets(first: 100) {
+		nodes {
+			id
+			name
+			size
+			contentType
+			url
+			downloadUrl
+			createdAt
+			updatedAt
+		}
+		pageInfo { hasNextPage }
+	}
+}`
+
+const listOrgReposQuery = `
+query($owner: String!, $first: Int!, $after: String) {
+	organization(login: $owner) {
+		repositories(first: $first, after: $after) {
+			nodes { ...repositoryFields }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}` + repositoryFieldsFragment
+
+const listUserReposQuery = `
+query($owner: String!, $first: Int!, $after: String) {
+	user(login: $owner) {
+		repositories(first: $first, after: $after, ownerAffiliations: OWNER) {
+			nodes { ...repositoryFields }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}` + repositoryFieldsFragment
+
+const listViewerReposQuery = `
+query($first: Int!, $after: String) {
+	viewer {
+		repositories(first: $first, after: $after, ownerAffiliations: OWNER) {
+			nodes { ...repositoryFields }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}` + repositoryFieldsFragment
+
+const getOwnerQuery = `
+query($login: String!) {
+	repositoryOwner(login: $login) {
+		__typename
+		login
+		... on User { databaseId }
+		... on Organization { databaseId }
+	}
+}`
+
+const getViewerQuery = `
+query {
+	viewer {
+		__typename
+		login
+		databaseId
+	}
+}`
+
+const getRepositoryQuery = `
+query($owner: String!, $name: String!) {
+	repository(owner: $owner, name: $name) { ...repositoryFields }
+}` + repositoryFieldsFragment
+
+const listReleasesQuery = `
+query($owner: String!, $name: String!, $first: Int!, $after: String) {
+	repository(owner: $owner, name: $name) {
+		releases(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+			nodes { ...releaseFields }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}` + releaseFieldsFragment
+
+const getReleaseByTagQuery = `
+query($owner: String!, $name: String!, $tag: String!) {
+	repository(owner: $owner, name: $name) {
+		release(tagName: $tag) { ...releaseFields }
+	}
+}` + releaseFieldsFragment
+
+// The target of an annotated tag is a tag object,
+// which in turn targets the commit of the tag.
+const listTagsQuery = `
+query($owner: String!, $name: String!, $first: Int!, $after: String) {
+	repository(owner: $owner, name: $name) {
+		refs(refPrefix: "refs/tags/", first: $first, after: $after) {
+			nodes {
+				name
+				target {
+					oid
+					... on Tag { target { oid } }
+				}
+			}
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}`
+
+type graphQLService struct {
+	httpClient  *http.Client
+	baseURL     string
+	maxPages    int
+	restService Service
+}
+
+// GraphQLServiceOption is a function that configures
+// the GitHub GraphQL repository service.
+type GraphQLServiceOption func(*graphQLService)
+
+// WithGraphQLHTTPClient configures the HTTP client used
+// to make requests to the GitHub GraphQL API.
+func WithGraphQLHTTPClient(httpClient *http.Client) GraphQLServiceOption {
+	return func(g *graphQLService) {
+		g.httpClient = httpClient
+	}
+}
+
+// WithGraphQLBaseURL configures the base URL of the GitHub API,
+// the GraphQL endpoint is expected to be at "{baseURL}graphql".
+func WithGraphQLBaseURL(baseURL string) GraphQLServiceOption {
+	return func(g *graphQLService) {
+		g.baseURL = baseURL
+	}
+}
+
+// WithGraphQLMaxPages configures the maximum number of pages
+// that will be fetched from the GraphQL API for a single listing.
+func WithGraphQLMaxPages(maxPages int) GraphQLServiceOption {
+	return func(g *graphQLService) {
+		g.maxPages = maxPages
+	}
+}
+
+// WithGraphQLRESTService configures the REST API service used to fetch
+// a release when the IDs of its assets can not be determined from the
+// GraphQL response or it has more assets than are fetched with GraphQL.
+// When not set, listing or fetching such a release fails.
+func WithGraphQLRESTService(restService Service) GraphQLServiceOption {
+	return func(g *graphQLService) {
+		g.restService = restService
+	}
+}
+
+// NewGraphQLService creates a new instance of a service for interacting
+// with GitHub repositories that uses the GitHub GraphQL API.
+//
+// Releases are fetched along with their assets in a single query for
+// each page, and all pages of a listing are returned as the first page,
+// as GraphQL pagination uses cursors instead of page numbers.
+// Release assets are given the REST API URL for the asset so they can be
+// downloaded in the same way as assets fetched from the REST API.
+// The contents of release assets are not available from the GraphQL API,
+// so artifacts such as registry info files are still downloaded with a
+// REST API request for each release.
+func NewGraphQLService(opts ...GraphQLServiceOption) Service {
+	service := &graphQLService{
+		httpClient: http.DefaultClient,
+		baseURL:    DefaultGitHubAPIBaseURL,
+		maxPages:   DefaultGraphQLMaxPages,
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLRepository struct {
+	DatabaseID    int64  `json:"databaseId"`
+	Name          string `json:"name"`
+	NameWithOwner string `json:"nameWithOwner"`
+	URL           string `json:"url"`
+	IsPrivate     bool   `json:"isPrivate"`
+	IsArchived    bool   `json:"isArchived"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type graphQLRepositoryConnection struct {
+	Nodes    []*graphQLRepository `json:"nodes"`
+	PageInfo graphQLPageInfo      `json:"pageInfo"`
+}
+
+type graphQLOwner struct {
+	TypeName   string `json:"__typename"`
+	Login      string `json:"login"`
+	DatabaseID int64  `json:"databaseId"`
+}
+
+type graphQLRelease struct {
+	DatabaseID   int64      `json:"databaseId"`
+	Name         string     `json:"name"`
+	TagName      string     `json:"tagName"`
+	IsDraft      bool       `json:"isDraft"`
+	IsPrerelease bool       `json:"isPrerelease"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	PublishedAt  *time.Time `json:"publishedAt"`
+	URL          string     `json:"url"`
+	TagCommit    *struct {
+		OID string `json:"oid"`
+	} `json:"tagCommit"`
+	ReleaseAssets struct {
+		Nodes    []*graphQLReleaseAsset `json:"nodes"`
+		PageInfo graphQLPageInfo        `json:"pageInfo"`
+	} `json:"releaseAssets"`
+}
+
+type graphQLReleaseAsset struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Size        int       `json:"size"`
+	ContentType string    `json:"contentType"`
+	URL         string    `json:"url"`
+	DownloadURL string    `json:"downloadUrl"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type graphQLTagRef struct {
+	Name   string `json:"name"`
+	Target struct {
+		OID    string `json:"oid"`
+		Target *struct {
+			OID string `json:"oid"`
+		} `json:"target"`
+	} `json:"target"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (g *graphQLService) ListByOrg(
+	ctx context.Context,
+	org string,
+	opts *github.RepositoryListByOrgOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := &github.ListOptions{}
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+
+	return listAllPages(
+		g,
+		listOpts,
+		func(after *string) ([]*github.Repository, *graphQLPageInfo, *github.Response, error) {
+			var data struct {
+				Organization *struct {
+					Repositories struct {
+						Nodes    []*graphQLRepository `json:"nodes"`
+						PageInfo graphQLPageInfo      `json:"pageInfo"`
+					} `json:"repositories"`
+				} `json:"organization"`
+			}
+			resp, err := g.query(
+				ctx,
+				listOrgReposQuery,
+				map[string]any{
+					"owner": org,
+					"first": pageSize(listOpts),
+					"after": after,
+				},
+				token,
+				&data,
+			)
+			if err != nil {
+				return nil, nil, resp, err
+			}
+
+			if data.Organization == nil {
+				return nil, nil, notFoundResponse(resp), notFoundError(org)
+			}
+
+			repos := make([]*github.Repository, 0, len(data.Organization.Repositories.Nodes))
+			for _, repo := range data.Organization.Repositories.Nodes {
+				repos = append(repos, toGitHubRepository(repo))
+			}
+			return repos, &data.Organization.Repositories.PageInfo, resp, nil
+		},
+	)
+}
+
+func (g *graphQLService) ListByUser(
+	ctx context.Context,
+	user string,
+	opts *github.RepositoryListByUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := &github.ListOptions{}
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+
+	return listAllPages(
+		g,
+		listOpts,
+		func(after *string) ([]*github.Repository, *graphQLPageInfo, *github.Response, error) {
+			var data struct {
+				User *struct {
+					Repositories graphQLRepositoryConnection `json:"repositories"`
+				} `json:"user"`
+			}
+			resp, err := g.query(
+				ctx,
+				listUserReposQuery,
+				map[string]any{
+					"owner": user,
+					"first": pageSize(listOpts),
+					"after": after,
+				},
+				token,
+				&data,
+			)
+			if err != nil {
+				return nil, nil, resp, err
+			}
+
+			if data.User == nil {
+				return nil, nil, notFoundResponse(resp), notFoundError(user)
+			}
+
+			return toGitHubRepositories(data.User.Repositories.Nodes),
+				&data.User.Repositories.PageInfo,
+				resp,
+				nil
+		},
+	)
+}
+
+// ListByAuthenticatedUser lists the repositories owned by the authenticated
+// user, repositories that the user can access through collaboration or
+// organisation membership are not included.
+func (g *graphQLService) ListByAuthenticatedUser(
+	ctx context.Context,
+	opts *github.RepositoryListByAuthenticatedUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	listOpts := &github.ListOptions{}
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+
+	return listAllPages(
+		g,
+		listOpts,
+		func(after *string) ([]*github.Repository, *graphQLPageInfo, *github.Response, error) {
+			var data struct {
+				Viewer struct {
+					Repositories graphQLRepositoryConnection `json:"repositories"`
+				} `json:"viewer"`
+			}
+			resp, err := g.query(
+				ctx,
+				listViewerReposQuery,
+				map[string]any{
+					"first": pageSize(listOpts),
+					"after": after,
+				},
+				token,
+				&data,
+			)
+			if err != nil {
+				return nil, nil, resp, err
+			}
+
+			return toGitHubRepositories(data.Viewer.Repositories.Nodes),
+				&data.Viewer.Repositories.PageInfo,
+				resp,
+				nil
+		},
+	)
+}
+
+func (g *graphQLService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	if user == "" {
+		var data struct {
+			Viewer *graphQLOwner `json:"viewer"`
+		}
+		resp, err := g.query(ctx, getViewerQuery, map[string]any{}, token, &data)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		return toGitHubUser(data.Viewer), resp, nil
+	}
+
+	var data struct {
+		RepositoryOwner *graphQLOwner `json:"repositoryOwner"`
+	}
+	resp, err := g.query(
+		ctx,
+		getOwnerQuery,
+		map[string]any{
+			"login": user,
+		},
+		token,
+		&data,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if data.RepositoryOwner == nil {
+		return nil, notFoundResponse(resp), notFoundError(user)
+	}
+
+	return toGitHubUser(data.RepositoryOwner), resp, nil
+}
+
+func (g *graphQLService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	var data struct {
+		Repository *graphQLRepository `json:"repository"`
+	}
+	resp, err := g.query(
+		ctx,
+		getRepositoryQuery,
+		map[string]any{
+			"owner": owner,
+			"name":  repo,
+		},
+		token,
+		&data,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if data.Repository == nil {
+		return nil, notFoundResponse(resp), notFoundError(owner + "/" + repo)
+	}
+
+	return toGitHubRepository(data.Repository), resp, nil
+}
+
+func (g *graphQLService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	if opts == nil {
+		opts = &github.ListOptions{}
+	}
+
+	return listAllPages(
+		g,
+		opts,
+		func(after *string) ([]*github.RepositoryRelease, *graphQLPageInfo, *github.Response, error) {
+			var data struct {
+				Repository *struct {
+					Releases struct {
+						Nodes    []*graphQLRelease `json:"nodes"`
+						PageInfo graphQLPageInfo   `json:"pageInfo"`
+					} `json:"releases"`
+				} `json:"repository"`
+			}
+			resp, err := g.query(
+				ctx,
+				listReleasesQuery,
+				map[string]any{
+					"owner": owner,
+					"name":  repo,
+					"first": pageSize(opts),
+					"after": after,
+				},
+				token,
+				&data,
+			)
+			if err != nil {
+				return nil, nil, resp, err
+			}
+
+			if data.Repository == nil {
+				return nil, nil, notFoundResponse(resp), notFoundError(owner + "/" + repo)
+			}
+
+			releases := make([]*github.RepositoryRelease, 0, len(data.Repository.Releases.Nodes))
+			for _, release := range data.Repository.Releases.Nodes {
+				githubRelease, err := g.toGitHubRelease(ctx, owner, repo, release, token)
+				if err != nil {
+					return nil, nil, resp, err
+				}
+				releases = append(releases, githubRelease)
+			}
+			return releases, &data.Repository.Releases.PageInfo, resp, nil
+		},
+	)
+}
+
+func (g *graphQLService) GetReleaseByTag(
+	ctx context.Context,
+	owner, repo, tag string,
+	token string,
+) (*github.RepositoryRelease, *github.Response, error) {
+	var data struct {
+		Repository *struct {
+			Release *graphQLRelease `json:"release"`
+		} `json:"repository"`
+	}
+	resp, err := g.query(
+		ctx,
+		getReleaseByTagQuery,
+		map[string]any{
+			"owner": owner,
+			"name":  repo,
+			"tag":   tag,
+		},
+		token,
+		&data,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if data.Repository == nil || data.Repository.Release == nil {
+		return nil, notFoundResponse(resp), notFoundError(owner + "/" + repo + "@" + tag)
+	}
+
+	release, err := g.toGitHubRelease(ctx, owner, repo, data.Repository.Release, token)
+	return release, resp, err
+}
+
+func (g *graphQLService) ListTags(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryTag, *github.Response, error) {
+	if opts == nil {
+		opts = &github.ListOptions{}
+	}
+
+	return listAllPages(
+		g,
+		opts,
+		func(after *string) ([]*github.RepositoryTag, *graphQLPageInfo, *github.Response, error) {
+			var data struct {
+				Repository *struct {
+					Refs struct {
+						Nodes    []*graphQLTagRef `json:"nodes"`
+						PageInfo graphQLPageInfo  `json:"pageInfo"`
+					} `json:"refs"`
+				} `json:"repository"`
+			}
+			resp, err := g.query(
+				ctx,
+				listTagsQuery,
+				map[string]any{
+					"owner": owner,
+					"name":  repo,
+					"first": pageSize(opts),
+					"after": after,
+				},
+				token,
+				&data,
+			)
+			if err != nil {
+				return nil, nil, resp, err
+			}
+
+			if data.Repository == nil {
+				return nil, nil, notFoundResponse(resp), notFoundError(owner + "/" + repo)
+			}
+
+			tags := make([]*github.RepositoryTag, 0, len(data.Repository.Refs.Nodes))
+			for _, ref := range data.Repository.Refs.Nodes {
+				tags = append(tags, toGitHubTag(ref))
+			}
+			return tags, &data.Repository.Refs.PageInfo, resp, nil
+		},
+	)
+}
+
+// listAllPages fetches all the pages of a GraphQL connection up to the
+// maximum number of pages, returning them as the first page of a listing.
+// Requests for subsequent pages return an empty list so callers that
+// paginate with page numbers stop after the first page.
+func listAllPages[Item any](
+	g *graphQLService,
+	opts *github.ListOptions,
+	fetchPage func(after *string) ([]Item, *graphQLPageInfo, *github.Response, error),
+) ([]Item, *github.Response, error) {
+	if opts.Page > 1 {
+		return []Item{}, &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+	}
+
+	items := []Item{}
+	var after *string
+	var lastResp *github.Response
+	for page := 1; page <= g.maxPages; page += 1 {
+		pageItems, pageInfo, resp, err := fetchPage(after)
+		if err != nil {
+			return nil, resp, err
+		}
+		lastResp = resp
+		items = append(items, pageItems...)
+
+		if !pageInfo.HasNextPage {
+			break
+		}
+		after = &pageInfo.EndCursor
+	}
+
+	return items, lastResp, nil
+}
+
+func (g *graphQLService) query(
+	ctx context.Context,
+	query string,
+	variables map[string]any,
+	token string,
+	target any,
+) (*github.Response, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		g.baseURL+"graphql",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpResp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	resp := &github.Response{Response: httpResp}
+
+	// CheckResponse produces the same errors as the REST client for
+	// error responses, including rate limit errors.
+	if err := github.CheckResponse(httpResp); err != nil {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []*graphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return resp, err
+	}
+
+	if len(envelope.Errors) > 0 {
+		return handleGraphQLErrors(resp, envelope.Errors)
+	}
+
+	return resp, json.Unmarshal(envelope.Data, target)
+}
+
+// handleGraphQLErrors converts errors in a GraphQL response to the
+// responses and errors that the REST client would produce, so callers
+// can handle errors in the same way for both services.
+// The GraphQL API responds with a 200 status code for most errors.
+func handleGraphQLErrors(
+	resp *github.Response,
+	errs []*graphQLError,
+) (*github.Response, error) {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Message)
+	}
+	message := strings.Join(messages, "; ")
+
+	switch errs[0].Type {
+	case "NOT_FOUND":
+		return notFoundResponse(resp), fmt.Errorf("github graphql: %s", message)
+	case "FORBIDDEN":
+		return withStatusCode(resp, http.StatusForbidden), fmt.Errorf("github graphql: %s", message)
+	case "RATE_LIMITED":
+		return resp, &github.RateLimitError{
+			Rate:     rateFromHeaders(resp.Header),
+			Response: resp.Response,
+			Message:  message,
+		}
+	default:
+		return resp, fmt.Errorf("github graphql: %s", message)
+	}
+}
+
+func rateFromHeaders(header http.Header) github.Rate {
+	rate := github.Rate{}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		rate.Limit = limit
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		rate.Remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rate.Reset = github.Timestamp{Time: time.Unix(reset, 0)}
+	}
+	return rate
+}
+
+func notFoundResponse(resp *github.Response) *github.Response {
+	return withStatusCode(resp, http.StatusNotFound)
+}
+
+func withStatusCode(resp *github.Response, statusCode int) *github.Response {
+	httpResp := &http.Response{Header: http.Header{}}
+	if resp != nil && resp.Response != nil {
+		copied := *resp.Response
+		httpResp = &copied
+	}
+	httpResp.StatusCode = statusCode
+	httpResp.Status = fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+	httpResp.Body = http.NoBody
+	return &github.Response{Response: httpResp}
+}
+
+func notFoundError(resource string) error {
+	return fmt.Errorf("github graphql: could not resolve %q", resource)
+}
+
+func pageSize(opts *github.ListOptions) int {
+	if opts.PerPage <= 0 || opts.PerPage > graphQLMaxPageSize {
+		return graphQLMaxPageSize
+	}
+
+	return opts.PerPage
+}
+
+func toGitHubRepositories(repos []*graphQLRepository) []*github.Repository {
+	githubRepos := make([]*github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		githubRepos = append(githubRepos, toGitHubRepository(repo))
+	}
+	return githubRepos
+}
+
+// toGitHubUser converts a GraphQL repository owner to a user,
+// the GraphQL type names for owners match the account types
+// in the REST API.
+func toGitHubUser(owner *graphQLOwner) *github.User {
+	if owner == nil {
+		return &github.User{}
+	}
+
+	return &github.User{
+		ID:    github.Ptr(owner.DatabaseID),
+		Login: github.Ptr(owner.Login),
+		Type:  github.Ptr(owner.TypeName),
+	}
+}
+
+func toGitHubRepository(repo *graphQLRepository) *github.Repository {
+	return &github.Repository{
+		ID:       github.Ptr(repo.DatabaseID),
+		Name:     github.Ptr(repo.Name),
+		FullName: github.Ptr(repo.NameWithOwner),
+		HTMLURL:  github.Ptr(repo.URL),
+		Private:  github.Ptr(repo.IsPrivate),
+		Archived: github.Ptr(repo.IsArchived),
+		Owner: &github.User{
+			Login: github.Ptr(repo.Owner.Login),
+		},
+	}
+}
+
+func toGitHubTag(ref *graphQLTagRef) *github.RepositoryTag {
+	commitSHA := ref.Target.OID
+	if ref.Target.Target != nil {
+		commitSHA = ref.Target.Target.OID
+	}
+
+	return &github.RepositoryTag{
+		Name: github.Ptr(ref.Name),
+		Commit: &github.Commit{
+			SHA: github.Ptr(commitSHA),
+		},
+	}
+}
+
+func (g *graphQLService) toGitHubRelease(
+	ctx context.Context,
+	owner, repo string,
+	release *graphQLRelease,
+	token string,
+) (*github.RepositoryRelease, error) {
+	githubRelease := &github.RepositoryRelease{
+		ID:         github.Ptr(release.DatabaseID),
+		Name:       github.Ptr(release.Name),
+		TagName:    github.Ptr(release.TagName),
+		Draft:      github.Ptr(release.IsDraft),
+		Prerelease: github.Ptr(release.IsPrerelease),
+		CreatedAt:  &github.Timestamp{Time: release.CreatedAt},
+		HTMLURL:    github.Ptr(release.URL),
+	}
+	if release.PublishedAt != nil {
+		githubRelease.PublishedAt = &github.Timestamp{Time: *release.PublishedAt}
+	}
+	if release.TagCommit != nil {
+		githubRelease.TargetCommitish = github.Ptr(release.TagCommit.OID)
+	}
+
+	assets, hasAllAssets := g.toGitHubReleaseAssets(owner, repo, release)
+	if !hasAllAssets {
+		restAssets, err := g.restReleaseAssets(ctx, owner, repo, release, token)
+		if err != nil {
+			return nil, err
+		}
+		assets = restAssets
+	}
+	githubRelease.Assets = assets
+
+	return githubRelease, nil
+}
+
+// toGitHubReleaseAssets converts the assets of a GraphQL release,
+// the second return value is false when the release has more assets
+// than were fetched or the ID of an asset can not be determined.
+func (g *graphQLService) toGitHubReleaseAssets(
+	owner, repo string,
+	release *graphQLRelease,
+) ([]*github.ReleaseAsset, bool) {
+	if release.ReleaseAssets.PageInfo.HasNextPage {
+		return nil, false
+	}
+
+	assets := make([]*github.ReleaseAsset, 0, len(release.ReleaseAssets.Nodes))
+	for _, asset := range release.ReleaseAssets.Nodes {
+		assetID, hasAssetID := releaseAssetID(asset)
+		if !hasAssetID {
+			return nil, false
+		}
+
+		assets = append(assets, &github.ReleaseAsset{
+			ID:     github.Ptr(assetID),
+			NodeID: github.Ptr(asset.ID),
+			Name:   github.Ptr(asset.Name),
+			Size:   github.Ptr(asset.Size),
+			URL: github.Ptr(fmt.Sprintf(
+				"%srepos/%s/%s/releases/assets/%d",
+				g.baseURL,
+				owner,
+				repo,
+				assetID,
+			)),
+			BrowserDownloadURL: github.Ptr(asset.DownloadURL),
+			ContentType:        github.Ptr(asset.ContentType),
+			CreatedAt:          &github.Timestamp{Time: asset.CreatedAt},
+			UpdatedAt:          &github.Timestamp{Time: asset.UpdatedAt},
+		})
+	}
+
+	return assets, true
+}
+
+// releaseAssetID determines the database ID of a release asset, the GraphQL
+// API does not expose the database ID of release assets but it is needed to
+// build the REST API URL used to download an asset.
+// The ID is only known when the URL of the asset is a REST API URL,
+// node IDs are opaque so the ID is never derived from them.
+func releaseAssetID(asset *graphQLReleaseAsset) (int64, bool) {
+	matches := releaseAssetAPIURLPattern.FindStringSubmatch(asset.URL)
+	if matches == nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(matches[1], 10, 64)
+	return id, err == nil
+}
+
+// restReleaseAssets fetches the assets of a release from the REST API,
+// this is used when the assets of a release can not be fully determined
+// from the GraphQL response.
+func (g *graphQLService) restReleaseAssets(
+	ctx context.Context,
+	owner, repo string,
+	release *graphQLRelease,
+	token string,
+) ([]*github.ReleaseAsset, error) {
+	if g.restService == nil {
+		return nil, fmt.Errorf(
+			"no REST API service configured to fetch the assets of release %q",
+			release.TagName,
+		)
+	}
+
+	restRelease, _, err := g.restService.GetReleaseByTag(
+		ctx,
+		owner,
+		repo,
+		release.TagName,
+		token,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return restRelease.Assets, nil
+}
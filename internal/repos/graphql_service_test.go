@@ -0,0 +1,406 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type GraphQLServiceTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	service     Service
+	restService *stubRESTReleaseService
+	requests    []*graphQLTestRequest
+}
+
+type graphQLTestRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+func (s *GraphQLServiceTestSuite) SetupTest() {
+	s.requests = []*graphQLTestRequest{}
+	s.restService = &stubRESTReleaseService{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handleGraphQL))
+	s.service = NewGraphQLService(
+		WithGraphQLHTTPClient(s.server.Client()),
+		WithGraphQLBaseURL(s.server.URL+"/"),
+		WithGraphQLRESTService(s.restService),
+	)
+}
+
+func (s *GraphQLServiceTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *GraphQLServiceTestSuite) Test_lists_all_pages_of_releases_with_assets() {
+	releases, _, err := s.service.ListReleases(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		&github.ListOptions{PerPage: 1},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(releases, 2)
+	s.Assert().Len(s.requests, 2)
+	s.Assert().Equal("cursor-1", s.requests[1].Variables["after"])
+
+	release := releases[0]
+	s.Assert().Equal("v1.1.0", release.GetTagName())
+	s.Assert().True(release.GetPrerelease())
+	s.Assert().False(release.GetDraft())
+	s.Assert().Equal("3f4e5d", release.GetTargetCommitish())
+	s.Require().Len(release.Assets, 1)
+	s.Assert().Equal(int64(234567890), release.Assets[0].GetID())
+	s.Assert().Equal("bluelink-provider-example_1.1.0_registry_info.json", release.Assets[0].GetName())
+	s.Assert().Equal(
+		s.server.URL+"/repos/newstack-cloud/bluelink-provider-example/releases/assets/234567890",
+		release.Assets[0].GetURL(),
+	)
+	s.Assert().Equal(512, release.Assets[0].GetSize())
+
+	s.Assert().Equal("v1.0.0", releases[1].GetTagName())
+	s.Assert().Equal(int64(123456789), releases[1].Assets[0].GetID())
+}
+
+func (s *GraphQLServiceTestSuite) Test_takes_asset_id_from_rest_api_url_of_asset() {
+	release, _, err := s.service.GetReleaseByTag(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-asset-urls",
+		"v1.0.0",
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(release.Assets, 1)
+	s.Assert().Equal(int64(345678901), release.Assets[0].GetID())
+	s.Assert().Equal(
+		s.server.URL+"/repos/newstack-cloud/bluelink-provider-asset-urls/releases/assets/345678901",
+		release.Assets[0].GetURL(),
+	)
+	s.Assert().Empty(s.restService.requestedTags)
+}
+
+func (s *GraphQLServiceTestSuite) Test_falls_back_to_rest_api_for_release_with_assets_without_api_urls() {
+	releases, _, err := s.service.ListReleases(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-opaque-ids",
+		&github.ListOptions{},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(releases, 1)
+	s.Assert().Equal("v1.0.0", releases[0].GetTagName())
+	s.Require().Len(releases[0].Assets, 1)
+	s.Assert().Equal(int64(456789012), releases[0].Assets[0].GetID())
+	s.Assert().Equal(
+		"https://api.github.com/repos/newstack-cloud/bluelink-provider-opaque-ids/releases/assets/456789012",
+		releases[0].Assets[0].GetURL(),
+	)
+	s.Assert().Equal([]string{"v1.0.0"}, s.restService.requestedTags)
+}
+
+func (s *GraphQLServiceTestSuite) Test_falls_back_to_rest_api_for_release_with_more_assets_than_fetched() {
+	release, _, err := s.service.GetReleaseByTag(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-many-assets",
+		"v1.0.0",
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(release.Assets, 1)
+	s.Assert().Equal(int64(456789012), release.Assets[0].GetID())
+	s.Assert().Equal([]string{"v1.0.0"}, s.restService.requestedTags)
+}
+
+func (s *GraphQLServiceTestSuite) Test_returns_empty_list_for_subsequent_pages() {
+	releases, resp, err := s.service.ListReleases(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		&github.ListOptions{Page: 2},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Empty(releases)
+	s.Assert().Equal(0, resp.NextPage)
+	s.Assert().Empty(s.requests)
+}
+
+func (s *GraphQLServiceTestSuite) Test_gets_repository() {
+	repo, _, err := s.service.GetRepository(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal("bluelink-provider-example", repo.GetName())
+	s.Assert().Equal("newstack-cloud", repo.GetOwner().GetLogin())
+}
+
+func (s *GraphQLServiceTestSuite) Test_returns_404_response_for_missing_repository() {
+	_, resp, err := s.service.GetRepository(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-missing",
+		"test-token",
+	)
+	s.Require().Error(err)
+	s.Require().NotNil(resp)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *GraphQLServiceTestSuite) Test_gets_user_owner() {
+	user, _, err := s.service.GetUser(
+		context.Background(),
+		"jane-doe",
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Equal("jane-doe", user.GetLogin())
+	s.Assert().Equal("User", user.GetType())
+}
+
+func (s *GraphQLServiceTestSuite) Test_returns_404_response_for_missing_owner() {
+	_, resp, err := s.service.GetUser(
+		context.Background(),
+		"missing-owner",
+		"test-token",
+	)
+	s.Require().Error(err)
+	s.Require().NotNil(resp)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *GraphQLServiceTestSuite) Test_lists_repositories_owned_by_user() {
+	repos, _, err := s.service.ListByUser(
+		context.Background(),
+		"jane-doe",
+		&github.RepositoryListByUserOptions{},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(repos, 1)
+	s.Assert().Equal("bluelink-provider-personal", repos[0].GetName())
+	s.Assert().Equal("jane-doe", repos[0].GetOwner().GetLogin())
+}
+
+func (s *GraphQLServiceTestSuite) Test_lists_tags_with_commits_of_annotated_tags() {
+	tags, _, err := s.service.ListTags(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		&github.ListOptions{},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(tags, 2)
+	s.Assert().Equal("v1.0.0", tags[0].GetName())
+	s.Assert().Equal("9c4b1d2e3f405162738495a6b7c8d9e0f1a2b3c4", tags[0].GetCommit().GetSHA())
+	s.Assert().Equal("v1.1.0", tags[1].GetName())
+	s.Assert().Equal("5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394", tags[1].GetCommit().GetSHA())
+}
+
+func (s *GraphQLServiceTestSuite) Test_returns_rate_limit_error_for_rate_limited_query() {
+	_, resp, err := s.service.GetReleaseByTag(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-rate-limited",
+		"v1.0.0",
+		"test-token",
+	)
+	_, isRateLimited := RateLimitRetryAfter(resp.Response, err)
+	s.Assert().True(isRateLimited)
+}
+
+func (s *GraphQLServiceTestSuite) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	s.Require().Equal(http.MethodPost, r.Method)
+	s.Require().Equal("/graphql", r.URL.Path)
+	s.Require().Equal("Bearer test-token", r.Header.Get("Authorization"))
+
+	req := &graphQLTestRequest{}
+	s.Require().NoError(json.NewDecoder(r.Body).Decode(req))
+	s.requests = append(s.requests, req)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case req.Variables["name"] == "bluelink-provider-rate-limited":
+		w.Write([]byte(`{
+			"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]
+		}`))
+	case req.Variables["name"] == "bluelink-provider-missing":
+		w.Write([]byte(`{
+			"data": {"repository": null},
+			"errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a Repository"}]
+		}`))
+	case req.Variables["name"] == "bluelink-provider-asset-urls":
+		w.Write([]byte(`{"data": {"repository": {"release": ` + testGraphQLReleaseWithAsset(
+			"1.0.0",
+			false,
+			map[string]any{
+				"id":  "RA_unsupported-format",
+				"url": "https://api.github.com/repos/newstack-cloud/bluelink-provider-asset-urls/releases/assets/345678901",
+			},
+		) + `}}}`))
+	case req.Variables["name"] == "bluelink-provider-many-assets":
+		w.Write([]byte(`{"data": {"repository": {"release": ` +
+			testGraphQLReleaseWithMoreAssets("1.0.0") + `}}}`))
+	case req.Variables["name"] == "bluelink-provider-opaque-ids":
+		w.Write([]byte(`{"data": {"repository": {"releases": {
+			"nodes": [` + testGraphQLReleaseWithAsset(
+			"1.0.0",
+			false,
+			map[string]any{
+				// The database ID is never derived from the node ID
+				// as node IDs are opaque.
+				"id":  "RA_kwDOOt5osc4N-zjS",
+				"url": "https://github.com/newstack-cloud/bluelink-provider-opaque-ids/releases/download/v1.0.0/registry_info.json",
+			},
+		) + `],
+			"pageInfo": {"hasNextPage": false, "endCursor": "cursor-1"}
+		}}}}`))
+	case req.Variables["login"] == "missing-owner":
+		w.Write([]byte(`{"data": {"repositoryOwner": null}}`))
+	case strings.Contains(req.Query, "repositoryOwner("):
+		w.Write([]byte(`{"data": {"repositoryOwner": {
+			"__typename": "User",
+			"login": "jane-doe",
+			"databaseId": 2
+		}}}`))
+	case strings.Contains(req.Query, "user("):
+		w.Write([]byte(`{"data": {"user": {"repositories": {
+			"nodes": [{
+				"databaseId": 3,
+				"name": "bluelink-provider-personal",
+				"nameWithOwner": "jane-doe/bluelink-provider-personal",
+				"owner": {"login": "jane-doe"}
+			}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "cursor-1"}
+		}}}}`))
+	case strings.Contains(req.Query, "refs("):
+		w.Write([]byte(`{"data": {"repository": {"refs": {
+			"nodes": [
+				{"name": "v1.0.0", "target": {"oid": "9c4b1d2e3f405162738495a6b7c8d9e0f1a2b3c4"}},
+				{"name": "v1.1.0", "target": {
+					"oid": "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567",
+					"target": {"oid": "5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394"}
+				}}
+			],
+			"pageInfo": {"hasNextPage": false, "endCursor": "cursor-1"}
+		}}}}`))
+	case strings.Contains(req.Query, "releases(") && req.Variables["after"] == nil:
+		w.Write([]byte(`{"data": {"repository": {"releases": {
+			"nodes": [` + testGraphQLRelease("1.1.0", true, 234567890) + `],
+			"pageInfo": {"hasNextPage": true, "endCursor": "cursor-1"}
+		}}}}`))
+	case strings.Contains(req.Query, "releases("):
+		w.Write([]byte(`{"data": {"repository": {"releases": {
+			"nodes": [` + testGraphQLRelease("1.0.0", false, 123456789) + `],
+			"pageInfo": {"hasNextPage": false, "endCursor": "cursor-2"}
+		}}}}`))
+	default:
+		w.Write([]byte(`{"data": {"repository": {
+			"databaseId": 1,
+			"name": "bluelink-provider-example",
+			"nameWithOwner": "newstack-cloud/bluelink-provider-example",
+			"owner": {"login": "newstack-cloud"}
+		}}}`))
+	}
+}
+
+func testGraphQLRelease(version string, prerelease bool, assetID int64) string {
+	return testGraphQLReleaseWithAsset(
+		version,
+		prerelease,
+		map[string]any{
+			"id": "RA_kwDOOt5osc4N-zjS",
+			"url": fmt.Sprintf(
+				"https://api.github.com/repos/newstack-cloud/bluelink-provider-example/releases/assets/%d",
+				assetID,
+			),
+		},
+	)
+}
+
+func testGraphQLReleaseWithAsset(version string, prerelease bool, assetFields map[string]any) string {
+	asset := map[string]any{
+		"name":        "bluelink-provider-example_" + version + "_registry_info.json",
+		"size":        512,
+		"contentType": "application/json",
+		"createdAt":   "2025-01-01T00:00:00Z",
+		"updatedAt":   "2025-01-01T00:00:00Z",
+	}
+	maps.Copy(asset, assetFields)
+
+	release, _ := json.Marshal(map[string]any{
+		"databaseId":   1,
+		"tagName":      "v" + version,
+		"isDraft":      false,
+		"isPrerelease": prerelease,
+		"createdAt":    "2025-01-01T00:00:00Z",
+		"publishedAt":  "2025-01-01T00:00:00Z",
+		"tagCommit":    map[string]any{"oid": "3f4e5d"},
+		"releaseAssets": map[string]any{
+			"nodes": []map[string]any{asset},
+		},
+	})
+	return string(release)
+}
+
+// testGraphQLReleaseWithMoreAssets produces a release with
+// more assets than were fetched in the first page of assets.
+func testGraphQLReleaseWithMoreAssets(version string) string {
+	release := map[string]any{}
+	_ = json.Unmarshal([]byte(testGraphQLRelease(version, false, 123456789)), &release)
+	release["releaseAssets"].(map[string]any)["pageInfo"] = map[string]any{
+		"hasNextPage": true,
+		"endCursor":   "cursor-1",
+	}
+
+	releaseJSON, _ := json.Marshal(release)
+	return string(releaseJSON)
+}
+
+// stubRESTReleaseService provides releases from the REST API
+// for releases with assets that do not have REST API URLs.
+type stubRESTReleaseService struct {
+	Service
+	requestedTags []string
+}
+
+func (s *stubRESTReleaseService) GetReleaseByTag(
+	ctx context.Context,
+	owner, repo, tag string,
+	token string,
+) (*github.RepositoryRelease, *github.Response, error) {
+	s.requestedTags = append(s.requestedTags, tag)
+	return &github.RepositoryRelease{
+		TagName: github.Ptr(tag),
+		Assets: []*github.ReleaseAsset{
+			{
+				ID:   github.Ptr(int64(456789012)),
+				Name: github.Ptr("registry_info.json"),
+				URL: github.Ptr(
+					"https://api.github.com/repos/" + owner + "/" + repo + "/releases/assets/456789012",
+				),
+			},
+		},
+	}, &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+}
+
+func TestGraphQLServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(GraphQLServiceTestSuite))
+}
@@ -0,0 +1,252 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+)
+
+// RateLimitRetryAfter determines whether a response or error from the
+// GitHub API was caused by a primary or secondary rate limit.
+// When the request was rate limited, the amount of time to wait before
+// retrying is returned, this will be zero if GitHub did not indicate
+// when the caller can retry.
+func RateLimitRetryAfter(resp *http.Response, err error) (time.Duration, bool) {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return untilReset(rateLimitErr.Rate.Reset.Time), true
+	}
+
+	var abuseRateLimitErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseRateLimitErr) {
+		if abuseRateLimitErr.RetryAfter != nil {
+			return *abuseRateLimitErr.RetryAfter, true
+		}
+		return 0, true
+	}
+
+	if resp == nil {
+		return 0, false
+	}
+
+	isRateLimitStatus := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden &&
+			(resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+				resp.Header.Get("Retry-After") != ""))
+	if !isRateLimitStatus {
+		return 0, false
+	}
+
+	return retryAfterFromHeaders(resp.Header), true
+}
+
+func retryAfterFromHeaders(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return untilReset(time.Unix(reset, 0))
+	}
+
+	return 0
+}
+
+func untilReset(reset time.Time) time.Duration {
+	return max(time.Until(reset), 0)
+}
+
+// RateLimitRetryConfig holds the configuration for automatically
+// retrying requests that were rate limited by GitHub.
+type RateLimitRetryConfig struct {
+	// MaxRetries is the maximum number of times to retry
+	// a rate limited request.
+	MaxRetries int
+	// MaxWait is the longest amount of time to wait before
+	// retrying a rate limited request.
+	// Requests are not retried when GitHub indicates that the caller must
+	// wait longer than this.
+	MaxWait time.Duration
+	// BaseBackoff is the amount of time to wait before the first retry
+	// when GitHub does not indicate when the caller can retry,
+	// this is doubled for each subsequent retry.
+	BaseBackoff time.Duration
+}
+
+type rateLimitRetryingService struct {
+	service Service
+	config  *RateLimitRetryConfig
+}
+
+// NewRateLimitRetryingService creates a repository service that retries
+// requests to the provided service that were rate limited by GitHub,
+// as long as the wait before the retry is short enough.
+func NewRateLimitRetryingService(
+	service Service,
+	config *RateLimitRetryConfig,
+) Service {
+	return &rateLimitRetryingService{
+		service: service,
+		config:  config,
+	}
+}
+
+func (r *rateLimitRetryingService) ListByOrg(
+	ctx context.Context,
+	org string,
+	opts *github.RepositoryListByOrgOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() ([]*github.Repository, *github.Response, error) {
+			return r.service.ListByOrg(ctx, org, opts, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) ListByUser(
+	ctx context.Context,
+	user string,
+	opts *github.RepositoryListByUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() ([]*github.Repository, *github.Response, error) {
+			return r.service.ListByUser(ctx, user, opts, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) ListByAuthenticatedUser(
+	ctx context.Context,
+	opts *github.RepositoryListByAuthenticatedUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() ([]*github.Repository, *github.Response, error) {
+			return r.service.ListByAuthenticatedUser(ctx, opts, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() (*github.User, *github.Response, error) {
+			return r.service.GetUser(ctx, user, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() (*github.Repository, *github.Response, error) {
+			return r.service.GetRepository(ctx, owner, repo, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() ([]*github.RepositoryRelease, *github.Response, error) {
+			return r.service.ListReleases(ctx, owner, repo, opts, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) GetReleaseByTag(
+	ctx context.Context,
+	owner, repo, tag string,
+	token string,
+) (*github.RepositoryRelease, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() (*github.RepositoryRelease, *github.Response, error) {
+			return r.service.GetReleaseByTag(ctx, owner, repo, tag, token)
+		},
+	)
+}
+
+func (r *rateLimitRetryingService) ListTags(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryTag, *github.Response, error) {
+	return retryRateLimited(
+		ctx,
+		r.config,
+		func() ([]*github.RepositoryTag, *github.Response, error) {
+			return r.service.ListTags(ctx, owner, repo, opts, token)
+		},
+	)
+}
+
+func retryRateLimited[Value any](
+	ctx context.Context,
+	config *RateLimitRetryConfig,
+	call func() (Value, *github.Response, error),
+) (Value, *github.Response, error) {
+	value, resp, err := call()
+	for attempt := 0; err != nil && attempt < config.MaxRetries; attempt += 1 {
+		retryAfter, isRateLimited := RateLimitRetryAfter(toHTTPResponse(resp), err)
+		if !isRateLimited {
+			return value, resp, err
+		}
+
+		wait := retryAfter
+		if wait == 0 {
+			wait = config.BaseBackoff << attempt
+		}
+		if wait > config.MaxWait {
+			return value, resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return value, resp, err
+		case <-time.After(wait):
+		}
+
+		value, resp, err = call()
+	}
+
+	return value, resp, err
+}
+
+func toHTTPResponse(resp *github.Response) *http.Response {
+	if resp == nil {
+		return nil
+	}
+
+	return resp.Response
+}
@@ -0,0 +1,167 @@
+package repos
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitsTestSuite struct {
+	suite.Suite
+}
+
+func (s *RateLimitsTestSuite) Test_detects_primary_rate_limit_error() {
+	retryAfter, isRateLimited := RateLimitRetryAfter(
+		nil,
+		&github.RateLimitError{
+			Rate: github.Rate{
+				Reset: github.Timestamp{Time: time.Now().Add(time.Minute)},
+			},
+		},
+	)
+	s.Assert().True(isRateLimited)
+	s.Assert().InDelta(time.Minute, retryAfter, float64(5*time.Second))
+}
+
+func (s *RateLimitsTestSuite) Test_detects_secondary_rate_limit_error() {
+	retryAfter, isRateLimited := RateLimitRetryAfter(
+		nil,
+		&github.AbuseRateLimitError{
+			RetryAfter: github.Ptr(30 * time.Second),
+		},
+	)
+	s.Assert().True(isRateLimited)
+	s.Assert().Equal(30*time.Second, retryAfter)
+}
+
+func (s *RateLimitsTestSuite) Test_detects_rate_limit_from_retry_after_header() {
+	retryAfter, isRateLimited := RateLimitRetryAfter(
+		rateLimitResponse(http.StatusTooManyRequests, "Retry-After", "12"),
+		nil,
+	)
+	s.Assert().True(isRateLimited)
+	s.Assert().Equal(12*time.Second, retryAfter)
+}
+
+func (s *RateLimitsTestSuite) Test_detects_rate_limit_from_remaining_header() {
+	resp := rateLimitResponse(http.StatusForbidden, "X-RateLimit-Remaining", "0")
+	resp.Header.Set(
+		"X-RateLimit-Reset",
+		strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10),
+	)
+
+	retryAfter, isRateLimited := RateLimitRetryAfter(resp, nil)
+	s.Assert().True(isRateLimited)
+	s.Assert().InDelta(time.Minute, retryAfter, float64(5*time.Second))
+}
+
+func (s *RateLimitsTestSuite) Test_does_not_treat_other_forbidden_responses_as_rate_limits() {
+	_, isRateLimited := RateLimitRetryAfter(
+		rateLimitResponse(http.StatusForbidden, "X-RateLimit-Remaining", "4999"),
+		nil,
+	)
+	s.Assert().False(isRateLimited)
+}
+
+func (s *RateLimitsTestSuite) Test_retries_rate_limited_requests_with_short_waits() {
+	service := &rateLimitedService{
+		Service:     testutils.NewStubRepoService(nil, nil),
+		failures:    2,
+		retryAfter:  time.Millisecond,
+		releaseTags: []string{"v1.0.0"},
+	}
+	retrying := NewRateLimitRetryingService(
+		service,
+		&RateLimitRetryConfig{
+			MaxRetries:  2,
+			MaxWait:     time.Second,
+			BaseBackoff: time.Millisecond,
+		},
+	)
+
+	releases, _, err := retrying.ListReleases(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		&github.ListOptions{},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Assert().Len(releases, 1)
+	s.Assert().Equal(3, service.calls)
+}
+
+func (s *RateLimitsTestSuite) Test_does_not_retry_when_wait_exceeds_maximum() {
+	service := &rateLimitedService{
+		Service:    testutils.NewStubRepoService(nil, nil),
+		failures:   1,
+		retryAfter: time.Minute,
+	}
+	retrying := NewRateLimitRetryingService(
+		service,
+		&RateLimitRetryConfig{
+			MaxRetries:  2,
+			MaxWait:     time.Second,
+			BaseBackoff: time.Millisecond,
+		},
+	)
+
+	_, _, err := retrying.ListReleases(
+		context.Background(),
+		"newstack-cloud",
+		"bluelink-provider-example",
+		&github.ListOptions{},
+		"test-token",
+	)
+	s.Require().Error(err)
+	s.Assert().Equal(1, service.calls)
+}
+
+type rateLimitedService struct {
+	Service
+	failures    int
+	retryAfter  time.Duration
+	releaseTags []string
+	calls       int
+}
+
+func (r *rateLimitedService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	r.calls += 1
+	if r.calls <= r.failures {
+		return nil, nil, &github.AbuseRateLimitError{
+			RetryAfter: github.Ptr(r.retryAfter),
+		}
+	}
+
+	releases := []*github.RepositoryRelease{}
+	for _, tag := range r.releaseTags {
+		releases = append(releases, &github.RepositoryRelease{
+			TagName: github.Ptr(tag),
+		})
+	}
+	return releases, &github.Response{}, nil
+}
+
+func rateLimitResponse(statusCode int, header string, value string) *http.Response {
+	resp := &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+	}
+	resp.Header.Set(header, value)
+	return resp
+}
+
+func TestRateLimitsTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitsTestSuite))
+}
@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/google/go-github/v70/github"
 )
@@ -22,6 +23,58 @@ type Service interface {
 		token string,
 	) ([]*github.Repository, *github.Response, error)
 
+	// ListByUser lists the repositories owned by a user,
+	// only public repositories are listed for users other
+	// than the authenticated user.
+	//
+	// GitHub API docs: https://docs.github.com/rest/repos/repos#list-repositories-for-a-user
+	//
+	//meta:operation GET /users/{username}/repos
+	ListByUser(
+		ctx context.Context,
+		user string,
+		opts *github.RepositoryListByUserOptions,
+		token string,
+	) ([]*github.Repository, *github.Response, error)
+
+	// ListByAuthenticatedUser lists the repositories that the
+	// authenticated user has access to, including private repositories.
+	//
+	// GitHub API docs: https://docs.github.com/rest/repos/repos#list-repositories-for-the-authenticated-user
+	//
+	//meta:operation GET /user/repos
+	ListByAuthenticatedUser(
+		ctx context.Context,
+		opts *github.RepositoryListByAuthenticatedUserOptions,
+		token string,
+	) ([]*github.Repository, *github.Response, error)
+
+	// GetUser fetches a user or organisation account,
+	// the type of the account is "User" or "Organization".
+	// Passing an empty string will fetch the authenticated user.
+	//
+	// GitHub API docs: https://docs.github.com/rest/users/users#get-a-user
+	// GitHub API docs: https://docs.github.com/rest/users/users#get-the-authenticated-user
+	//
+	//meta:operation GET /user
+	//meta:operation GET /users/{username}
+	GetUser(
+		ctx context.Context,
+		user string,
+		token string,
+	) (*github.User, *github.Response, error)
+
+	// GetRepository fetches a repository.
+	//
+	// GitHub API docs: https://docs.github.com/rest/repos/repos#get-a-repository
+	//
+	//meta:operation GET /repos/{owner}/{repo}
+	GetRepository(
+		ctx context.Context,
+		owner, repo string,
+		token string,
+	) (*github.Repository, *github.Response, error)
+
 	// ListReleases lists the releases for a repository.
 	//
 	// GitHub API docs: https://docs.github.com/rest/releases/releases#list-releases
@@ -44,14 +97,51 @@ type Service interface {
 		owner, repo, tag string,
 		token string,
 	) (*github.RepositoryRelease, *github.Response, error)
+
+	// ListTags lists the tags for a repository along with
+	// the commit that each tag points to.
+	//
+	// GitHub API docs: https://docs.github.com/rest/repos/repos#list-repository-tags
+	//
+	//meta:operation GET /repos/{owner}/{repo}/tags
+	ListTags(
+		ctx context.Context,
+		owner, repo string,
+		opts *github.ListOptions,
+		token string,
+	) ([]*github.RepositoryTag, *github.Response, error)
+}
+
+type githubService struct {
+	httpClient *http.Client
 }
 
-type githubService struct{}
+// GitHubServiceOption is a function that configures
+// the GitHub repository service.
+type GitHubServiceOption func(*githubService)
+
+// WithGitHubHTTPClient configures the HTTP client used
+// to make requests to the GitHub API.
+func WithGitHubHTTPClient(httpClient *http.Client) GitHubServiceOption {
+	return func(g *githubService) {
+		g.httpClient = httpClient
+	}
+}
 
 // NewGitHubService creates a new instance of the GitHub
 // service for interacting with GitHub repositories.
-func NewGitHubService() Service {
-	return &githubService{}
+func NewGitHubService(opts ...GitHubServiceOption) Service {
+	service := &githubService{}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+func (g *githubService) client(token string) *github.Client {
+	return github.NewClient(g.httpClient).WithAuthToken(token)
 }
 
 func (g *githubService) ListByOrg(
@@ -60,8 +150,40 @@ func (g *githubService) ListByOrg(
 	opts *github.RepositoryListByOrgOptions,
 	token string,
 ) ([]*github.Repository, *github.Response, error) {
-	client := github.NewClient(nil).WithAuthToken(token)
-	return client.Repositories.ListByOrg(ctx, org, opts)
+	return g.client(token).Repositories.ListByOrg(ctx, org, opts)
+}
+
+func (g *githubService) ListByUser(
+	ctx context.Context,
+	user string,
+	opts *github.RepositoryListByUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	return g.client(token).Repositories.ListByUser(ctx, user, opts)
+}
+
+func (g *githubService) ListByAuthenticatedUser(
+	ctx context.Context,
+	opts *github.RepositoryListByAuthenticatedUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	return g.client(token).Repositories.ListByAuthenticatedUser(ctx, opts)
+}
+
+func (g *githubService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	return g.client(token).Users.Get(ctx, user)
+}
+
+func (g *githubService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	return g.client(token).Repositories.Get(ctx, owner, repo)
 }
 
 func (g *githubService) ListReleases(
@@ -70,8 +192,7 @@ func (g *githubService) ListReleases(
 	opts *github.ListOptions,
 	token string,
 ) ([]*github.RepositoryRelease, *github.Response, error) {
-	client := github.NewClient(nil).WithAuthToken(token)
-	return client.Repositories.ListReleases(ctx, owner, repo, opts)
+	return g.client(token).Repositories.ListReleases(ctx, owner, repo, opts)
 }
 
 func (g *githubService) GetReleaseByTag(
@@ -79,6 +200,14 @@ func (g *githubService) GetReleaseByTag(
 	owner, repo, tag string,
 	token string,
 ) (*github.RepositoryRelease, *github.Response, error) {
-	client := github.NewClient(nil).WithAuthToken(token)
-	return client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	return g.client(token).Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+}
+
+func (g *githubService) ListTags(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryTag, *github.Response, error) {
+	return g.client(token).Repositories.ListTags(ctx, owner, repo, opts)
 }
@@ -0,0 +1,201 @@
+package signedurls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MinSecretLength is the minimum length of a secret
+	// used to sign download URLs.
+	MinSecretLength = 32
+
+	expiresParam   = "expires"
+	keyIDParam     = "kid"
+	signatureParam = "signature"
+)
+
+var (
+	// ErrInvalidSignature is returned when the signature of a URL
+	// does not match the signed asset, is missing or was created
+	// with an unknown key.
+	ErrInvalidSignature = errors.New("invalid download URL signature")
+
+	// ErrExpired is returned when a signed URL has expired.
+	ErrExpired = errors.New("download URL has expired")
+)
+
+// Key is a secret key used to sign download URLs.
+type Key struct {
+	// ID is included in signed URLs so the key used to sign a URL
+	// can be found when verifying the URL.
+	ID     string
+	Secret []byte
+}
+
+// Asset holds the information about a release asset
+// that is covered by the signature of a download URL.
+type Asset struct {
+	Organisation string
+	Plugin       string
+	Version      string
+	Name         string
+}
+
+// Signer creates and verifies download URLs signed with HMAC-SHA256.
+//
+// URLs are always signed with the first key, all keys are used to
+// verify URLs so keys can be rotated by adding a new key to the start
+// of the list and removing the old key once URLs signed with it
+// have expired.
+type Signer struct {
+	keys     []*Key
+	keysByID map[string]*Key
+	ttl      time.Duration
+	clock    func() time.Time
+}
+
+// SignerOption is a function that configures a signer.
+type SignerOption func(*Signer)
+
+// WithSignerClock configures the function used to get the
+// current time, this is primarily useful for tests.
+func WithSignerClock(clock func() time.Time) SignerOption {
+	return func(s *Signer) {
+		s.clock = clock
+	}
+}
+
+// NewSigner creates a signer for download URLs that expire after
+// the provided amount of time.
+func NewSigner(keys []*Key, ttl time.Duration, opts ...SignerOption) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key must be provided to sign download URLs")
+	}
+
+	keysByID := map[string]*Key{}
+	for _, key := range keys {
+		if len(key.Secret) < MinSecretLength {
+			return nil, fmt.Errorf(
+				"download URL signing key %q must be at least %d characters long",
+				key.ID,
+				MinSecretLength,
+			)
+		}
+
+		if _, exists := keysByID[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate download URL signing key ID %q", key.ID)
+		}
+		keysByID[key.ID] = key
+	}
+
+	signer := &Signer{
+		keys:     keys,
+		keysByID: keysByID,
+		ttl:      ttl,
+		clock:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(signer)
+	}
+
+	return signer, nil
+}
+
+// Sign returns the query parameters that authorise
+// a download of the provided asset until the URL expires.
+func (s *Signer) Sign(asset *Asset) url.Values {
+	key := s.keys[0]
+	expires := strconv.FormatInt(s.clock().Add(s.ttl).Unix(), 10)
+
+	return url.Values{
+		expiresParam:   []string{expires},
+		keyIDParam:     []string{key.ID},
+		signatureParam: []string{signature(key, asset, expires)},
+	}
+}
+
+// IsSigned determines whether the provided query parameters
+// contain a signature, signed URLs should be verified instead
+// of authenticating the request by other means.
+func IsSigned(query url.Values) bool {
+	return query.Has(signatureParam)
+}
+
+// Verify checks that the signature in the provided query parameters
+// is valid for the asset and that the URL has not expired.
+func (s *Signer) Verify(asset *Asset, query url.Values) error {
+	for _, field := range []string{asset.Organisation, asset.Plugin, asset.Version, asset.Name} {
+		if strings.Contains(field, "\n") {
+			return ErrInvalidSignature
+		}
+	}
+
+	key, exists := s.keysByID[query.Get(keyIDParam)]
+	if !exists {
+		return ErrInvalidSignature
+	}
+
+	expires := query.Get(expiresParam)
+	expected := signature(key, asset, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get(signatureParam))) {
+		return ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !s.clock().Before(time.Unix(expiresAt, 0)) {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func signature(key *Key, asset *Asset, expires string) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	// The fields are separated by new lines, which are rejected in
+	// the fields of assets being verified, so different assets
+	// can not produce the same message.
+	mac.Write([]byte(strings.Join(
+		[]string{
+			asset.Organisation,
+			asset.Plugin,
+			asset.Version,
+			asset.Name,
+			expires,
+		},
+		"\n",
+	)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ParseKeys parses download URL signing keys in the {id}:{secret} format.
+func ParseKeys(serialised []string) ([]*Key, error) {
+	keys := []*Key{}
+	for _, entry := range serialised {
+		id, secret, hasSeparator := strings.Cut(strings.TrimSpace(entry), ":")
+		if !hasSeparator || id == "" || secret == "" {
+			return nil, errors.New(
+				"download URL signing keys must be in the {id}:{secret} format",
+			)
+		}
+
+		keys = append(keys, &Key{
+			ID:     id,
+			Secret: []byte(secret),
+		})
+	}
+
+	return keys, nil
+}
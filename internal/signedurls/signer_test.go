@@ -0,0 +1,123 @@
+package signedurls
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SignerTestSuite struct {
+	suite.Suite
+	now    time.Time
+	signer *Signer
+	asset  *Asset
+}
+
+func (s *SignerTestSuite) SetupTest() {
+	s.now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	signer, err := NewSigner(
+		[]*Key{testKey("key-2"), testKey("key-1")},
+		5*time.Minute,
+		WithSignerClock(func() time.Time { return s.now }),
+	)
+	s.Require().NoError(err)
+	s.signer = signer
+	s.asset = &Asset{
+		Organisation: "newstack-cloud",
+		Plugin:       "aws",
+		Version:      "1.0.0",
+		Name:         "bluelink-provider-aws_1.0.0_linux_amd64.zip",
+	}
+}
+
+func (s *SignerTestSuite) Test_verifies_signed_url_until_expiry() {
+	query := s.signer.Sign(s.asset)
+	s.Assert().Equal("key-2", query.Get("kid"))
+	s.Assert().True(IsSigned(query))
+	s.Require().NoError(s.signer.Verify(s.asset, query))
+
+	s.now = s.now.Add(5 * time.Minute)
+	s.Assert().ErrorIs(s.signer.Verify(s.asset, query), ErrExpired)
+}
+
+func (s *SignerTestSuite) Test_verifies_url_signed_with_rotated_key() {
+	oldSigner, err := NewSigner(
+		[]*Key{testKey("key-1")},
+		5*time.Minute,
+		WithSignerClock(func() time.Time { return s.now }),
+	)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.signer.Verify(s.asset, oldSigner.Sign(s.asset)))
+}
+
+func (s *SignerTestSuite) Test_rejects_url_for_different_asset() {
+	query := s.signer.Sign(s.asset)
+
+	otherAssets := []*Asset{
+		{Organisation: "other-org", Plugin: "aws", Version: "1.0.0", Name: s.asset.Name},
+		{Organisation: "newstack-cloud", Plugin: "azure", Version: "1.0.0", Name: s.asset.Name},
+		{Organisation: "newstack-cloud", Plugin: "aws", Version: "1.0.1", Name: s.asset.Name},
+		{Organisation: "newstack-cloud", Plugin: "aws", Version: "1.0.0", Name: "bluelink-provider-aws_1.0.0_SHA256SUMS"},
+	}
+	for _, asset := range otherAssets {
+		s.Assert().ErrorIs(s.signer.Verify(asset, query), ErrInvalidSignature)
+	}
+}
+
+func (s *SignerTestSuite) Test_rejects_tampered_urls() {
+	extendedExpiry := s.signer.Sign(s.asset)
+	extendedExpiry.Set("expires", "9999999999")
+
+	unknownKey := s.signer.Sign(s.asset)
+	unknownKey.Set("kid", "key-3")
+
+	queries := []url.Values{
+		extendedExpiry,
+		unknownKey,
+		{"signature": []string{"invalid"}},
+	}
+	for _, query := range queries {
+		s.Assert().ErrorIs(s.signer.Verify(s.asset, query), ErrInvalidSignature)
+	}
+}
+
+func (s *SignerTestSuite) Test_rejects_invalid_keys() {
+	_, err := NewSigner([]*Key{}, time.Minute)
+	s.Assert().Error(err)
+
+	_, err = NewSigner([]*Key{{ID: "key-1", Secret: []byte("too-short")}}, time.Minute)
+	s.Assert().Error(err)
+
+	_, err = NewSigner([]*Key{testKey("key-1"), testKey("key-1")}, time.Minute)
+	s.Assert().Error(err)
+}
+
+func (s *SignerTestSuite) Test_parses_keys() {
+	keys, err := ParseKeys([]string{"key-2:secret:with:colons", " key-1:secret "})
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		[]*Key{
+			{ID: "key-2", Secret: []byte("secret:with:colons")},
+			{ID: "key-1", Secret: []byte("secret")},
+		},
+		keys,
+	)
+
+	_, err = ParseKeys([]string{"no-separator"})
+	s.Assert().Error(err)
+}
+
+func testKey(id string) *Key {
+	return &Key{
+		ID:     id,
+		Secret: []byte(strings.Repeat(id, 8)),
+	}
+}
+
+func TestSignerTestSuite(t *testing.T) {
+	suite.Run(t, new(SignerTestSuite))
+}
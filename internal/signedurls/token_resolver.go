@@ -0,0 +1,64 @@
+package signedurls
+
+import (
+	"net/http"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+)
+
+// SignedURLSubject is the subject of the principal
+// for requests authorised by a signed URL.
+const SignedURLSubject = "signed-url"
+
+type tokenResolver struct {
+	signer      *Signer
+	tokenSource auth.TokenSource
+	fallback    auth.TokenResolver
+}
+
+// NewTokenResolver creates a token resolver for download requests
+// that authorises requests with a signed URL without credentials,
+// using the provided token source for the registry's own credentials
+// for GitHub.
+// Requests without a signature are resolved with the fallback resolver,
+// requests with an invalid or expired signature are rejected.
+func NewTokenResolver(
+	signer *Signer,
+	tokenSource auth.TokenSource,
+	fallback auth.TokenResolver,
+) auth.TokenResolver {
+	return &tokenResolver{
+		signer:      signer,
+		tokenSource: tokenSource,
+		fallback:    fallback,
+	}
+}
+
+func (r *tokenResolver) ResolveToken(
+	req *http.Request,
+	resource *auth.Resource,
+) (string, error) {
+	query := req.URL.Query()
+	if !IsSigned(query) {
+		return r.fallback.ResolveToken(req, resource)
+	}
+
+	err := r.signer.Verify(
+		&Asset{
+			Organisation: resource.Owner,
+			Plugin:       resource.Plugin,
+			Version:      resource.Version,
+			Name:         resource.Asset,
+		},
+		query,
+	)
+	if err != nil {
+		return "", auth.ErrUnauthenticated
+	}
+
+	return r.tokenSource.Token(
+		req.Context(),
+		&auth.Principal{Subject: SignedURLSubject},
+		resource.Owner,
+	)
+}
@@ -0,0 +1,86 @@
+package signedurls
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenResolverTestSuite struct {
+	suite.Suite
+	signer   *Signer
+	resolver auth.TokenResolver
+	resource *auth.Resource
+}
+
+func (s *TokenResolverTestSuite) SetupTest() {
+	signer, err := NewSigner([]*Key{testKey("key-1")}, 5*time.Minute)
+	s.Require().NoError(err)
+	s.signer = signer
+	s.resolver = NewTokenResolver(
+		signer,
+		&stubTokenSource{},
+		auth.NewTokenResolver(
+			auth.NewPassthroughAuthenticator("bluelink-gh-registry-token"),
+			auth.NewPassthroughTokenSource(),
+		),
+	)
+	s.resource = &auth.Resource{
+		Owner:   "newstack-cloud",
+		Plugin:  "aws",
+		Version: "1.0.0",
+		Asset:   "bluelink-provider-aws_1.0.0_linux_amd64.zip",
+	}
+}
+
+func (s *TokenResolverTestSuite) Test_resolves_server_token_for_signed_url() {
+	query := s.signer.Sign(&Asset{
+		Organisation: s.resource.Owner,
+		Plugin:       s.resource.Plugin,
+		Version:      s.resource.Version,
+		Name:         s.resource.Asset,
+	})
+	req := httptest.NewRequest("GET", "/plugins/download?"+query.Encode(), nil)
+
+	token, err := s.resolver.ResolveToken(req, s.resource)
+	s.Require().NoError(err)
+	s.Assert().Equal("ghs_installation-token", token)
+}
+
+func (s *TokenResolverTestSuite) Test_rejects_invalid_signature_even_with_credentials() {
+	req := httptest.NewRequest("GET", "/plugins/download?kid=key-1&expires=1&signature=invalid", nil)
+	req.Header.Set("bluelink-gh-registry-token", "client-token")
+
+	_, err := s.resolver.ResolveToken(req, s.resource)
+	s.Assert().ErrorIs(err, auth.ErrUnauthenticated)
+}
+
+func (s *TokenResolverTestSuite) Test_falls_back_for_unsigned_requests() {
+	req := httptest.NewRequest("GET", "/plugins/download", nil)
+	req.Header.Set("bluelink-gh-registry-token", "client-token")
+
+	token, err := s.resolver.ResolveToken(req, s.resource)
+	s.Require().NoError(err)
+	s.Assert().Equal("client-token", token)
+}
+
+type stubTokenSource struct{}
+
+func (s *stubTokenSource) Token(
+	ctx context.Context,
+	principal *auth.Principal,
+	owner string,
+) (string, error) {
+	if principal.Subject != SignedURLSubject {
+		return "", auth.ErrUnauthenticated
+	}
+	return "ghs_installation-token", nil
+}
+
+func TestTokenResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenResolverTestSuite))
+}
@@ -18,6 +18,11 @@ type StubRepoService struct {
 	// A mapping of repo name and tag in the format `{repo}::{tag}`
 	// to the release.
 	releaseTagLookup map[string]*github.RepositoryRelease
+	// The login of the user that the token used
+	// in requests belongs to.
+	authenticatedUser string
+	// A mapping of repository names to lists of tags.
+	tags map[string][]*github.RepositoryTag
 }
 
 // NewStubRepoService creates a new instance of the
@@ -55,6 +60,109 @@ func (s *StubRepoService) ListByOrg(
 	}, nil
 }
 
+// WithAuthenticatedUser sets the login of the user that
+// tokens used with the stub service belong to.
+func (s *StubRepoService) WithAuthenticatedUser(login string) *StubRepoService {
+	s.authenticatedUser = login
+	return s
+}
+
+// ListByUser lists the public repositories owned by a user.
+func (s *StubRepoService) ListByUser(
+	ctx context.Context,
+	user string,
+	opts *github.RepositoryListByUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	userRepos := []*github.Repository{}
+
+	for _, repo := range s.repos {
+		if repo.GetOwner().GetLogin() == user && !repo.GetPrivate() {
+			userRepos = append(userRepos, repo)
+		}
+	}
+
+	return userRepos, &github.Response{
+		NextPage: 0,
+	}, nil
+}
+
+// ListByAuthenticatedUser lists all the repositories owned by
+// the authenticated user, including private repositories.
+func (s *StubRepoService) ListByAuthenticatedUser(
+	ctx context.Context,
+	opts *github.RepositoryListByAuthenticatedUserOptions,
+	token string,
+) ([]*github.Repository, *github.Response, error) {
+	userRepos := []*github.Repository{}
+
+	for _, repo := range s.repos {
+		if s.authenticatedUser != "" &&
+			repo.GetOwner().GetLogin() == s.authenticatedUser {
+			userRepos = append(userRepos, repo)
+		}
+	}
+
+	return userRepos, &github.Response{
+		NextPage: 0,
+	}, nil
+}
+
+// GetUser returns the owner of the stub repositories with the provided
+// login, owners without a type are treated as organisations.
+func (s *StubRepoService) GetUser(
+	ctx context.Context,
+	user string,
+	token string,
+) (*github.User, *github.Response, error) {
+	if user == "" {
+		user = s.authenticatedUser
+	}
+
+	for _, repo := range s.repos {
+		owner := repo.GetOwner()
+		if user != "" && owner.GetLogin() == user {
+			ownerType := owner.GetType()
+			if ownerType == "" {
+				ownerType = "Organization"
+			}
+			return &github.User{
+				Login: github.Ptr(owner.GetLogin()),
+				Type:  github.Ptr(ownerType),
+			}, nil, nil
+		}
+	}
+
+	return nil, &github.Response{
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	}, errors.New("user not found")
+}
+
+func (s *StubRepoService) GetRepository(
+	ctx context.Context,
+	owner, repo string,
+	token string,
+) (*github.Repository, *github.Response, error) {
+	for _, candidate := range s.repos {
+		if candidate.GetOwner().GetLogin() == owner &&
+			candidate.GetName() == repo {
+			return candidate, &github.Response{
+				Response: &http.Response{
+					StatusCode: http.StatusOK,
+				},
+			}, nil
+		}
+	}
+
+	return nil, &github.Response{
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	}, errors.New("repository not found")
+}
+
 func (s *StubRepoService) ListReleases(
 	ctx context.Context,
 	owner, repo string,
@@ -88,6 +196,32 @@ func (g *StubRepoService) GetReleaseByTag(
 	}, errors.New("release not found")
 }
 
+// WithTags sets the tags for repositories of the stub service,
+// keyed by repository name.
+func (s *StubRepoService) WithTags(
+	tags map[string][]*github.RepositoryTag,
+) *StubRepoService {
+	s.tags = tags
+	return s
+}
+
+func (s *StubRepoService) ListTags(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+	token string,
+) ([]*github.RepositoryTag, *github.Response, error) {
+	if repoTags, ok := s.tags[repo]; ok {
+		return repoTags, &github.Response{
+			NextPage: 0,
+		}, nil
+	}
+
+	return []*github.RepositoryTag{}, &github.Response{
+		NextPage: 0,
+	}, nil
+}
+
 func toTagLookup(
 	releaseMap map[string][]*github.RepositoryRelease,
 ) map[string]*github.RepositoryRelease {
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // PluginVersions holds the information about the plugin versions
 // that are available for a given plugin.
 type PluginVersions struct {
@@ -13,6 +15,26 @@ type PluginVersion struct {
 	Version            string                   `json:"version"`
 	SupportedProtocols []string                 `json:"supportedProtocols"`
 	SupportedPlatforms []*PluginVersionPlatform `json:"supportedPlatforms"`
+	Prerelease         bool                     `json:"prerelease"`
+	PublishedAt        *time.Time               `json:"publishedAt,omitempty"`
+	CommitSHA          string                   `json:"commitSha,omitempty"`
+	HTMLURL            string                   `json:"htmlUrl,omitempty"`
+}
+
+// PluginVersionResolution holds the plugin version that was resolved
+// for a version constraint, platform and protocol along with the
+// package information for the resolved version and platform.
+type PluginVersionResolution struct {
+	Version *PluginVersion        `json:"version"`
+	Package *PluginVersionPackage `json:"package"`
+}
+
+// UnresolvedPluginVersion holds a plugin version that was considered
+// when resolving a version constraint along with the reasons
+// that the version was not resolved.
+type UnresolvedPluginVersion struct {
+	Version string   `json:"version"`
+	Reasons []string `json:"reasons"`
 }
 
 // PluginVersionPlatform holds the information about
@@ -36,10 +58,15 @@ type PluginRegistryInfo struct {
 // This represents the structure of a plugin version package
 // expected by the Bluelink registry protocol.
 type PluginVersionPackage struct {
+	// Version is the concrete version of the package,
+	// this is useful when the package was requested
+	// with a version alias such as "latest".
+	Version             string                `json:"version"`
 	SupportedProtocols  []string              `json:"supportedProtocols"`
 	OS                  string                `json:"os"`
 	Arch                string                `json:"arch"`
 	Filename            string                `json:"filename"`
+	ArchiveFormat       string                `json:"archiveFormat"`
 	DownloadURL         string                `json:"downloadUrl"`
 	SHASumsURL          string                `json:"shasumsUrl"`
 	SHASumsSignatureURL string                `json:"shasumsSignatureUrl"`
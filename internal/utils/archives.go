@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ArchiveFormatZip is the archive format for zip files.
+	ArchiveFormatZip = "zip"
+	// ArchiveFormatTarGz is the archive format for gzip compressed tarballs.
+	ArchiveFormatTarGz = "tar.gz"
+	// ArchiveFormatTgz is the archive format for gzip compressed tarballs
+	// with the shortened file extension.
+	ArchiveFormatTgz = "tgz"
+	// ArchiveFormatTarXz is the archive format for xz compressed tarballs.
+	ArchiveFormatTarXz = "tar.xz"
+)
+
+// SupportedArchiveFormats holds the archive formats that can be
+// recognised for plugin archives, the format is the file extension
+// of the archive without the leading ".".
+var SupportedArchiveFormats = []string{
+	ArchiveFormatZip,
+	ArchiveFormatTarGz,
+	ArchiveFormatTgz,
+	ArchiveFormatTarXz,
+}
+
+// DefaultArchiveFormats holds the archive formats that are recognised
+// for plugin archives when archive formats are not configured,
+// in order of preference.
+var DefaultArchiveFormats = []string{
+	ArchiveFormatZip,
+	ArchiveFormatTarGz,
+}
+
+func validateArchiveFormats(formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("at least one archive format must be provided")
+	}
+
+	for i, format := range formats {
+		if !slices.Contains(SupportedArchiveFormats, format) {
+			return fmt.Errorf(
+				"unsupported archive format %q, supported formats are: %s",
+				format,
+				strings.Join(SupportedArchiveFormats, ", "),
+			)
+		}
+
+		if slices.Contains(formats[:i], format) {
+			return fmt.Errorf("archive format %q is provided more than once", format)
+		}
+	}
+
+	return nil
+}
+
+// archiveFormatPattern creates a pattern that matches the file extension
+// of any of the provided archive formats, capturing the format.
+func archiveFormatPattern(formats []string) string {
+	quoted := make([]string, len(formats))
+	for i, format := range formats {
+		quoted[i] = regexp.QuoteMeta(format)
+	}
+
+	return fmt.Sprintf(
+		`\.(?P<%s>%s)`,
+		captureFormat,
+		strings.Join(quoted, "|"),
+	)
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
+)
+
+// ArtifactKind is the kind of release artifact
+// that is downloaded to extract plugin version information.
+type ArtifactKind string
+
+const (
+	// ArtifactKindRegistryInfo is the kind for the
+	// `_registry_info.json` file published with a release.
+	ArtifactKindRegistryInfo ArtifactKind = "registry_info"
+
+	// ArtifactKindSHASums is the kind for the
+	// `_SHA256SUMS` file published with a release.
+	ArtifactKindSHASums ArtifactKind = "shasums"
+)
+
+// ArtifactKey uniquely identifies a release artifact.
+type ArtifactKey struct {
+	Owner      string
+	Repository string
+	Tag        string
+	AssetID    int64
+	Kind       ArtifactKind
+}
+
+// ArtifactCache provides an interface for a cache that holds
+// the contents of release artifacts.
+// The token that was used to fetch the release that the artifact
+// belongs to is provided so that implementations can isolate
+// entries between callers.
+type ArtifactCache interface {
+	// Get retrieves the contents of a cached artifact,
+	// the second return value will be false if the artifact
+	// is not in the cache.
+	Get(ctx context.Context, key *ArtifactKey, token string) ([]byte, bool)
+
+	// Set stores the contents of an artifact in the cache.
+	Set(ctx context.Context, key *ArtifactKey, token string, contents []byte)
+}
+
+func fetchArtifact(
+	ctx context.Context,
+	client httputils.Client,
+	artifactCache ArtifactCache,
+	key *ArtifactKey,
+	asset *github.ReleaseAsset,
+	token string,
+) ([]byte, error) {
+	if artifactCache == nil || asset == nil {
+		return downloadFromGitHub(ctx, client, asset.GetURL(), token)
+	}
+
+	if contents, ok := artifactCache.Get(ctx, key, token); ok {
+		return contents, nil
+	}
+
+	contents, err := downloadFromGitHub(ctx, client, asset.GetURL(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactCache.Set(ctx, key, token, contents)
+	return contents, nil
+}
+
+type tieredArtifactCache struct {
+	tiers []ArtifactCache
+}
+
+// NewTieredArtifactCache creates an artifact cache that checks each
+// of the provided caches in order, the first cache should be the fastest
+// to read from.
+// When an artifact is found in a later tier, it is written back
+// to the earlier tiers so subsequent reads are served by the faster caches.
+func NewTieredArtifactCache(tiers ...ArtifactCache) ArtifactCache {
+	return &tieredArtifactCache{
+		tiers: tiers,
+	}
+}
+
+func (c *tieredArtifactCache) Get(
+	ctx context.Context,
+	key *ArtifactKey,
+	token string,
+) ([]byte, bool) {
+	for i, tier := range c.tiers {
+		if contents, ok := tier.Get(ctx, key, token); ok {
+			for _, earlierTier := range c.tiers[:i] {
+				earlierTier.Set(ctx, key, token, contents)
+			}
+			return contents, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *tieredArtifactCache) Set(
+	ctx context.Context,
+	key *ArtifactKey,
+	token string,
+	contents []byte,
+) {
+	for _, tier := range c.tiers {
+		tier.Set(ctx, key, token, contents)
+	}
+}
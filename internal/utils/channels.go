@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+)
+
+const (
+	// ReleaseChannelStable is the release channel that only includes
+	// releases that are not prereleases.
+	ReleaseChannelStable = "stable"
+	// ReleaseChannelPrerelease is the release channel that only includes
+	// prereleases.
+	ReleaseChannelPrerelease = "prerelease"
+	// ReleaseChannelAll is the release channel that includes both
+	// stable releases and prereleases.
+	ReleaseChannelAll = "all"
+)
+
+// ReleaseChannels holds the release channels that can be used
+// to filter the versions of a plugin.
+var ReleaseChannels = []string{
+	ReleaseChannelStable,
+	ReleaseChannelPrerelease,
+	ReleaseChannelAll,
+}
+
+// ValidateReleaseChannel checks that the provided release channel
+// is one of the supported release channels.
+func ValidateReleaseChannel(channel string) error {
+	if !slices.Contains(ReleaseChannels, channel) {
+		return fmt.Errorf(
+			"unsupported release channel %q, supported channels are: %s",
+			channel,
+			strings.Join(ReleaseChannels, ", "),
+		)
+	}
+
+	return nil
+}
+
+// isPrerelease determines whether a release is a prerelease,
+// a release is a prerelease if it is marked as a prerelease in GitHub
+// or the tag has a semantic version prerelease suffix (e.g. "v1.0.0-beta.1").
+func isPrerelease(release *github.RepositoryRelease) bool {
+	if release.GetPrerelease() {
+		return true
+	}
+
+	matches := validTagPattern.FindStringSubmatch(release.GetTagName())
+	return len(matches) > 4 && matches[4] != ""
+}
+
+func inReleaseChannel(channel string, release *github.RepositoryRelease) bool {
+	switch channel {
+	case ReleaseChannelAll:
+		return true
+	case ReleaseChannelPrerelease:
+		return isPrerelease(release)
+	default:
+		return !isPrerelease(release)
+	}
+}
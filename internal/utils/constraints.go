@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionConstraint is a semantic version constraint that versions
+// of a plugin can be checked against.
+//
+// Constraints are made up of comparators separated by spaces or commas,
+// where all the comparators must be satisfied, sets of comparators can be
+// separated by "||" where any of the sets must be satisfied.
+// The supported comparators are:
+//
+//   - "1.2.3" or "=1.2.3" for an exact version.
+//   - "!=1.2.3" to exclude a version.
+//   - ">1.2.3", ">=1.2.3", "<1.2.3" and "<=1.2.3" for comparisons.
+//   - "^1.2.3" for versions that do not change the first non-zero component.
+//   - "~1.2.3" for versions that only change the patch version.
+//   - "1.2.x", "1.x" or "*" for any version matching the provided components.
+//
+// Prereleases only satisfy a constraint when a comparator in the same set
+// has a prerelease for the same major, minor and patch version.
+type VersionConstraint struct {
+	constraint string
+	sets       [][]*versionComparator
+}
+
+type versionComparator struct {
+	operator string
+	version  string
+}
+
+const (
+	operatorEqual              = "="
+	operatorNotEqual           = "!="
+	operatorGreaterThan        = ">"
+	operatorGreaterThanOrEqual = ">="
+	operatorLessThan           = "<"
+	operatorLessThanOrEqual    = "<="
+	operatorCaret              = "^"
+	operatorTilde              = "~"
+)
+
+var (
+	comparatorPattern = regexp.MustCompile(
+		`^(!=|>=|<=|>|<|=|\^|~)?\s*v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?` +
+			`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`,
+	)
+	// Operators can be separated from the version by whitespace,
+	// this is removed before splitting a set into comparators.
+	operatorSpacingPattern = regexp.MustCompile(`(!=|>=|<=|>|<|=|\^|~)\s+`)
+)
+
+// ParseVersionConstraint parses a semantic version constraint,
+// an error is returned if the constraint is not valid.
+func ParseVersionConstraint(constraint string) (*VersionConstraint, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return nil, fmt.Errorf("version constraint must not be empty")
+	}
+
+	sets := [][]*versionComparator{}
+	for _, set := range strings.Split(constraint, "||") {
+		normalised := operatorSpacingPattern.ReplaceAllString(set, "$1")
+		fields := strings.FieldsFunc(normalised, func(char rune) bool {
+			return char == ',' || char == ' ' || char == '\t'
+		})
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("version constraint %q has an empty set of comparators", constraint)
+		}
+
+		comparators := []*versionComparator{}
+		for _, field := range fields {
+			expanded, err := parseComparator(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, expanded...)
+		}
+		sets = append(sets, comparators)
+	}
+
+	return &VersionConstraint{
+		constraint: constraint,
+		sets:       sets,
+	}, nil
+}
+
+// Check determines whether the provided version without the "v" prefix
+// satisfies the constraint.
+func (c *VersionConstraint) Check(version string) bool {
+	if !validTagPattern.MatchString("v" + version) {
+		return false
+	}
+
+	for _, set := range c.sets {
+		if setSatisfied(set, version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *VersionConstraint) String() string {
+	return c.constraint
+}
+
+func setSatisfied(set []*versionComparator, version string) bool {
+	for _, comparator := range set {
+		if !comparator.check(version) {
+			return false
+		}
+	}
+
+	parts := versionParts(version)
+	if parts[3] == "" {
+		return true
+	}
+
+	for _, comparator := range set {
+		comparatorParts := versionParts(comparator.version)
+		if comparatorParts[3] != "" && [3]string(comparatorParts[:3]) == [3]string(parts[:3]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *versionComparator) check(version string) bool {
+	result := compareVersions(version, c.version)
+	switch c.operator {
+	case operatorNotEqual:
+		return result != 0
+	case operatorGreaterThan:
+		return result > 0
+	case operatorGreaterThanOrEqual:
+		return result >= 0
+	case operatorLessThan:
+		return result < 0
+	case operatorLessThanOrEqual:
+		return result <= 0
+	default:
+		return result == 0
+	}
+}
+
+// parseComparator parses a single comparator, expanding comparators
+// for ranges such as "^1.2.3" or "1.x" into the equivalent
+// primitive comparators.
+func parseComparator(comparator string) ([]*versionComparator, error) {
+	matches := comparatorPattern.FindStringSubmatch(comparator)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version constraint comparator %q", comparator)
+	}
+
+	operator := matches[1]
+	prerelease := matches[5]
+	components := []string{}
+	for _, component := range matches[2:5] {
+		if component == "" || isWildcard(component) {
+			break
+		}
+		if !isValidNumericComponent(component) {
+			return nil, fmt.Errorf("invalid version number %q in comparator %q", component, comparator)
+		}
+		components = append(components, component)
+	}
+
+	if prerelease != "" && len(components) < 3 {
+		return nil, fmt.Errorf(
+			"comparator %q must provide a major, minor and patch version with a prerelease",
+			comparator,
+		)
+	}
+
+	return expandComparator(operator, components, prerelease), nil
+}
+
+func expandComparator(
+	operator string,
+	components []string,
+	prerelease string,
+) []*versionComparator {
+	lower := formatVersion(components, prerelease)
+
+	switch operator {
+	case operatorCaret:
+		return rangeComparators(lower, caretUpperBound(components))
+	case operatorTilde:
+		return rangeComparators(lower, tildeUpperBound(components))
+	case operatorGreaterThan:
+		if len(components) < 3 {
+			// Any version matching a partial version is excluded,
+			// for example, ">1.2" is the same as ">=1.3.0".
+			return rangeComparators(partialUpperBound(components), "")
+		}
+		return []*versionComparator{{operator: operator, version: lower}}
+	case operatorLessThanOrEqual:
+		if len(components) < 3 {
+			// Any version matching a partial version is included,
+			// for example, "<=1.2" is the same as "<1.3.0".
+			return rangeComparators("", partialUpperBound(components))
+		}
+		return []*versionComparator{{operator: operator, version: lower}}
+	case operatorGreaterThanOrEqual, operatorLessThan, operatorNotEqual:
+		return []*versionComparator{{operator: operator, version: lower}}
+	default:
+		if len(components) < 3 {
+			return rangeComparators(lower, partialUpperBound(components))
+		}
+		return []*versionComparator{{operator: operatorEqual, version: lower}}
+	}
+}
+
+func rangeComparators(lower string, upper string) []*versionComparator {
+	comparators := []*versionComparator{{
+		operator: operatorGreaterThanOrEqual,
+		version:  lower,
+	}}
+	if lower == "" {
+		comparators = []*versionComparator{}
+	}
+
+	if upper != "" {
+		comparators = append(comparators, &versionComparator{
+			operator: operatorLessThan,
+			version:  upper,
+		})
+	}
+
+	return comparators
+}
+
+// caretUpperBound returns the upper bound for a caret range where
+// the first non-zero component of the version can not change,
+// for example, "^1.2.3" is "<2.0.0" and "^0.2.3" is "<0.3.0".
+func caretUpperBound(components []string) string {
+	for i, component := range components {
+		if component != "0" || i == len(components)-1 {
+			return bumpComponent(components, i)
+		}
+	}
+
+	// A caret range for "0" or any version allows any version.
+	return partialUpperBound(components)
+}
+
+// tildeUpperBound returns the upper bound for a tilde range where
+// only the patch version can change when a minor version is provided,
+// for example, "~1.2.3" is "<1.3.0" and "~1" is "<2.0.0".
+func tildeUpperBound(components []string) string {
+	if len(components) >= 2 {
+		return bumpComponent(components, 1)
+	}
+
+	return partialUpperBound(components)
+}
+
+// partialUpperBound returns the lowest version that does not match a
+// partial version, for example "1.2" is "<1.3.0", no upper bound is
+// returned for a version without any components.
+func partialUpperBound(components []string) string {
+	if len(components) == 0 {
+		return ""
+	}
+
+	if len(components) == 3 {
+		return formatVersion(components, "")
+	}
+
+	return bumpComponent(components, len(components)-1)
+}
+
+func bumpComponent(components []string, index int) string {
+	bumped := make([]string, index+1)
+	copy(bumped, components[:index])
+	value, _ := strconv.ParseUint(components[index], 10, 64)
+	bumped[index] = strconv.FormatUint(value+1, 10)
+	return formatVersion(bumped, "")
+}
+
+func formatVersion(components []string, prerelease string) string {
+	padded := make([]string, 3)
+	for i := range padded {
+		padded[i] = "0"
+		if i < len(components) {
+			padded[i] = components[i]
+		}
+	}
+
+	version := strings.Join(padded, ".")
+	if prerelease != "" {
+		return fmt.Sprintf("%s-%s", version, prerelease)
+	}
+
+	return version
+}
+
+func isWildcard(component string) bool {
+	return component == "x" || component == "X" || component == "*"
+}
+
+// isValidNumericComponent checks that a version component has no leading
+// zeros and can be bumped for range upper bounds without overflowing.
+func isValidNumericComponent(component string) bool {
+	if len(component) > 1 && component[0] == '0' {
+		return false
+	}
+
+	value, err := strconv.ParseUint(component, 10, 64)
+	return err == nil && value < 1<<63
+}
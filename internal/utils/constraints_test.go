@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionConstraintTestSuite struct {
+	suite.Suite
+}
+
+func (s *VersionConstraintTestSuite) Test_checks_versions_against_constraints() {
+	testCases := []struct {
+		constraint  string
+		satisfiedBy []string
+		rejects     []string
+	}{
+		{
+			constraint:  "^1.4.0",
+			satisfiedBy: []string{"1.4.0", "1.4.7", "1.9.0"},
+			rejects:     []string{"1.3.9", "2.0.0", "1.5.0-beta.1", "2.0.0-alpha"},
+		},
+		{
+			constraint:  "^0.4.1",
+			satisfiedBy: []string{"0.4.1", "0.4.9"},
+			rejects:     []string{"0.4.0", "0.5.0", "1.0.0"},
+		},
+		{
+			constraint:  "^0.0.3",
+			satisfiedBy: []string{"0.0.3"},
+			rejects:     []string{"0.0.4", "0.1.0"},
+		},
+		{
+			constraint:  "~1.4.2",
+			satisfiedBy: []string{"1.4.2", "1.4.10"},
+			rejects:     []string{"1.4.1", "1.5.0"},
+		},
+		{
+			constraint:  "~1",
+			satisfiedBy: []string{"1.0.0", "1.9.3"},
+			rejects:     []string{"2.0.0"},
+		},
+		{
+			constraint:  "1.2.x",
+			satisfiedBy: []string{"1.2.0", "1.2.99"},
+			rejects:     []string{"1.3.0", "1.1.9"},
+		},
+		{
+			constraint:  "*",
+			satisfiedBy: []string{"0.0.1", "10.2.3"},
+			rejects:     []string{"1.0.0-rc.1"},
+		},
+		{
+			constraint:  ">= 1.2, < 2",
+			satisfiedBy: []string{"1.2.0", "1.99.0"},
+			rejects:     []string{"1.1.9", "2.0.0"},
+		},
+		{
+			constraint:  ">1.2 <=1.4",
+			satisfiedBy: []string{"1.3.0", "1.4.5"},
+			rejects:     []string{"1.2.9", "1.5.0"},
+		},
+		{
+			constraint:  "=1.2.3",
+			satisfiedBy: []string{"1.2.3", "1.2.3+build.1"},
+			rejects:     []string{"1.2.4"},
+		},
+		{
+			constraint:  "^1.0.0 !=1.0.2",
+			satisfiedBy: []string{"1.0.1", "1.0.3"},
+			rejects:     []string{"1.0.2"},
+		},
+		{
+			constraint:  "^1.2.0 || ^3.0.0",
+			satisfiedBy: []string{"1.9.0", "3.1.0"},
+			rejects:     []string{"2.0.0", "4.0.0"},
+		},
+		{
+			// Prereleases are only allowed for the same
+			// major, minor and patch version as the constraint.
+			constraint:  ">=1.5.0-beta.1",
+			satisfiedBy: []string{"1.5.0-beta.1", "1.5.0-beta.2", "1.5.0", "2.0.0"},
+			rejects:     []string{"1.5.0-alpha.1", "1.6.0-beta.1"},
+		},
+		{
+			constraint:  "v2.0.0",
+			satisfiedBy: []string{"2.0.0"},
+			rejects:     []string{"2.0.1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.constraint, func() {
+			constraint, err := ParseVersionConstraint(testCase.constraint)
+			s.Require().NoError(err)
+
+			for _, version := range testCase.satisfiedBy {
+				s.Assert().True(constraint.Check(version), "expected %q to satisfy the constraint", version)
+			}
+
+			for _, version := range testCase.rejects {
+				s.Assert().False(constraint.Check(version), "expected %q to not satisfy the constraint", version)
+			}
+		})
+	}
+}
+
+func (s *VersionConstraintTestSuite) Test_fails_for_invalid_constraints() {
+	constraints := []string{
+		"",
+		"latest",
+		"^1.4.0 ||",
+		">>1.0.0",
+		"1.2.3.4",
+		"01.2.3",
+		"1.x-beta",
+		"99999999999999999999.0.0",
+	}
+
+	for _, constraint := range constraints {
+		s.Run(constraint, func() {
+			_, err := ParseVersionConstraint(constraint)
+			s.Assert().Error(err)
+		})
+	}
+}
+
+func TestVersionConstraintTestSuite(t *testing.T) {
+	suite.Run(t, new(VersionConstraintTestSuite))
+}
@@ -0,0 +1,657 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultRepoNameTemplate is the default template for the names
+	// of plugin repositories, e.g. "bluelink-provider-aws".
+	DefaultRepoNameTemplate = "bluelink-{type}-{plugin}"
+	// DefaultArchiveNameTemplate is the default template for the names
+	// of plugin archives in releases without the file extension,
+	// e.g. "bluelink-provider-aws_1.0.0_linux_amd64".
+	DefaultArchiveNameTemplate = "{repo}_{version}_{os}_{arch}"
+	// DefaultChecksumsNameTemplate is the default template for the names
+	// of the SHA256 checksums files in releases,
+	// e.g. "bluelink-provider-aws_1.0.0_SHA256SUMS".
+	DefaultChecksumsNameTemplate = "{repo}_{version}_SHA256SUMS"
+)
+
+// The name of the capture group for the archive format
+// in archive name patterns.
+const captureFormat = "format"
+
+const (
+	placeholderOwner   = "owner"
+	placeholderPlugin  = "plugin"
+	placeholderType    = "type"
+	placeholderRepo    = "repo"
+	placeholderVersion = "version"
+	placeholderOS      = "os"
+	placeholderArch    = "arch"
+)
+
+const (
+	signatureExtension = ".sig"
+)
+
+// NamingTemplates holds the templates used to derive the names of
+// plugin repositories and release assets.
+// Templates are made up of literal text and placeholders
+// in the form "{placeholder}".
+type NamingTemplates struct {
+	// Repo is the template for the names of plugin repositories,
+	// this supports the {owner}, {type} and {plugin} placeholders
+	// and must contain the {plugin} placeholder.
+	Repo string
+	// Archive is the template for the names of plugin archives without
+	// the file extension, this supports the {owner}, {type}, {plugin}, {repo},
+	// {version}, {os} and {arch} placeholders and must contain the {version},
+	// {os} and {arch} placeholders.
+	Archive string
+	// Checksums is the template for the names of SHA256 checksums files,
+	// this supports the {owner}, {type}, {plugin}, {repo} and {version}
+	// placeholders and must contain the {version} placeholder.
+	// The name of the signature file for the checksums is derived
+	// by adding the ".sig" extension.
+	Checksums string
+}
+
+// NamingConvention derives the names of plugin repositories and
+// release assets from a set of validated naming templates.
+type NamingConvention struct {
+	repo           *nameTemplate
+	archive        *nameTemplate
+	checksums      *nameTemplate
+	archiveFormats []string
+	platforms      *Platforms
+	// Patterns are compiled once when the naming convention is created,
+	// placeholders with values that are only known for a request are
+	// captured and checked against the values after matching.
+	repoPattern    *regexp.Regexp
+	archivePattern *regexp.Regexp
+}
+
+// NamingConventionOption is a function that configures
+// a naming convention.
+type NamingConventionOption func(*NamingConvention)
+
+// WithArchiveFormats configures the archive formats that are recognised
+// for plugin archives in order of preference, when a release contains
+// archives in multiple formats for a platform, the first format in the
+// list is used.
+// When not set, DefaultArchiveFormats will be used.
+func WithArchiveFormats(formats []string) NamingConventionOption {
+	return func(c *NamingConvention) {
+		c.archiveFormats = formats
+	}
+}
+
+// WithPlatforms configures the operating systems and CPU architectures
+// that are recognised for plugin archives.
+// When not set, DefaultPlatforms will be used.
+func WithPlatforms(platforms *Platforms) NamingConventionOption {
+	return func(c *NamingConvention) {
+		c.platforms = platforms
+	}
+}
+
+// NewNamingConvention creates a naming convention from the provided
+// templates, an error is returned if any of the templates
+// or archive formats are invalid.
+func NewNamingConvention(
+	templates *NamingTemplates,
+	opts ...NamingConventionOption,
+) (*NamingConvention, error) {
+	repo, err := parseNameTemplate(
+		templates.Repo,
+		[]string{placeholderOwner, placeholderType, placeholderPlugin},
+		[]string{placeholderPlugin},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository name template: %w", err)
+	}
+
+	assetPlaceholders := []string{
+		placeholderOwner,
+		placeholderType,
+		placeholderPlugin,
+		placeholderRepo,
+		placeholderVersion,
+	}
+	archive, err := parseNameTemplate(
+		templates.Archive,
+		append(slices.Clone(assetPlaceholders), placeholderOS, placeholderArch),
+		[]string{placeholderVersion, placeholderOS, placeholderArch},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive name template: %w", err)
+	}
+
+	checksums, err := parseNameTemplate(
+		templates.Checksums,
+		assetPlaceholders,
+		[]string{placeholderVersion},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksums name template: %w", err)
+	}
+
+	// The plugin type for release assets is derived from the repository
+	// name, so it can only be used when it is part of the repository name.
+	if !repo.hasPlaceholder(placeholderType) {
+		if archive.hasPlaceholder(placeholderType) {
+			return nil, fmt.Errorf(
+				"invalid archive name template: %q can only contain the {type} placeholder "+
+					"when the repository name template contains the {type} placeholder",
+				templates.Archive,
+			)
+		}
+
+		if checksums.hasPlaceholder(placeholderType) {
+			return nil, fmt.Errorf(
+				"invalid checksums name template: %q can only contain the {type} placeholder "+
+					"when the repository name template contains the {type} placeholder",
+				templates.Checksums,
+			)
+		}
+	}
+
+	naming := &NamingConvention{
+		repo:           repo,
+		archive:        archive,
+		checksums:      checksums,
+		archiveFormats: DefaultArchiveFormats,
+		platforms:      DefaultPlatforms(),
+	}
+
+	for _, opt := range opts {
+		opt(naming)
+	}
+
+	if err := validateArchiveFormats(naming.archiveFormats); err != nil {
+		return nil, err
+	}
+
+	naming.repoPattern = repo.pattern(
+		map[string]string{},
+		map[string]string{
+			placeholderOwner:  anyValuePattern,
+			placeholderType:   pluginTypePattern(),
+			placeholderPlugin: ".+",
+		},
+		"",
+	)
+	naming.archivePattern = archive.pattern(
+		map[string]string{},
+		map[string]string{
+			placeholderOwner: anyValuePattern,
+			// The plugin type is empty when the repository name
+			// does not follow the naming convention.
+			placeholderType:    pluginTypePattern() + "|",
+			placeholderPlugin:  anyValuePattern,
+			placeholderRepo:    anyValuePattern,
+			placeholderVersion: semanticVersionPattern,
+			placeholderOS:      naming.platforms.os.pattern(),
+			placeholderArch:    naming.platforms.arch.pattern(),
+		},
+		archiveFormatPattern(naming.archiveFormats),
+	)
+
+	return naming, nil
+}
+
+// The pattern for placeholders with values that are
+// checked after a name has been matched.
+const anyValuePattern = ".*"
+
+func pluginTypePattern() string {
+	quoted := make([]string, len(PluginTypes))
+	for i, pluginType := range PluginTypes {
+		quoted[i] = regexp.QuoteMeta(pluginType)
+	}
+
+	return strings.Join(quoted, "|")
+}
+
+// The default naming convention is immutable,
+// so a single instance is shared.
+var defaultNamingConvention = sync.OnceValue(func() *NamingConvention {
+	naming, err := NewNamingConvention(&NamingTemplates{
+		Repo:      DefaultRepoNameTemplate,
+		Archive:   DefaultArchiveNameTemplate,
+		Checksums: DefaultChecksumsNameTemplate,
+	})
+	if err != nil {
+		// The default templates are always valid.
+		panic(err)
+	}
+
+	return naming
+})
+
+// DefaultNamingConvention returns the naming convention
+// created from the default naming templates.
+func DefaultNamingConvention() *NamingConvention {
+	return defaultNamingConvention()
+}
+
+// RepoName generates the repository name for a plugin
+// based on the owner, plugin name and plugin type.
+func (c *NamingConvention) RepoName(
+	owner string,
+	pluginName string,
+	pluginType string,
+) string {
+	return c.repo.render(map[string]string{
+		placeholderOwner:  owner,
+		placeholderType:   pluginType,
+		placeholderPlugin: pluginName,
+	})
+}
+
+// CandidateRepoNames returns the repository names that a plugin
+// can be published from in the order that they should be searched for.
+// When a plugin type is provided, only the repository name for the
+// plugin type is returned, otherwise repository names for all plugin
+// types are returned.
+// When the repository name template does not contain the plugin type,
+// a single repository name is returned.
+func (c *NamingConvention) CandidateRepoNames(
+	owner string,
+	pluginName string,
+	pluginType string,
+) []string {
+	pluginTypes := PluginTypes
+	if pluginType != "" {
+		pluginTypes = []string{pluginType}
+	}
+
+	repoNames := []string{}
+	for _, pluginType := range pluginTypes {
+		repoName := c.RepoName(owner, pluginName, pluginType)
+		if !slices.Contains(repoNames, repoName) {
+			repoNames = append(repoNames, repoName)
+		}
+	}
+
+	return repoNames
+}
+
+// ParseRepoName extracts the plugin name and plugin type from a plugin
+// repository name, this is the inverse of RepoName.
+// The plugin type will be empty when the repository name template
+// does not contain the plugin type.
+// The last return value will be false if the repository name
+// does not follow the naming convention for plugin repositories.
+func (c *NamingConvention) ParseRepoName(
+	owner string,
+	repoName string,
+) (string, string, bool) {
+	matches := c.repoPattern.FindStringSubmatch(repoName)
+	if matches == nil {
+		return "", "", false
+	}
+
+	captured := capturedValues(c.repoPattern, matches)
+	if !c.repo.hasPlaceholder(placeholderOwner) || captured[placeholderOwner] == owner {
+		return captured[placeholderPlugin], captured[placeholderType], true
+	}
+
+	// The owner and plugin name can be split in more than one way when
+	// names contain the text that separates them in the template,
+	// so the repository name is matched against a pattern for the owner.
+	return c.parseRepoNameForOwner(owner, repoName)
+}
+
+func (c *NamingConvention) parseRepoNameForOwner(
+	owner string,
+	repoName string,
+) (string, string, bool) {
+	pluginTypes := PluginTypes
+	if !c.repo.hasPlaceholder(placeholderType) {
+		pluginTypes = []string{""}
+	}
+
+	for _, pluginType := range pluginTypes {
+		pattern := c.repo.pattern(
+			map[string]string{
+				placeholderOwner: owner,
+				placeholderType:  pluginType,
+			},
+			map[string]string{
+				placeholderPlugin: ".+",
+			},
+			"",
+		)
+		matches := pattern.FindStringSubmatch(repoName)
+		if matches != nil {
+			return matches[pattern.SubexpIndex(placeholderPlugin)], pluginType, true
+		}
+	}
+
+	return "", "", false
+}
+
+// AssetNameParams holds the values used to derive
+// the names of release assets for a plugin version.
+type AssetNameParams struct {
+	Owner      string
+	Repository string
+	// Version is the plugin version without the "v" prefix.
+	Version string
+	OS      string
+	Arch    string
+}
+
+// ArchiveName returns the file name of the plugin archive
+// for a plugin version and platform in the provided archive format.
+func (c *NamingConvention) ArchiveName(params *AssetNameParams, format string) string {
+	return c.repoAssetNames(params.Owner, params.Repository).
+		archiveName(params.Version, params.OS, params.Arch, format)
+}
+
+// Platforms returns the operating systems and CPU architectures
+// that are recognised for plugin archives.
+func (c *NamingConvention) Platforms() *Platforms {
+	return c.platforms
+}
+
+// ArchiveFormats returns the archive formats that are recognised
+// for plugin archives in order of preference.
+func (c *NamingConvention) ArchiveFormats() []string {
+	return c.archiveFormats
+}
+
+// ChecksumsName returns the file name of the SHA256 checksums
+// file for a plugin version.
+func (c *NamingConvention) ChecksumsName(params *AssetNameParams) string {
+	return c.repoAssetNames(params.Owner, params.Repository).checksumsName(params.Version)
+}
+
+// ChecksumsSignatureName returns the file name of the signature
+// for the SHA256 checksums file of a plugin version.
+func (c *NamingConvention) ChecksumsSignatureName(params *AssetNameParams) string {
+	return c.repoAssetNames(params.Owner, params.Repository).checksumsSignatureName(params.Version)
+}
+
+// repoAssetNames derives the names of release assets for a plugin
+// repository, the repository name is parsed once when this is created
+// so it should be reused for all the assets of a request.
+type repoAssetNames struct {
+	naming *NamingConvention
+	values map[string]string
+}
+
+func (c *NamingConvention) repoAssetNames(owner string, repository string) *repoAssetNames {
+	pluginName, pluginType, _ := c.ParseRepoName(owner, repository)
+	return &repoAssetNames{
+		naming: c,
+		values: map[string]string{
+			placeholderOwner:  owner,
+			placeholderType:   pluginType,
+			placeholderPlugin: pluginName,
+			placeholderRepo:   repository,
+		},
+	}
+}
+
+func (n *repoAssetNames) archiveName(version string, os string, arch string, format string) string {
+	return n.naming.archive.render(n.assetValues(version, os, arch)) + "." + format
+}
+
+// archiveNameCandidates returns the file names that the plugin archive for
+// a plugin version and platform in the provided archive format can have,
+// the archive can be named with the operating system and architecture
+// or any of their aliases.
+func (n *repoAssetNames) archiveNameCandidates(
+	version string,
+	os string,
+	arch string,
+	format string,
+) []string {
+	candidates := []string{}
+	for _, osSpelling := range n.naming.platforms.os.spellings(os) {
+		for _, archSpelling := range n.naming.platforms.arch.spellings(arch) {
+			candidates = append(
+				candidates,
+				n.archiveName(version, osSpelling, archSpelling, format),
+			)
+		}
+	}
+
+	return candidates
+}
+
+func (n *repoAssetNames) checksumsName(version string) string {
+	return n.naming.checksums.render(n.assetValues(version, "", ""))
+}
+
+func (n *repoAssetNames) checksumsSignatureName(version string) string {
+	return n.checksumsName(version) + signatureExtension
+}
+
+// matchArchive determines whether the provided asset name is the name of
+// a plugin archive for the plugin version for any supported platform and
+// recognised archive format, returning the OS and architecture as they
+// appear in the name.
+func (n *repoAssetNames) matchArchive(version string, name string) (string, string, bool) {
+	matches := n.naming.archivePattern.FindStringSubmatch(name)
+	if matches == nil {
+		return "", "", false
+	}
+
+	values := n.assetValues(version, "", "")
+	captured := capturedValues(n.naming.archivePattern, matches)
+	if n.matchesValues(captured, values) {
+		return captured[placeholderOS], captured[placeholderArch], true
+	}
+
+	// The values in the name can be split in more than one way when they
+	// contain the text that separates them in the template, so the name
+	// is matched against a pattern for the values.
+	pattern := n.naming.archive.pattern(
+		values,
+		map[string]string{
+			placeholderOS:   n.naming.platforms.os.pattern(),
+			placeholderArch: n.naming.platforms.arch.pattern(),
+		},
+		archiveFormatPattern(n.naming.archiveFormats),
+	)
+	matches = pattern.FindStringSubmatch(name)
+	if matches == nil {
+		return "", "", false
+	}
+
+	return matches[pattern.SubexpIndex(placeholderOS)],
+		matches[pattern.SubexpIndex(placeholderArch)],
+		true
+}
+
+func (n *repoAssetNames) matchesValues(captured map[string]string, values map[string]string) bool {
+	for placeholder, value := range values {
+		if n.naming.archive.hasPlaceholder(placeholder) && captured[placeholder] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (n *repoAssetNames) assetValues(version string, os string, arch string) map[string]string {
+	values := map[string]string{
+		placeholderVersion: version,
+	}
+	for placeholder, value := range n.values {
+		values[placeholder] = value
+	}
+	if os != "" {
+		values[placeholderOS] = os
+	}
+	if arch != "" {
+		values[placeholderArch] = arch
+	}
+
+	return values
+}
+
+func capturedValues(pattern *regexp.Regexp, matches []string) map[string]string {
+	values := map[string]string{}
+	for i, name := range pattern.SubexpNames() {
+		if name != "" {
+			values[name] = matches[i]
+		}
+	}
+
+	return values
+}
+
+func namingOrDefault(naming *NamingConvention) *NamingConvention {
+	if naming == nil {
+		return DefaultNamingConvention()
+	}
+
+	return naming
+}
+
+type nameTemplate struct {
+	segments []*templateSegment
+}
+
+type templateSegment struct {
+	text          string
+	isPlaceholder bool
+}
+
+func parseNameTemplate(
+	template string,
+	allowed []string,
+	required []string,
+) (*nameTemplate, error) {
+	if template == "" {
+		return nil, fmt.Errorf("template must not be empty")
+	}
+
+	segments := []*templateSegment{}
+	placeholders := []string{}
+	remaining := template
+	for remaining != "" {
+		start := strings.IndexAny(remaining, "{}")
+		if start == -1 {
+			segments = append(segments, &templateSegment{text: remaining})
+			break
+		}
+
+		if remaining[start] == '}' {
+			return nil, fmt.Errorf("%q contains an unexpected \"}\"", template)
+		}
+
+		if start > 0 {
+			segments = append(segments, &templateSegment{text: remaining[:start]})
+		}
+
+		length := strings.IndexAny(remaining[start+1:], "{}")
+		if length == -1 || remaining[start+1+length] != '}' {
+			return nil, fmt.Errorf("%q contains an unclosed placeholder", template)
+		}
+
+		placeholder := remaining[start+1 : start+1+length]
+		if !slices.Contains(allowed, placeholder) {
+			return nil, fmt.Errorf(
+				"%q contains the unsupported placeholder {%s}, supported placeholders are: %s",
+				template,
+				placeholder,
+				formatPlaceholders(allowed),
+			)
+		}
+
+		if slices.Contains(placeholders, placeholder) {
+			return nil, fmt.Errorf(
+				"%q contains the {%s} placeholder more than once",
+				template,
+				placeholder,
+			)
+		}
+
+		placeholders = append(placeholders, placeholder)
+		segments = append(segments, &templateSegment{text: placeholder, isPlaceholder: true})
+		remaining = remaining[start+2+length:]
+	}
+
+	for _, placeholder := range required {
+		if !slices.Contains(placeholders, placeholder) {
+			return nil, fmt.Errorf(
+				"%q must contain the {%s} placeholder",
+				template,
+				placeholder,
+			)
+		}
+	}
+
+	return &nameTemplate{segments: segments}, nil
+}
+
+func (t *nameTemplate) hasPlaceholder(placeholder string) bool {
+	return slices.ContainsFunc(t.segments, func(segment *templateSegment) bool {
+		return segment.isPlaceholder && segment.text == placeholder
+	})
+}
+
+func (t *nameTemplate) render(values map[string]string) string {
+	var name strings.Builder
+	for _, segment := range t.segments {
+		if segment.isPlaceholder {
+			name.WriteString(values[segment.text])
+		} else {
+			name.WriteString(segment.text)
+		}
+	}
+
+	return name.String()
+}
+
+// pattern creates a regular expression that matches names rendered from
+// the template followed by the suffix pattern, placeholders with a capture pattern
+// are captured in named groups, all other placeholders must match
+// the provided values.
+func (t *nameTemplate) pattern(
+	values map[string]string,
+	captures map[string]string,
+	suffixPattern string,
+) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, segment := range t.segments {
+		if !segment.isPlaceholder {
+			pattern.WriteString(regexp.QuoteMeta(segment.text))
+			continue
+		}
+
+		capture, isCaptured := captures[segment.text]
+		if isCaptured {
+			pattern.WriteString(fmt.Sprintf("(?P<%s>%s)", segment.text, capture))
+		} else {
+			pattern.WriteString(regexp.QuoteMeta(values[segment.text]))
+		}
+	}
+	pattern.WriteString(suffixPattern)
+	pattern.WriteString("$")
+
+	// Literal text is escaped and capture and suffix patterns are fixed,
+	// so the pattern is always valid.
+	return regexp.MustCompile(pattern.String())
+}
+
+func formatPlaceholders(placeholders []string) string {
+	formatted := make([]string, len(placeholders))
+	for i, placeholder := range placeholders {
+		formatted[i] = "{" + placeholder + "}"
+	}
+
+	return strings.Join(formatted, ", ")
+}
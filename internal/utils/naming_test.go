@@ -0,0 +1,387 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamingConventionTestSuite struct {
+	suite.Suite
+}
+
+func (s *NamingConventionTestSuite) Test_derives_names_from_default_templates() {
+	naming := DefaultNamingConvention()
+	s.Assert().Equal(
+		"bluelink-provider-aws",
+		naming.RepoName("newstack-cloud", "aws", PluginTypeProvider),
+	)
+	s.Assert().Equal(
+		[]string{"bluelink-provider-aws", "bluelink-transformer-aws"},
+		naming.CandidateRepoNames("newstack-cloud", "aws", ""),
+	)
+	s.Assert().Equal(
+		[]string{"bluelink-transformer-aws"},
+		naming.CandidateRepoNames("newstack-cloud", "aws", PluginTypeTransformer),
+	)
+
+	params := &AssetNameParams{
+		Owner:      "newstack-cloud",
+		Repository: "bluelink-provider-aws",
+		Version:    "1.0.0",
+		OS:         "linux",
+		Arch:       "amd64",
+	}
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_linux_amd64.zip", naming.ArchiveName(params, ArchiveFormatZip))
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_SHA256SUMS", naming.ChecksumsName(params))
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_SHA256SUMS.sig", naming.ChecksumsSignatureName(params))
+}
+
+func (s *NamingConventionTestSuite) Test_derives_names_from_custom_templates() {
+	naming, err := NewNamingConvention(&NamingTemplates{
+		Repo:      "bluelink-plugin-{plugin}-internal",
+		Archive:   "{plugin}-v{version}.{os}-{arch}",
+		Checksums: "{owner}-{plugin}-{version}-checksums.txt",
+	})
+	s.Require().NoError(err)
+
+	s.Assert().Equal(
+		[]string{"bluelink-plugin-aws-internal"},
+		naming.CandidateRepoNames("newstack-cloud", "aws", ""),
+	)
+
+	pluginName, pluginType, ok := naming.ParseRepoName("newstack-cloud", "bluelink-plugin-aws-internal")
+	s.Assert().True(ok)
+	s.Assert().Equal("aws", pluginName)
+	s.Assert().Empty(pluginType)
+
+	_, _, ok = naming.ParseRepoName("newstack-cloud", "bluelink-provider-aws")
+	s.Assert().False(ok)
+
+	params := &AssetNameParams{
+		Owner:      "newstack-cloud",
+		Repository: "bluelink-plugin-aws-internal",
+		Version:    "1.0.0",
+		OS:         "darwin",
+		Arch:       "arm64",
+	}
+	s.Assert().Equal("aws-v1.0.0.darwin-arm64.zip", naming.ArchiveName(params, ArchiveFormatZip))
+	s.Assert().Equal("newstack-cloud-aws-1.0.0-checksums.txt", naming.ChecksumsName(params))
+}
+
+func (s *NamingConventionTestSuite) Test_extracts_supported_platforms_with_custom_archive_names() {
+	naming, err := NewNamingConvention(&NamingTemplates{
+		Repo:      "bluelink-plugin-{plugin}-internal",
+		Archive:   "{plugin}-v{version}.{os}-{arch}",
+		Checksums: DefaultChecksumsNameTemplate,
+	})
+	s.Require().NoError(err)
+
+	platforms := extractSupportedPlatforms(
+		naming.repoAssetNames("newstack-cloud", "bluelink-plugin-aws-internal"),
+		&github.RepositoryRelease{
+			TagName: github.Ptr("v1.0.0"),
+			Assets: []*github.ReleaseAsset{
+				{Name: github.Ptr("aws-v1.0.0.darwin-arm64.zip")},
+				{Name: github.Ptr("aws-v1.0.0.linux-amd64.zip")},
+				// Archives for other versions and assets that do not follow
+				// the archive naming convention are ignored.
+				{Name: github.Ptr("aws-v0.9.0.linux-amd64.zip")},
+				{Name: github.Ptr("bluelink-plugin-aws-internal_1.0.0_linux_amd64.zip")},
+			},
+		},
+	)
+	s.Assert().Equal(
+		[]*types.PluginVersionPlatform{
+			{OS: "darwin", Arch: "arm64"},
+			{OS: "linux", Arch: "amd64"},
+		},
+		platforms,
+	)
+}
+
+func (s *NamingConventionTestSuite) Test_extracts_each_platform_once_for_multiple_archive_formats() {
+	platforms := extractSupportedPlatforms(
+		DefaultNamingConvention().repoAssetNames("newstack-cloud", "bluelink-provider-aws"),
+		&github.RepositoryRelease{
+			TagName: github.Ptr("v1.0.0"),
+			Assets: []*github.ReleaseAsset{
+				{Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_amd64.tar.gz")},
+				{Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_amd64.zip")},
+				{Name: github.Ptr("bluelink-provider-aws_1.0.0_windows_amd64.zip")},
+				// Archive formats that are not recognised are ignored.
+				{Name: github.Ptr("bluelink-provider-aws_1.0.0_darwin_arm64.tar.xz")},
+			},
+		},
+	)
+	s.Assert().Equal(
+		[]*types.PluginVersionPlatform{
+			{OS: "linux", Arch: "amd64"},
+			{OS: "windows", Arch: "amd64"},
+		},
+		platforms,
+	)
+}
+
+func (s *NamingConventionTestSuite) Test_attaches_archive_in_preferred_format() {
+	naming, err := NewNamingConvention(
+		&NamingTemplates{
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		WithArchiveFormats([]string{ArchiveFormatTarGz, ArchiveFormatZip}),
+	)
+	s.Require().NoError(err)
+
+	release := &github.RepositoryRelease{
+		TagName: github.Ptr("v1.0.0"),
+		Assets: []*github.ReleaseAsset{
+			{
+				Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_amd64.zip"),
+				URL:  github.Ptr("https://example.com/assets/1"),
+			},
+			{
+				Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_amd64.tar.gz"),
+				URL:  github.Ptr("https://example.com/assets/2"),
+			},
+			{
+				Name: github.Ptr("bluelink-provider-aws_1.0.0_windows_amd64.zip"),
+				URL:  github.Ptr("https://example.com/assets/3"),
+			},
+		},
+	}
+
+	linuxPackage := &types.PluginVersionPackage{OS: "linux", Arch: "amd64"}
+	attachReleaseFileInfo(
+		naming,
+		&AssetNameParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-aws",
+			Version:    "1.0.0",
+			OS:         "linux",
+			Arch:       "amd64",
+		},
+		release,
+		linuxPackage,
+	)
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_linux_amd64.tar.gz", linuxPackage.Filename)
+	s.Assert().Equal(ArchiveFormatTarGz, linuxPackage.ArchiveFormat)
+	s.Assert().Equal("https://example.com/assets/2", linuxPackage.DownloadURL)
+
+	// Less preferred formats are used when the preferred format
+	// is not available for a platform.
+	windowsPackage := &types.PluginVersionPackage{OS: "windows", Arch: "amd64"}
+	attachReleaseFileInfo(
+		naming,
+		&AssetNameParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-aws",
+			Version:    "1.0.0",
+			OS:         "windows",
+			Arch:       "amd64",
+		},
+		release,
+		windowsPackage,
+	)
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_windows_amd64.zip", windowsPackage.Filename)
+	s.Assert().Equal(ArchiveFormatZip, windowsPackage.ArchiveFormat)
+}
+
+func (s *NamingConventionTestSuite) Test_recognises_configured_platforms_and_aliases() {
+	platforms, err := NewPlatforms(&PlatformsConfig{
+		OperatingSystems:    []string{"linux", "netbsd"},
+		Architectures:       []string{"amd64", "arm64", "s390x"},
+		ArchitectureAliases: DefaultArchitectureAliases,
+	})
+	s.Require().NoError(err)
+
+	naming, err := NewNamingConvention(
+		&NamingTemplates{
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		WithPlatforms(platforms),
+	)
+	s.Require().NoError(err)
+
+	release := &github.RepositoryRelease{
+		TagName: github.Ptr("v1.0.0"),
+		Assets: []*github.ReleaseAsset{
+			{
+				Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_x86_64.zip"),
+				URL:  github.Ptr("https://example.com/assets/1"),
+			},
+			{Name: github.Ptr("bluelink-provider-aws_1.0.0_linux_s390x.zip")},
+			{Name: github.Ptr("bluelink-provider-aws_1.0.0_netbsd_aarch64.zip")},
+			// Platforms that are not configured are ignored.
+			{Name: github.Ptr("bluelink-provider-aws_1.0.0_darwin_arm64.zip")},
+		},
+	}
+
+	platformsList := extractSupportedPlatforms(
+		naming.repoAssetNames("newstack-cloud", "bluelink-provider-aws"),
+		release,
+	)
+	s.Assert().Equal(
+		[]*types.PluginVersionPlatform{
+			{OS: "linux", Arch: "amd64"},
+			{OS: "linux", Arch: "s390x"},
+			{OS: "netbsd", Arch: "arm64"},
+		},
+		platformsList,
+	)
+
+	// Archives named with an alias are found for the recognised name.
+	versionPackage := &types.PluginVersionPackage{OS: "linux", Arch: "amd64"}
+	attachReleaseFileInfo(
+		naming,
+		&AssetNameParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-aws",
+			Version:    "1.0.0",
+			OS:         "linux",
+			Arch:       "amd64",
+		},
+		release,
+		versionPackage,
+	)
+	s.Assert().Equal("bluelink-provider-aws_1.0.0_linux_x86_64.zip", versionPackage.Filename)
+	s.Assert().Equal("https://example.com/assets/1", versionPackage.DownloadURL)
+}
+
+func (s *NamingConventionTestSuite) Test_parses_repo_names_with_owner_and_plugin_containing_separators() {
+	naming, err := NewNamingConvention(&NamingTemplates{
+		Repo:      "{owner}-{plugin}",
+		Archive:   DefaultArchiveNameTemplate,
+		Checksums: DefaultChecksumsNameTemplate,
+	})
+	s.Require().NoError(err)
+
+	pluginName, _, ok := naming.ParseRepoName("acme", "acme-aws-tools")
+	s.Require().True(ok)
+	s.Assert().Equal("aws-tools", pluginName)
+
+	pluginName, _, ok = naming.ParseRepoName("my-org", "my-org-aws")
+	s.Require().True(ok)
+	s.Assert().Equal("aws", pluginName)
+
+	_, _, ok = naming.ParseRepoName("other-org", "my-org-aws")
+	s.Assert().False(ok)
+}
+
+func (s *NamingConventionTestSuite) Test_extracts_supported_platforms_for_prerelease_with_separators_in_names() {
+	naming, err := NewNamingConvention(&NamingTemplates{
+		Repo:      DefaultRepoNameTemplate,
+		Archive:   "{plugin}-{version}-{os}-{arch}",
+		Checksums: DefaultChecksumsNameTemplate,
+	})
+	s.Require().NoError(err)
+
+	platforms := extractSupportedPlatforms(
+		naming.repoAssetNames("newstack-cloud", "bluelink-provider-aws-tools"),
+		&github.RepositoryRelease{
+			TagName: github.Ptr("v1.0.0-beta-1"),
+			Assets: []*github.ReleaseAsset{
+				{Name: github.Ptr("aws-tools-1.0.0-beta-1-linux-amd64.zip")},
+				{Name: github.Ptr("aws-tools-1.0.0-beta-1-darwin-arm64.tar.gz")},
+				// Archives for other plugins and versions are ignored.
+				{Name: github.Ptr("aws-1.0.0-beta-1-windows-amd64.zip")},
+				{Name: github.Ptr("aws-tools-1.0.0-beta-2-windows-amd64.zip")},
+			},
+		},
+	)
+	s.Assert().Equal(
+		[]*types.PluginVersionPlatform{
+			{OS: "linux", Arch: "amd64"},
+			{OS: "darwin", Arch: "arm64"},
+		},
+		platforms,
+	)
+}
+
+func (s *NamingConventionTestSuite) Test_fails_for_invalid_archive_formats() {
+	testCases := map[string][]string{
+		"no formats":         {},
+		"unsupported format": {ArchiveFormatZip, "rar"},
+		"duplicate format":   {ArchiveFormatZip, ArchiveFormatTarGz, ArchiveFormatZip},
+	}
+
+	for name, formats := range testCases {
+		s.Run(name, func() {
+			_, err := NewNamingConvention(
+				&NamingTemplates{
+					Repo:      DefaultRepoNameTemplate,
+					Archive:   DefaultArchiveNameTemplate,
+					Checksums: DefaultChecksumsNameTemplate,
+				},
+				WithArchiveFormats(formats),
+			)
+			s.Assert().Error(err)
+		})
+	}
+}
+
+func (s *NamingConventionTestSuite) Test_fails_for_invalid_templates() {
+	testCases := map[string]*NamingTemplates{
+		"repo template without plugin placeholder": {
+			Repo:      "bluelink-{type}",
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"unsupported placeholder": {
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   "{repo}_{version}_{os}_{arch}_{variant}",
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"unclosed placeholder": {
+			Repo:      "bluelink-{type-{plugin}",
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"unexpected closing brace": {
+			Repo:      "bluelink-type}-{plugin}",
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"duplicate placeholder": {
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   "{repo}_{version}_{os}_{arch}_{os}",
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"archive template without platform placeholders": {
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   "{repo}_{version}",
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"checksums template with platform placeholder": {
+			Repo:      DefaultRepoNameTemplate,
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: "{repo}_{version}_{os}_SHA256SUMS",
+		},
+		"type placeholder not in repo template": {
+			Repo:      "bluelink-plugin-{plugin}",
+			Archive:   "{type}_{plugin}_{version}_{os}_{arch}",
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+		"empty template": {
+			Repo:      "",
+			Archive:   DefaultArchiveNameTemplate,
+			Checksums: DefaultChecksumsNameTemplate,
+		},
+	}
+
+	for name, templates := range testCases {
+		s.Run(name, func() {
+			_, err := NewNamingConvention(templates)
+			s.Assert().Error(err)
+		})
+	}
+}
+
+func TestNamingConventionTestSuite(t *testing.T) {
+	suite.Run(t, new(NamingConventionTestSuite))
+}
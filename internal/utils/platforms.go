@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultOperatingSystems holds the operating systems that are
+// recognised for plugin archives when they are not configured.
+var DefaultOperatingSystems = []string{
+	"linux",
+	"windows",
+	"darwin",
+	"freebsd",
+}
+
+// DefaultArchitectures holds the CPU architectures that are
+// recognised for plugin archives when they are not configured.
+var DefaultArchitectures = []string{
+	"amd64",
+	"arm64",
+	"arm",
+	"386",
+}
+
+// DefaultArchitectureAliases holds the aliases for CPU architectures
+// that are recognised when they are not configured.
+var DefaultArchitectureAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+}
+
+var platformNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// PlatformsConfig holds the configuration for the operating systems
+// and CPU architectures that are recognised for plugin archives.
+type PlatformsConfig struct {
+	OperatingSystems []string
+	Architectures    []string
+	// OperatingSystemAliases maps alternative names for operating systems
+	// to the name of an operating system in OperatingSystems.
+	OperatingSystemAliases map[string]string
+	// ArchitectureAliases maps alternative names for CPU architectures
+	// to the name of an architecture in Architectures (e.g. "x86_64" to "amd64").
+	ArchitectureAliases map[string]string
+}
+
+// Platforms holds the operating systems and CPU architectures that are
+// recognised for plugin archives.
+// Aliases are recognised in the names of release assets and in requests
+// for package information, they are always reported with the name of
+// the operating system or architecture that they are an alias for.
+type Platforms struct {
+	os   *platformSet
+	arch *platformSet
+}
+
+// NewPlatforms creates a set of recognised platforms from the provided
+// configuration, an error is returned if the configuration is invalid.
+func NewPlatforms(config *PlatformsConfig) (*Platforms, error) {
+	os, err := newPlatformSet(
+		"operating system",
+		config.OperatingSystems,
+		config.OperatingSystemAliases,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	arch, err := newPlatformSet(
+		"architecture",
+		config.Architectures,
+		config.ArchitectureAliases,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Platforms{
+		os:   os,
+		arch: arch,
+	}, nil
+}
+
+// DefaultPlatforms returns the platforms created from the default
+// operating systems, architectures and architecture aliases.
+func DefaultPlatforms() *Platforms {
+	platforms, err := NewPlatforms(&PlatformsConfig{
+		OperatingSystems:    DefaultOperatingSystems,
+		Architectures:       DefaultArchitectures,
+		ArchitectureAliases: DefaultArchitectureAliases,
+	})
+	if err != nil {
+		// The default platforms are always valid.
+		panic(err)
+	}
+
+	return platforms
+}
+
+// OS returns the name of the recognised operating system for the
+// provided name or alias, the second return value will be false if the
+// operating system is not recognised.
+func (p *Platforms) OS(name string) (string, bool) {
+	return p.os.canonical(name)
+}
+
+// Arch returns the name of the recognised CPU architecture for the
+// provided name or alias, the second return value will be false if the
+// architecture is not recognised.
+func (p *Platforms) Arch(name string) (string, bool) {
+	return p.arch.canonical(name)
+}
+
+type platformSet struct {
+	names   []string
+	aliases map[string]string
+}
+
+func newPlatformSet(
+	kind string,
+	names []string,
+	aliases map[string]string,
+) (*platformSet, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one %s must be provided", kind)
+	}
+
+	for i, name := range names {
+		if !platformNamePattern.MatchString(name) {
+			return nil, fmt.Errorf(
+				"%s %q must only contain lowercase letters, numbers and underscores",
+				kind,
+				name,
+			)
+		}
+
+		if slices.Contains(names[:i], name) {
+			return nil, fmt.Errorf("%s %q is provided more than once", kind, name)
+		}
+	}
+
+	for alias, name := range aliases {
+		if !platformNamePattern.MatchString(alias) {
+			return nil, fmt.Errorf(
+				"%s alias %q must only contain lowercase letters, numbers and underscores",
+				kind,
+				alias,
+			)
+		}
+
+		if slices.Contains(names, alias) {
+			return nil, fmt.Errorf(
+				"%s alias %q can not be the same as a recognised %s",
+				kind,
+				alias,
+				kind,
+			)
+		}
+
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf(
+				"%s alias %q must be an alias for a recognised %s, %q is not recognised",
+				kind,
+				alias,
+				kind,
+				name,
+			)
+		}
+	}
+
+	return &platformSet{
+		names:   names,
+		aliases: aliases,
+	}, nil
+}
+
+func (s *platformSet) canonical(name string) (string, bool) {
+	if slices.Contains(s.names, name) {
+		return name, true
+	}
+
+	canonicalName, isAlias := s.aliases[name]
+	return canonicalName, isAlias
+}
+
+// spellings returns the name along with all the aliases for the name
+// in a deterministic order, the name is always first.
+func (s *platformSet) spellings(name string) []string {
+	aliases := []string{}
+	for alias, aliasFor := range s.aliases {
+		if aliasFor == name {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	return append([]string{name}, aliases...)
+}
+
+// pattern returns a regular expression pattern that matches
+// any of the names or aliases in the set.
+func (s *platformSet) pattern() string {
+	allNames := slices.Clone(s.names)
+	for alias := range s.aliases {
+		allNames = append(allNames, alias)
+	}
+	// Names are sorted for a deterministic pattern, names only
+	// contain characters that do not need to be escaped.
+	sort.Strings(allNames)
+
+	return strings.Join(allNames, "|")
+}
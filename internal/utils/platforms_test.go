@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PlatformsTestSuite struct {
+	suite.Suite
+}
+
+func (s *PlatformsTestSuite) Test_resolves_names_and_aliases() {
+	platforms, err := NewPlatforms(&PlatformsConfig{
+		OperatingSystems: []string{"linux", "openbsd"},
+		Architectures:    []string{"amd64", "riscv64"},
+		OperatingSystemAliases: map[string]string{
+			"gnu_linux": "linux",
+		},
+		ArchitectureAliases: map[string]string{
+			"x86_64": "amd64",
+		},
+	})
+	s.Require().NoError(err)
+
+	os, ok := platforms.OS("openbsd")
+	s.Assert().True(ok)
+	s.Assert().Equal("openbsd", os)
+
+	os, ok = platforms.OS("gnu_linux")
+	s.Assert().True(ok)
+	s.Assert().Equal("linux", os)
+
+	_, ok = platforms.OS("windows")
+	s.Assert().False(ok)
+
+	arch, ok := platforms.Arch("x86_64")
+	s.Assert().True(ok)
+	s.Assert().Equal("amd64", arch)
+
+	arch, ok = platforms.Arch("riscv64")
+	s.Assert().True(ok)
+	s.Assert().Equal("riscv64", arch)
+
+	_, ok = platforms.Arch("arm64")
+	s.Assert().False(ok)
+}
+
+func (s *PlatformsTestSuite) Test_fails_for_invalid_config() {
+	testCases := map[string]*PlatformsConfig{
+		"no operating systems": {
+			Architectures: DefaultArchitectures,
+		},
+		"no architectures": {
+			OperatingSystems: DefaultOperatingSystems,
+		},
+		"invalid name": {
+			OperatingSystems: []string{"linux", "Mac OS"},
+			Architectures:    DefaultArchitectures,
+		},
+		"duplicate name": {
+			OperatingSystems: DefaultOperatingSystems,
+			Architectures:    []string{"amd64", "amd64"},
+		},
+		"alias for unrecognised name": {
+			OperatingSystems:    DefaultOperatingSystems,
+			Architectures:       DefaultArchitectures,
+			ArchitectureAliases: map[string]string{"ppc64el": "ppc64le"},
+		},
+		"alias that is a recognised name": {
+			OperatingSystems:    DefaultOperatingSystems,
+			Architectures:       DefaultArchitectures,
+			ArchitectureAliases: map[string]string{"arm": "arm64"},
+		},
+	}
+
+	for name, config := range testCases {
+		s.Run(name, func() {
+			_, err := NewPlatforms(config)
+			s.Assert().Error(err)
+		})
+	}
+}
+
+func TestPlatformsTestSuite(t *testing.T) {
+	suite.Run(t, new(PlatformsTestSuite))
+}
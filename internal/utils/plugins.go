@@ -8,63 +8,204 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v70/github"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/httputils"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/signingkeys"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// DefaultRegistryInfoFetchConcurrency is the default maximum number
+	// of registry info files that will be fetched concurrently when extracting
+	// plugin versions.
+	DefaultRegistryInfoFetchConcurrency = 8
+)
+
+// ExtractPluginVersionsParams holds the parameters needed to
+// extract plugin versions from a set of GitHub releases.
+type ExtractPluginVersionsParams struct {
+	Owner      string
+	Repository string
+	Releases   []*github.RepositoryRelease
+	// ArtifactCache is an optional cache for the contents
+	// of release artifacts such as the registry info file.
+	ArtifactCache ArtifactCache
+	// Concurrency is the maximum number of registry info files
+	// to fetch at the same time.
+	// When not set, DefaultRegistryInfoFetchConcurrency will be used.
+	Concurrency int
+	// Naming is the naming convention for release assets,
+	// when not set, the default naming convention will be used.
+	Naming *NamingConvention
+	// Channel is the release channel used to filter the releases,
+	// when not set, only stable releases will be included.
+	Channel string
+	// IncludeDrafts determines whether draft releases are included,
+	// draft releases are excluded by default.
+	IncludeDrafts bool
+	// TagCommitSHAs holds the SHA of the commit that each release tag
+	// points to, keyed by tag name.
+	// When not set, the target commitish of each release is used, which
+	// is only the commit of the release tag for releases fetched from the
+	// GitHub GraphQL API.
+	TagCommitSHAs map[string]string
+}
+
 // ExtractPluginVersions extracts the plugin versions from the GitHub releases
 // and returns them in a format that is compatible with the
 // Bluelink registry protocol.
+// The registry info for each release is fetched concurrently,
+// the versions in the output are sorted in ascending order of
+// semantic version precedence.
 func ExtractPluginVersions(
 	ctx context.Context,
-	repository string,
-	releases []*github.RepositoryRelease,
+	params *ExtractPluginVersionsParams,
 	client httputils.Client,
 	token string,
 ) (*types.PluginVersions, error) {
-	versions := []*types.PluginVersion{}
-	for _, release := range releases {
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(fetchConcurrency(params.Concurrency))
+
+	// The repository name is only parsed once for the
+	// archive names of all releases.
+	assetNames := namingOrDefault(params.Naming).repoAssetNames(
+		params.Owner,
+		params.Repository,
+	)
+
+	// Each version is written to the index of the release it was
+	// extracted from to keep the output order deterministic.
+	extracted := make([]*types.PluginVersion, len(params.Releases))
+	for i, release := range params.Releases {
 		if !validTagPattern.MatchString(release.GetTagName()) {
 			// Ignore releases that are not semantic versions prefixed with "v".
 			continue
 		}
 
-		registryInfoURL := getRegistryInfoURL(release.Assets)
-		registryInfo, err := getRegistryInfoFromURL(ctx, client, registryInfoURL, token)
-		if err != nil {
-			return nil, err
+		if release.GetDraft() && !params.IncludeDrafts {
+			continue
 		}
 
-		supportedPlatforms, err := extractSupportedPlatforms(repository, release)
-		if err != nil {
-			return nil, err
+		if !inReleaseChannel(params.Channel, release) {
+			continue
+		}
+
+		if groupCtx.Err() != nil {
+			// Stop scheduling fetches when the request has been cancelled
+			// or a fetch for another release has failed.
+			break
 		}
 
-		versions = append(versions, &types.PluginVersion{
-			Version:            versionFromTag(release.GetTagName()),
-			SupportedProtocols: registryInfo.SupportedProtocols,
-			SupportedPlatforms: supportedPlatforms,
+		group.Go(func() error {
+			version, err := extractPluginVersion(
+				groupCtx,
+				params,
+				assetNames,
+				release,
+				client,
+				token,
+			)
+			if err != nil {
+				return err
+			}
+			extracted[i] = version
+			return nil
 		})
 	}
 
+	err := group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	versions := []*types.PluginVersion{}
+	for _, version := range extracted {
+		if version != nil {
+			versions = append(versions, version)
+		}
+	}
+
+	// A stable sort keeps the order of the provided releases for versions
+	// that only differ by build metadata.
+	slices.SortStableFunc(versions, func(a, b *types.PluginVersion) int {
+		return compareVersions(a.Version, b.Version)
+	})
+
 	return &types.PluginVersions{
 		Versions: versions,
 	}, nil
 }
 
-var (
-	// A regex pattern that matches semantic versioning.
-	// It matches versions like 1.0.0, 1.0.0-alpha, 1.0.0-beta, etc.
-	// We prefix it with "v" as per expected in plugin releases for the registry.
-	// The regexp is taken from the https://semver.org/ docs and modified to
-	// include the "v" prefix.
-	validTagPattern = regexp.MustCompile(
-		`^v(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`,
+func extractPluginVersion(
+	ctx context.Context,
+	params *ExtractPluginVersionsParams,
+	assetNames *repoAssetNames,
+	release *github.RepositoryRelease,
+	client httputils.Client,
+	token string,
+) (*types.PluginVersion, error) {
+	registryInfo, err := getRegistryInfo(
+		ctx,
+		client,
+		params.ArtifactCache,
+		params.Owner,
+		params.Repository,
+		release,
+		token,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	supportedPlatforms := extractSupportedPlatforms(assetNames, release)
+
+	return &types.PluginVersion{
+		Version:            versionFromTag(release.GetTagName()),
+		SupportedProtocols: registryInfo.SupportedProtocols,
+		SupportedPlatforms: supportedPlatforms,
+		Prerelease:         isPrerelease(release),
+		PublishedAt:        publishedAt(release),
+		CommitSHA:          commitSHA(release, params.TagCommitSHAs),
+		HTMLURL:            release.GetHTMLURL(),
+	}, nil
+}
+
+func publishedAt(release *github.RepositoryRelease) *time.Time {
+	if release.PublishedAt == nil {
+		// Draft releases have not been published.
+		return nil
+	}
+
+	publishedAt := release.PublishedAt.UTC()
+	return &publishedAt
+}
+
+func fetchConcurrency(concurrency int) int {
+	if concurrency <= 0 {
+		return DefaultRegistryInfoFetchConcurrency
+	}
+
+	return concurrency
+}
+
+// A regex pattern that matches semantic versioning.
+// It matches versions like 1.0.0, 1.0.0-alpha, 1.0.0-beta, etc.
+// The regexp is taken from the https://semver.org/ docs.
+const semanticVersionPattern = `(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?`
+
+var (
+	// We prefix the semantic version pattern with "v" as per expected
+	// in plugin releases for the registry.
+	validTagPattern = regexp.MustCompile(`^v` + semanticVersionPattern + `$`)
 )
 
 func versionFromTag(tag string) string {
@@ -73,83 +214,81 @@ func versionFromTag(tag string) string {
 	return strings.TrimPrefix(tag, "v")
 }
 
-var (
-	// A regex pattern that matches an archive name that dismisses
-	// the plugin name and version to extract the os and architecture.
-	platformPattern = regexp.MustCompile(
-		`^.+_(linux|windows|darwin|freebsd)_(amd64|arm64|arm|386).zip$`,
-	)
-)
-
 func extractSupportedPlatforms(
-	repository string,
+	assetNames *repoAssetNames,
 	release *github.RepositoryRelease,
-) ([]*types.PluginVersionPlatform, error) {
+) []*types.PluginVersionPlatform {
 	platforms := []*types.PluginVersionPlatform{}
 
+	// The release must have at least one archive asset that follows the
+	// archive naming convention for the plugin version, by default:
+	// <repo-name>_<version>_<os>_<arch>.<zip|tar.gz>
+	version := versionFromTag(release.GetTagName())
 	for _, asset := range release.Assets {
-		isReleaseArchive, err := checkMatchesArchiveFileName(
-			repository,
-			release.GetTagName(),
-			asset.GetName(),
-		)
-		if err != nil {
-			return nil, err
+		archiveOS, archiveArch, isArchive := assetNames.matchArchive(version, asset.GetName())
+		if !isArchive {
+			continue
 		}
 
-		if isReleaseArchive {
-			matches := platformPattern.FindStringSubmatch(asset.GetName())
-			if len(matches) == 3 {
-				platforms = append(platforms, &types.PluginVersionPlatform{
-					OS:   matches[1],
-					Arch: matches[2],
-				})
-			}
+		// Platforms are always reported with the recognised names for the
+		// operating system and architecture, even when an archive is
+		// named with an alias.
+		os, _ := assetNames.naming.Platforms().OS(archiveOS)
+		arch, _ := assetNames.naming.Platforms().Arch(archiveArch)
+		platform := &types.PluginVersionPlatform{
+			OS:   os,
+			Arch: arch,
+		}
+		// Releases can contain archives in multiple formats for a platform,
+		// each platform is only listed once.
+		isListed := slices.ContainsFunc(
+			platforms,
+			func(listed *types.PluginVersionPlatform) bool {
+				return listed.OS == platform.OS && listed.Arch == platform.Arch
+			},
+		)
+		if !isListed {
+			platforms = append(platforms, platform)
 		}
 	}
 
-	return platforms, nil
+	return platforms
 }
 
-func checkMatchesArchiveFileName(
-	repository string,
-	tagName string,
-	assetName string,
-) (bool, error) {
-	// The release must have at least one asset in the form:
-	// <repo-name>_<version>_<platform>_<arch>.zip
-	return regexp.MatchString(
-		fmt.Sprintf(
-			`^%s_%s_(linux|windows|darwin|freebsd)_(amd64|arm64|arm|386).zip$`,
-			repository,
-			strings.TrimPrefix(tagName, "v"),
-		),
-		assetName,
-	)
-}
-
-func getRegistryInfoURL(
+func getRegistryInfoAsset(
 	assets []*github.ReleaseAsset,
-) string {
+) *github.ReleaseAsset {
 	for _, asset := range assets {
 		if strings.HasSuffix(asset.GetName(), "_registry_info.json") {
-			return asset.GetURL()
+			return asset
 		}
 	}
 
-	return ""
+	return nil
 }
 
-func getRegistryInfoFromURL(
+func getRegistryInfo(
 	ctx context.Context,
 	client httputils.Client,
-	url string,
+	artifactCache ArtifactCache,
+	owner string,
+	repository string,
+	release *github.RepositoryRelease,
 	token string,
 ) (*types.PluginRegistryInfo, error) {
-	respBodyBytes, err := downloadFromGitHub(
+	registryInfoAsset := getRegistryInfoAsset(release.Assets)
+	respBodyBytes, err := fetchArtifact(
 		ctx,
 		client,
-		url,
+		artifactCache,
+		&ArtifactKey{
+			Owner:      owner,
+			Repository: repository,
+			Tag:        release.GetTagName(),
+			AssetID:    registryInfoAsset.GetID(),
+			Kind:       ArtifactKindRegistryInfo,
+		},
+		registryInfoAsset,
 		token,
 	)
 	if err != nil {
@@ -166,22 +305,24 @@ func getRegistryInfoFromURL(
 }
 
 // FindPluginRepo searches for a plugin repository in the list of
-// repositories based on the organisation and plugin name.
+// repositories based on the organisation, plugin name
+// and the naming convention for plugin repositories.
+// When a plugin type is provided, only repositories for the plugin
+// type are matched.
 // It returns the first matching repository found or nil if none is found.
 func FindPluginRepo(
 	repositories []*github.Repository,
 	organisation string,
 	pluginName string,
+	pluginType string,
+	naming *NamingConvention,
 ) *github.Repository {
+	candidateRepos := naming.CandidateRepoNames(organisation, pluginName, pluginType)
 	pluginRepo := (*github.Repository)(nil)
 	i := 0
 	for pluginRepo == nil && i < len(repositories) {
 		repo := repositories[i]
-		candidateProviderRepo := RepoName(pluginName, "provider")
-		candidateTransformerRepo := RepoName(pluginName, "transformer")
-
-		if repo.GetName() == candidateProviderRepo ||
-			repo.GetName() == candidateTransformerRepo {
+		if slices.Contains(candidateRepos, repo.GetName()) {
 			pluginRepo = repo
 		}
 
@@ -191,25 +332,108 @@ func FindPluginRepo(
 	return pluginRepo
 }
 
-// RepoName generates the repository name for a plugin
-// based on the plugin name and type.
-func RepoName(pluginName string, pluginType string) string {
-	return fmt.Sprintf(
-		"bluelink-%s-%s",
-		pluginType,
-		pluginName,
-	)
+const (
+	// PluginTypeProvider is the plugin type for provider plugins.
+	PluginTypeProvider = "provider"
+	// PluginTypeTransformer is the plugin type for transformer plugins.
+	PluginTypeTransformer = "transformer"
+)
+
+// PluginTypes holds the plugin types in the order that repositories
+// are searched for when resolving the repository for a plugin.
+var PluginTypes = []string{
+	PluginTypeProvider,
+	PluginTypeTransformer,
 }
 
 // ExtractPluginVersionPackageParams holds the parameters needed to
 // extract the plugin version package from a GitHub release.
 type ExtractPluginVersionPackageParams struct {
+	Owner                 string
 	Repository            string
 	Release               *github.RepositoryRelease
 	Version               string
 	OS                    string
 	Arch                  string
 	SigningKeysSerialised string
+	// ArtifactCache is an optional cache for the contents
+	// of release artifacts such as the registry info
+	// and SHA256SUMS files.
+	ArtifactCache ArtifactCache
+	// Naming is the naming convention for release assets,
+	// when not set, the default naming convention will be used.
+	Naming *NamingConvention
+}
+
+// FindLatestReleaseParams holds the parameters needed to find
+// the latest release of a plugin for a platform.
+type FindLatestReleaseParams struct {
+	Owner      string
+	Repository string
+	Releases   []*github.RepositoryRelease
+	OS         string
+	Arch       string
+	// IncludePrereleases determines whether prereleases
+	// can be the latest release.
+	IncludePrereleases bool
+	// IncludeDrafts determines whether draft releases
+	// can be the latest release.
+	IncludeDrafts bool
+	// Naming is the naming convention for release assets,
+	// when not set, the default naming convention will be used.
+	Naming *NamingConvention
+}
+
+// FindLatestRelease finds the release with the highest semantic version
+// precedence that contains a plugin archive for the provided platform,
+// returning the release along with the version for the release.
+// A nil release is returned if no release contains an archive for the platform.
+func FindLatestRelease(
+	params *FindLatestReleaseParams,
+) (*github.RepositoryRelease, string) {
+	assetNames := namingOrDefault(params.Naming).repoAssetNames(
+		params.Owner,
+		params.Repository,
+	)
+
+	var latest *github.RepositoryRelease
+	latestVersion := ""
+	for _, release := range params.Releases {
+		if !validTagPattern.MatchString(release.GetTagName()) ||
+			(release.GetDraft() && !params.IncludeDrafts) ||
+			(isPrerelease(release) && !params.IncludePrereleases) {
+			continue
+		}
+
+		version := versionFromTag(release.GetTagName())
+		if latest != nil && compareVersions(version, latestVersion) <= 0 {
+			continue
+		}
+
+		if hasArchive(assetNames, version, params.OS, params.Arch, release) {
+			latest = release
+			latestVersion = version
+		}
+	}
+
+	return latest, latestVersion
+}
+
+func hasArchive(
+	assetNames *repoAssetNames,
+	version string,
+	os string,
+	arch string,
+	release *github.RepositoryRelease,
+) bool {
+	for _, format := range assetNames.naming.ArchiveFormats() {
+		names := assetNames.archiveNameCandidates(version, os, arch, format)
+		if findAsset(release.Assets, names) != nil {
+			return true
+		}
+	}
+
+	return false
 }
 
 func ExtractPluginVersionPackage(
@@ -219,15 +443,18 @@ func ExtractPluginVersionPackage(
 	token string,
 ) (*types.PluginVersionPackage, error) {
 	pluginPackage := &types.PluginVersionPackage{
-		OS:   params.OS,
-		Arch: params.Arch,
+		Version: params.Version,
+		OS:      params.OS,
+		Arch:    params.Arch,
 	}
 
-	registryInfoURL := getRegistryInfoURL(params.Release.Assets)
-	registryInfo, err := getRegistryInfoFromURL(
+	registryInfo, err := getRegistryInfo(
 		ctx,
 		client,
-		registryInfoURL,
+		params.ArtifactCache,
+		params.Owner,
+		params.Repository,
+		params.Release,
 		token,
 	)
 	if err != nil {
@@ -236,9 +463,15 @@ func ExtractPluginVersionPackage(
 	pluginPackage.SupportedProtocols = registryInfo.SupportedProtocols
 	pluginPackage.Dependencies = registryInfo.Dependencies
 
-	attachReleaseFileInfo(
-		params.Repository,
-		params.Version,
+	shasumsAsset := attachReleaseFileInfo(
+		namingOrDefault(params.Naming),
+		&AssetNameParams{
+			Owner:      params.Owner,
+			Repository: params.Repository,
+			Version:    params.Version,
+			OS:         params.OS,
+			Arch:       params.Arch,
+		},
 		params.Release,
 		pluginPackage,
 	)
@@ -251,10 +484,18 @@ func ExtractPluginVersionPackage(
 	}
 	pluginPackage.SigningKeys = signingKeys
 
-	shasum, err := getSHASumFromURL(
+	shasum, err := getSHASum(
 		ctx,
 		client,
-		pluginPackage.SHASumsURL,
+		params.ArtifactCache,
+		&ArtifactKey{
+			Owner:      params.Owner,
+			Repository: params.Repository,
+			Tag:        params.Release.GetTagName(),
+			AssetID:    shasumsAsset.GetID(),
+			Kind:       ArtifactKindSHASums,
+		},
+		shasumsAsset,
 		pluginPackage.Filename,
 		token,
 	)
@@ -300,56 +541,77 @@ func prepareSigningKeysForPackageInfo(
 }
 
 func attachReleaseFileInfo(
-	repository string,
-	version string,
+	naming *NamingConvention,
+	assetNameParams *AssetNameParams,
 	release *github.RepositoryRelease,
 	versionPackage *types.PluginVersionPackage,
-) {
-	archive := fmt.Sprintf(
-		"%s_%s_%s_%s.zip",
-		repository,
-		version,
-		versionPackage.OS,
-		versionPackage.Arch,
-	)
-	shasumsFile := fmt.Sprintf(
-		"%s_%s_SHA256SUMS",
-		repository,
-		version,
-	)
-	shasumsSignatureFile := fmt.Sprintf(
-		"%s_%s_SHA256SUMS.sig",
-		repository,
-		version,
-	)
-
-	for _, asset := range release.Assets {
-		if asset.GetName() == archive {
-			versionPackage.Filename = archive
+) *github.ReleaseAsset {
+	assetNames := naming.repoAssetNames(assetNameParams.Owner, assetNameParams.Repository)
+	shasumsFile := assetNames.checksumsName(assetNameParams.Version)
+	shasumsSignatureFile := assetNames.checksumsSignatureName(assetNameParams.Version)
+
+	// The archive in the most preferred format is used when the release
+	// contains archives in multiple formats for the platform.
+	for _, format := range naming.ArchiveFormats() {
+		asset := findAsset(
+			release.Assets,
+			assetNames.archiveNameCandidates(
+				assetNameParams.Version,
+				assetNameParams.OS,
+				assetNameParams.Arch,
+				format,
+			),
+		)
+		if asset != nil {
+			versionPackage.Filename = asset.GetName()
+			versionPackage.ArchiveFormat = format
 			versionPackage.DownloadURL = asset.GetURL()
+			break
 		}
+	}
 
+	var shasumsAsset *github.ReleaseAsset
+	for _, asset := range release.Assets {
 		if asset.GetName() == shasumsFile {
 			versionPackage.SHASumsURL = asset.GetURL()
+			shasumsAsset = asset
 		}
 
 		if asset.GetName() == shasumsSignatureFile {
 			versionPackage.SHASumsSignatureURL = asset.GetURL()
 		}
 	}
+
+	return shasumsAsset
+}
+
+func findAsset(assets []*github.ReleaseAsset, names []string) *github.ReleaseAsset {
+	for _, name := range names {
+		for _, asset := range assets {
+			if asset.GetName() == name {
+				return asset
+			}
+		}
+	}
+
+	return nil
 }
 
-func getSHASumFromURL(
+func getSHASum(
 	ctx context.Context,
 	client httputils.Client,
-	shasumsURL string,
+	artifactCache ArtifactCache,
+	key *ArtifactKey,
+	shasumsAsset *github.ReleaseAsset,
 	archiveFilename string,
 	token string,
 ) (string, error) {
-	shasumBytes, err := downloadFromGitHub(
+	shasumBytes, err := fetchArtifact(
 		ctx,
 		client,
-		shasumsURL,
+		artifactCache,
+		key,
+		shasumsAsset,
 		token,
 	)
 	if err != nil {
@@ -398,16 +660,34 @@ func downloadFromGitHub(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf(
-			"failed to fetch from url %q: status code: %s",
-			url,
-			resp.Status,
-		)
+		return nil, &DownloadStatusError{
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+		}
 	}
 
 	return io.ReadAll(resp.Body)
 }
 
+// DownloadStatusError is returned when a file download from GitHub
+// responds with an unexpected status code.
+type DownloadStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (e *DownloadStatusError) Error() string {
+	return fmt.Sprintf(
+		"failed to fetch from url %q: status code: %s",
+		e.URL,
+		e.Status,
+	)
+}
+
 func createGitHubDownloadRequest(
 	ctx context.Context,
 	url string,
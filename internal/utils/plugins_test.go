@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v70/github"
 	"github.com/newstack-cloud/bluelink-github-registry/internal/testutils"
@@ -18,8 +24,11 @@ type PluginUtilsTestSuite struct {
 func (s *PluginUtilsTestSuite) Test_extracts_plugin_versions_from_the_provided_releases() {
 	pluginVersions, err := ExtractPluginVersions(
 		context.Background(),
-		"bluelink-provider-example",
-		inputReleases1(),
+		&ExtractPluginVersionsParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-example",
+			Releases:   inputReleases1(),
+		},
 		&testutils.StubHTTPClient{
 			Contents: registryInfoContents(),
 		},
@@ -32,11 +41,262 @@ func (s *PluginUtilsTestSuite) Test_extracts_plugin_versions_from_the_provided_r
 	)
 }
 
+func (s *PluginUtilsTestSuite) Test_extracts_plugin_versions_concurrently_in_release_order() {
+	releases := []*github.RepositoryRelease{}
+	expectedVersions := []string{}
+	for i := range 20 {
+		version := fmt.Sprintf("1.0.%d", i)
+		releases = append(releases, &github.RepositoryRelease{
+			TagName: github.Ptr(fmt.Sprintf("v%s", version)),
+			Assets: []*github.ReleaseAsset{
+				{
+					Name: github.Ptr(fmt.Sprintf("bluelink-provider-example_%s_registry_info.json", version)),
+					URL:  testutils.GithubAssetURL(i),
+				},
+			},
+		})
+		expectedVersions = append(expectedVersions, version)
+	}
+
+	client := &concurrencyTrackingHTTPClient{
+		contents: registryInfoContents(),
+	}
+	pluginVersions, err := ExtractPluginVersions(
+		context.Background(),
+		&ExtractPluginVersionsParams{
+			Owner:       "newstack-cloud",
+			Repository:  "bluelink-provider-example",
+			Releases:    releases,
+			Concurrency: 4,
+		},
+		client,
+		"test-token",
+	)
+	s.Require().NoError(err)
+
+	actualVersions := []string{}
+	for _, pluginVersion := range pluginVersions.Versions {
+		actualVersions = append(actualVersions, pluginVersion.Version)
+	}
+	s.Assert().Equal(expectedVersions, actualVersions)
+	s.Assert().LessOrEqual(client.maxInFlight.Load(), int32(4))
+}
+
+func (s *PluginUtilsTestSuite) Test_stops_extracting_plugin_versions_when_context_is_cancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := ExtractPluginVersions(
+		ctx,
+		&ExtractPluginVersionsParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-example",
+			Releases:   inputReleases1(),
+		},
+		&blockingHTTPClient{},
+		"test-token",
+	)
+	s.Require().ErrorIs(err, context.Canceled)
+}
+
+func (s *PluginUtilsTestSuite) Test_extracts_plugin_versions_sorted_with_release_metadata() {
+	publishedAt := time.Date(2025, 3, 4, 10, 30, 0, 0, time.UTC)
+	releases := []*github.RepositoryRelease{
+		{
+			TagName:         github.Ptr("v1.10.0"),
+			TargetCommitish: github.Ptr("9c4b1d2e3f405162738495a6b7c8d9e0f1a2b3c4"),
+			HTMLURL:         github.Ptr("https://github.com/newstack-cloud/bluelink-provider-example/releases/tag/v1.10.0"),
+			PublishedAt:     &github.Timestamp{Time: publishedAt},
+		},
+		{
+			// The target commitish is a branch name so the
+			// commit SHA is not known.
+			TagName:         github.Ptr("v1.2.0"),
+			TargetCommitish: github.Ptr("main"),
+			HTMLURL:         github.Ptr("https://github.com/newstack-cloud/bluelink-provider-example/releases/tag/v1.2.0"),
+			PublishedAt:     &github.Timestamp{Time: publishedAt.Add(-time.Hour)},
+		},
+		{
+			TagName: github.Ptr("v1.10.0-rc.1"),
+		},
+	}
+	for i, release := range releases {
+		version := strings.TrimPrefix(release.GetTagName(), "v")
+		release.Assets = []*github.ReleaseAsset{
+			{
+				Name: github.Ptr(fmt.Sprintf("bluelink-provider-example_%s_registry_info.json", version)),
+				URL:  testutils.GithubAssetURL(i),
+			},
+		}
+	}
+
+	pluginVersions, err := ExtractPluginVersions(
+		context.Background(),
+		&ExtractPluginVersionsParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-example",
+			Releases:   releases,
+			Channel:    ReleaseChannelAll,
+		},
+		&testutils.StubHTTPClient{
+			Contents: registryInfoContents(),
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(pluginVersions.Versions, 3)
+
+	olderPublishedAt := publishedAt.Add(-time.Hour)
+	s.Assert().Equal(
+		[]*types.PluginVersion{
+			{
+				Version:            "1.2.0",
+				SupportedProtocols: []string{"1.2", "2.0"},
+				SupportedPlatforms: []*types.PluginVersionPlatform{},
+				PublishedAt:        &olderPublishedAt,
+				HTMLURL:            "https://github.com/newstack-cloud/bluelink-provider-example/releases/tag/v1.2.0",
+			},
+			{
+				Version:            "1.10.0-rc.1",
+				SupportedProtocols: []string{"1.2", "2.0"},
+				SupportedPlatforms: []*types.PluginVersionPlatform{},
+				Prerelease:         true,
+			},
+			{
+				Version:            "1.10.0",
+				SupportedProtocols: []string{"1.2", "2.0"},
+				SupportedPlatforms: []*types.PluginVersionPlatform{},
+				PublishedAt:        &publishedAt,
+				CommitSHA:          "9c4b1d2e3f405162738495a6b7c8d9e0f1a2b3c4",
+				HTMLURL:            "https://github.com/newstack-cloud/bluelink-provider-example/releases/tag/v1.10.0",
+			},
+		},
+		pluginVersions.Versions,
+	)
+}
+
+func (s *PluginUtilsTestSuite) Test_takes_commit_sha_from_tag_commit_shas_when_provided() {
+	// The target commitish of a release from the REST API is the branch
+	// or commit that the tag was created from, so the commit SHAs of tags
+	// are used instead.
+	releases := []*github.RepositoryRelease{
+		{
+			TagName:         github.Ptr("v1.0.0"),
+			TargetCommitish: github.Ptr("main"),
+		},
+		{
+			TagName:         github.Ptr("v1.1.0"),
+			TargetCommitish: github.Ptr("9c4b1d2e3f405162738495a6b7c8d9e0f1a2b3c4"),
+		},
+	}
+	for i, release := range releases {
+		version := strings.TrimPrefix(release.GetTagName(), "v")
+		release.Assets = []*github.ReleaseAsset{
+			{
+				Name: github.Ptr(fmt.Sprintf("bluelink-provider-example_%s_registry_info.json", version)),
+				URL:  testutils.GithubAssetURL(i),
+			},
+		}
+	}
+
+	pluginVersions, err := ExtractPluginVersions(
+		context.Background(),
+		&ExtractPluginVersionsParams{
+			Owner:      "newstack-cloud",
+			Repository: "bluelink-provider-example",
+			Releases:   releases,
+			Channel:    ReleaseChannelAll,
+			TagCommitSHAs: map[string]string{
+				"v1.0.0": "5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394",
+			},
+		},
+		&testutils.StubHTTPClient{
+			Contents: registryInfoContents(),
+		},
+		"test-token",
+	)
+	s.Require().NoError(err)
+	s.Require().Len(pluginVersions.Versions, 2)
+	s.Assert().Equal("1.0.0", pluginVersions.Versions[0].Version)
+	s.Assert().Equal(
+		"5f0c3a9e2b7d41c68e9a0b1c2d3e4f5061728394",
+		pluginVersions.Versions[0].CommitSHA,
+	)
+	// The target commitish is not used for tags
+	// without a known commit.
+	s.Assert().Equal("1.1.0", pluginVersions.Versions[1].Version)
+	s.Assert().Empty(pluginVersions.Versions[1].CommitSHA)
+}
+
+func (s *PluginUtilsTestSuite) Test_extracts_plugin_versions_for_release_channel() {
+	testCases := map[string]struct {
+		channel          string
+		includeDrafts    bool
+		expectedVersions []string
+	}{
+		"default": {
+			expectedVersions: []string{"1.0.0"},
+		},
+		"stable": {
+			channel:          ReleaseChannelStable,
+			expectedVersions: []string{"1.0.0"},
+		},
+		"prerelease": {
+			channel:          ReleaseChannelPrerelease,
+			expectedVersions: []string{"1.1.0-beta.1", "1.1.0-rc"},
+		},
+		"all": {
+			channel:          ReleaseChannelAll,
+			expectedVersions: []string{"1.0.0", "1.1.0-beta.1", "1.1.0-rc"},
+		},
+		"all including drafts": {
+			channel:          ReleaseChannelAll,
+			includeDrafts:    true,
+			expectedVersions: []string{"1.0.0", "1.1.0-beta.1", "1.1.0-rc", "1.2.0"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		s.Run(name, func() {
+			pluginVersions, err := ExtractPluginVersions(
+				context.Background(),
+				&ExtractPluginVersionsParams{
+					Owner:         "newstack-cloud",
+					Repository:    "bluelink-provider-example",
+					Releases:      channelReleases(),
+					Channel:       testCase.channel,
+					IncludeDrafts: testCase.includeDrafts,
+				},
+				&testutils.StubHTTPClient{
+					Contents: registryInfoContents(),
+				},
+				"test-token",
+			)
+			s.Require().NoError(err)
+
+			actualVersions := []string{}
+			for _, pluginVersion := range pluginVersions.Versions {
+				actualVersions = append(actualVersions, pluginVersion.Version)
+				s.Assert().Equal(
+					strings.Contains(pluginVersion.Version, "-"),
+					pluginVersion.Prerelease,
+				)
+			}
+			s.Assert().Equal(testCase.expectedVersions, actualVersions)
+		})
+	}
+}
+
 func (s *PluginUtilsTestSuite) Test_finds_repository_for_provided_plugin() {
 	pluginRepo := FindPluginRepo(
 		reposToSearch(),
 		"newstack-cloud",
 		"example",
+		"",
+		DefaultNamingConvention(),
 	)
 	s.Assert().NotNil(pluginRepo)
 	s.Assert().Equal(
@@ -51,6 +311,91 @@ func (s *PluginUtilsTestSuite) Test_finds_repository_for_provided_plugin() {
 	)
 }
 
+func (s *PluginUtilsTestSuite) Test_finds_repository_for_provided_plugin_type() {
+	pluginRepo := FindPluginRepo(
+		reposToSearch(),
+		"newstack-cloud",
+		"example",
+		PluginTypeTransformer,
+		DefaultNamingConvention(),
+	)
+	s.Require().NotNil(pluginRepo)
+	s.Assert().Equal("bluelink-transformer-example", pluginRepo.GetName())
+}
+
+func (s *PluginUtilsTestSuite) Test_extracts_plugin_name_from_repository_name() {
+	naming := DefaultNamingConvention()
+	pluginName, pluginType, ok := naming.ParseRepoName("newstack-cloud", "bluelink-transformer-celerity")
+	s.Assert().True(ok)
+	s.Assert().Equal("celerity", pluginName)
+	s.Assert().Equal(PluginTypeTransformer, pluginType)
+
+	_, _, ok = naming.ParseRepoName("newstack-cloud", "some-other-repo")
+	s.Assert().False(ok)
+}
+
+func (s *PluginUtilsTestSuite) Test_finds_latest_release_for_platform() {
+	releases := []*github.RepositoryRelease{
+		latestReleaseCandidate("v1.2.0", "linux_amd64", "linux_arm64"),
+		latestReleaseCandidate("v1.10.0", "linux_amd64"),
+		latestReleaseCandidate("v1.9.0", "linux_amd64", "linux_arm64"),
+		latestReleaseCandidate("v1.11.0-beta.1", "linux_amd64", "linux_arm64"),
+		latestReleaseCandidate("v2.0.0", "linux_amd64", "linux_arm64"),
+		latestReleaseCandidate("some-other-tag", "linux_amd64", "linux_arm64"),
+	}
+	releases[4].Draft = github.Ptr(true)
+
+	testCases := map[string]struct {
+		arch               string
+		includePrereleases bool
+		includeDrafts      bool
+		expectedVersion    string
+	}{
+		"latest": {
+			arch:            "amd64",
+			expectedVersion: "1.10.0",
+		},
+		"latest with archive for platform": {
+			arch:            "arm64",
+			expectedVersion: "1.9.0",
+		},
+		"latest prerelease": {
+			arch:               "arm64",
+			includePrereleases: true,
+			expectedVersion:    "1.11.0-beta.1",
+		},
+		"latest including drafts": {
+			arch:            "amd64",
+			includeDrafts:   true,
+			expectedVersion: "2.0.0",
+		},
+		"no release for platform": {
+			arch:            "386",
+			expectedVersion: "",
+		},
+	}
+
+	for name, testCase := range testCases {
+		s.Run(name, func() {
+			release, version := FindLatestRelease(&FindLatestReleaseParams{
+				Owner:              "newstack-cloud",
+				Repository:         "bluelink-provider-example",
+				Releases:           releases,
+				OS:                 "linux",
+				Arch:               testCase.arch,
+				IncludePrereleases: testCase.includePrereleases,
+				IncludeDrafts:      testCase.includeDrafts,
+			})
+			s.Assert().Equal(testCase.expectedVersion, version)
+			if testCase.expectedVersion == "" {
+				s.Assert().Nil(release)
+				return
+			}
+			s.Assert().Equal("v"+testCase.expectedVersion, release.GetTagName())
+		})
+	}
+}
+
 func (s *PluginUtilsTestSuite) Test_extracts_plugin_package_info_for_the_provided_release() {
 	signingKeys, err := testutils.GetSigningKeysFromEnv()
 	s.Require().NoError(err)
@@ -58,6 +403,7 @@ func (s *PluginUtilsTestSuite) Test_extracts_plugin_package_info_for_the_provide
 	versionPackage, err := ExtractPluginVersionPackage(
 		context.Background(),
 		&ExtractPluginVersionPackageParams{
+			Owner:                 "newstack-cloud",
 			Repository:            "bluelink-provider-example",
 			Release:               packageInfoRelease(),
 			Version:               "1.0.1",
@@ -81,10 +427,12 @@ func expectedVersionPackage(
 	expectedSigningKeys *types.PublicGPGSigningKeys,
 ) *types.PluginVersionPackage {
 	return &types.PluginVersionPackage{
+		Version:            "1.0.1",
 		SupportedProtocols: []string{"1.2", "2.0"},
 		OS:                 "linux",
 		Arch:               "amd64",
 		Filename:           "bluelink-provider-example_1.0.1_linux_amd64.zip",
+		ArchiveFormat:      "zip",
 		// See the packageInfoRelease function for the URL in the source github releases.
 		DownloadURL:         *testutils.GithubAssetURL(6),
 		SHASumsURL:          packageInfoRegistrySHA256SumsURL(),
@@ -211,6 +559,46 @@ func inputReleases1() []*github.RepositoryRelease {
 	}
 }
 
+func latestReleaseCandidate(tag string, platforms ...string) *github.RepositoryRelease {
+	release := &github.RepositoryRelease{
+		TagName: github.Ptr(tag),
+	}
+	for _, platform := range platforms {
+		release.Assets = append(release.Assets, &github.ReleaseAsset{
+			Name: github.Ptr(fmt.Sprintf(
+				"bluelink-provider-example_%s_%s.zip",
+				strings.TrimPrefix(tag, "v"),
+				platform,
+			)),
+		})
+	}
+
+	return release
+}
+
+func channelReleases() []*github.RepositoryRelease {
+	releases := []*github.RepositoryRelease{
+		{TagName: github.Ptr("v1.0.0")},
+		// Prereleases can be identified by the version in the tag
+		// or by being marked as a prerelease in GitHub.
+		{TagName: github.Ptr("v1.1.0-beta.1")},
+		{TagName: github.Ptr("v1.1.0-rc"), Prerelease: github.Ptr(true)},
+		{TagName: github.Ptr("v1.2.0"), Draft: github.Ptr(true)},
+	}
+
+	for i, release := range releases {
+		version := strings.TrimPrefix(release.GetTagName(), "v")
+		release.Assets = []*github.ReleaseAsset{
+			{
+				Name: github.Ptr(fmt.Sprintf("bluelink-provider-example_%s_registry_info.json", version)),
+				URL:  testutils.GithubAssetURL(i),
+			},
+		}
+	}
+
+	return releases
+}
+
 func registryInfoContents() []byte {
 	return []byte(`
 	{
@@ -291,6 +679,40 @@ func packageSHASumContents() []byte {
 	`)
 }
 
+// concurrencyTrackingHTTPClient is a stub HTTP client that
+// records the maximum number of requests in flight at the same time.
+type concurrencyTrackingHTTPClient struct {
+	contents    []byte
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *concurrencyTrackingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	current := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		previousMax := c.maxInFlight.Load()
+		if current <= previousMax || c.maxInFlight.CompareAndSwap(previousMax, current) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewReader(c.contents)),
+	}, nil
+}
+
+// blockingHTTPClient is a stub HTTP client that blocks
+// until the request context is cancelled.
+type blockingHTTPClient struct{}
+
+func (c *blockingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
 func TestPluginUtilsTestSuite(t *testing.T) {
 	suite.Run(t, new(PluginUtilsTestSuite))
 }
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"slices"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+)
+
+const (
+	// UnresolvedReasonConstraint is the reason a plugin version was not
+	// resolved when the version does not satisfy the version constraint.
+	UnresolvedReasonConstraint = "constraint_not_satisfied"
+	// UnresolvedReasonPlatform is the reason a plugin version was not
+	// resolved when the version does not support the requested platform.
+	UnresolvedReasonPlatform = "platform_not_supported"
+	// UnresolvedReasonProtocol is the reason a plugin version was not
+	// resolved when the version does not support the requested protocol.
+	UnresolvedReasonProtocol = "protocol_not_supported"
+)
+
+// ResolveVersionParams holds the requirements that a plugin
+// version must meet to be resolved.
+type ResolveVersionParams struct {
+	Constraint *VersionConstraint
+	OS         string
+	Arch       string
+	Protocol   string
+}
+
+// ResolveVersion finds the plugin version with the highest precedence
+// that satisfies the version constraint and supports the requested
+// platform and protocol.
+// When no version meets the requirements, nil is returned along with
+// the reasons that each of the candidate versions was not resolved.
+func ResolveVersion(
+	versions []*types.PluginVersion,
+	params *ResolveVersionParams,
+) (*types.PluginVersion, []*types.UnresolvedPluginVersion) {
+	var resolved *types.PluginVersion
+	unresolved := []*types.UnresolvedPluginVersion{}
+	for _, version := range versions {
+		reasons := unmetRequirements(version, params)
+		if len(reasons) > 0 {
+			unresolved = append(unresolved, &types.UnresolvedPluginVersion{
+				Version: version.Version,
+				Reasons: reasons,
+			})
+			continue
+		}
+
+		if resolved == nil || compareVersions(version.Version, resolved.Version) > 0 {
+			resolved = version
+		}
+	}
+
+	if resolved != nil {
+		return resolved, nil
+	}
+
+	return nil, unresolved
+}
+
+func unmetRequirements(
+	version *types.PluginVersion,
+	params *ResolveVersionParams,
+) []string {
+	reasons := []string{}
+	if !params.Constraint.Check(version.Version) {
+		reasons = append(reasons, UnresolvedReasonConstraint)
+	}
+
+	supportsPlatform := slices.ContainsFunc(
+		version.SupportedPlatforms,
+		func(platform *types.PluginVersionPlatform) bool {
+			return platform.OS == params.OS && platform.Arch == params.Arch
+		},
+	)
+	if !supportsPlatform {
+		reasons = append(reasons, UnresolvedReasonPlatform)
+	}
+
+	if !slices.Contains(version.SupportedProtocols, params.Protocol) {
+		reasons = append(reasons, UnresolvedReasonProtocol)
+	}
+
+	return reasons
+}
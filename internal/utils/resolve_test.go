@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResolveVersionTestSuite struct {
+	suite.Suite
+}
+
+func (s *ResolveVersionTestSuite) Test_resolves_highest_matching_version() {
+	constraint, err := ParseVersionConstraint("^1.4.0")
+	s.Require().NoError(err)
+
+	resolved, unresolved := ResolveVersion(
+		resolveCandidates(),
+		&ResolveVersionParams{
+			Constraint: constraint,
+			OS:         "linux",
+			Arch:       "arm64",
+			Protocol:   "1.0",
+		},
+	)
+	s.Assert().Nil(unresolved)
+	s.Require().NotNil(resolved)
+	s.Assert().Equal("1.5.0", resolved.Version)
+}
+
+func (s *ResolveVersionTestSuite) Test_provides_reasons_when_no_version_matches() {
+	constraint, err := ParseVersionConstraint("^1.6.0 || ^2.0.0")
+	s.Require().NoError(err)
+
+	resolved, unresolved := ResolveVersion(
+		resolveCandidates(),
+		&ResolveVersionParams{
+			Constraint: constraint,
+			OS:         "linux",
+			Arch:       "arm64",
+			Protocol:   "1.0",
+		},
+	)
+	s.Assert().Nil(resolved)
+	s.Assert().Equal(
+		[]*types.UnresolvedPluginVersion{
+			{
+				Version: "1.4.0",
+				Reasons: []string{UnresolvedReasonConstraint},
+			},
+			{
+				Version: "1.5.0",
+				Reasons: []string{UnresolvedReasonConstraint},
+			},
+			{
+				Version: "1.6.0",
+				Reasons: []string{UnresolvedReasonPlatform},
+			},
+			{
+				Version: "2.0.0",
+				Reasons: []string{UnresolvedReasonProtocol},
+			},
+		},
+		unresolved,
+	)
+}
+
+func resolveCandidates() []*types.PluginVersion {
+	return []*types.PluginVersion{
+		{
+			Version:            "1.4.0",
+			SupportedProtocols: []string{"1.0"},
+			SupportedPlatforms: []*types.PluginVersionPlatform{
+				{OS: "linux", Arch: "arm64"},
+			},
+		},
+		{
+			Version:            "1.5.0",
+			SupportedProtocols: []string{"1.0"},
+			SupportedPlatforms: []*types.PluginVersionPlatform{
+				{OS: "linux", Arch: "amd64"},
+				{OS: "linux", Arch: "arm64"},
+			},
+		},
+		{
+			Version:            "1.6.0",
+			SupportedProtocols: []string{"1.0"},
+			SupportedPlatforms: []*types.PluginVersionPlatform{
+				{OS: "linux", Arch: "amd64"},
+			},
+		},
+		{
+			Version:            "2.0.0",
+			SupportedProtocols: []string{"2.0"},
+			SupportedPlatforms: []*types.PluginVersionPlatform{
+				{OS: "linux", Arch: "arm64"},
+			},
+		},
+	}
+}
+
+func TestResolveVersionTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolveVersionTestSuite))
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+)
+
+const (
+	// VersionAliasLatest is the alias for the stable version of a plugin
+	// with the highest precedence that has an archive for a platform.
+	VersionAliasLatest = "latest"
+	// VersionAliasLatestPrerelease is the alias for the version of a plugin
+	// with the highest precedence that has an archive for a platform,
+	// including prereleases.
+	VersionAliasLatestPrerelease = "latest-prerelease"
+)
+
+// IsVersionAlias determines whether the provided version
+// is an alias for a concrete version of a plugin.
+func IsVersionAlias(version string) bool {
+	return version == VersionAliasLatest || version == VersionAliasLatestPrerelease
+}
+
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// compareVersions compares two semantic versions without the "v" prefix
+// by precedence as defined in the semantic versioning specification,
+// build metadata is ignored.
+// The result is negative if a has a lower precedence than b, positive if
+// a has a higher precedence than b and 0 if they have the same precedence.
+func compareVersions(a, b string) int {
+	aParts := versionParts(a)
+	bParts := versionParts(b)
+
+	for i := range 3 {
+		if result := compareNumericIdentifiers(aParts[i], bParts[i]); result != 0 {
+			return result
+		}
+	}
+
+	return comparePrereleases(aParts[3], bParts[3])
+}
+
+// versionParts returns the major, minor and patch versions
+// along with the prerelease identifiers for a version.
+func versionParts(version string) [4]string {
+	parts := [4]string{}
+	matches := validTagPattern.FindStringSubmatch("v" + version)
+	if matches == nil {
+		return parts
+	}
+
+	copy(parts[:], matches[1:5])
+	return parts
+}
+
+func comparePrereleases(a, b string) int {
+	// A version without a prerelease has a higher precedence
+	// than the same version with a prerelease.
+	if a == "" || b == "" {
+		return compareBool(a == "", b == "")
+	}
+
+	aIdentifiers := strings.Split(a, ".")
+	bIdentifiers := strings.Split(b, ".")
+	for i := 0; i < len(aIdentifiers) && i < len(bIdentifiers); i += 1 {
+		if result := comparePrereleaseIdentifiers(aIdentifiers[i], bIdentifiers[i]); result != 0 {
+			return result
+		}
+	}
+
+	return len(aIdentifiers) - len(bIdentifiers)
+}
+
+func comparePrereleaseIdentifiers(a, b string) int {
+	aIsNumeric := isNumericIdentifier(a)
+	bIsNumeric := isNumericIdentifier(b)
+	if aIsNumeric && bIsNumeric {
+		return compareNumericIdentifiers(a, b)
+	}
+
+	// Numeric identifiers have a lower precedence than
+	// alphanumeric identifiers.
+	if aIsNumeric || bIsNumeric {
+		return compareBool(bIsNumeric, aIsNumeric)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// compareNumericIdentifiers compares numeric identifiers without leading zeros
+// without parsing them to avoid overflows for large numbers.
+func compareNumericIdentifiers(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	return strings.Compare(a, b)
+}
+
+func isNumericIdentifier(identifier string) bool {
+	for _, char := range identifier {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return identifier != ""
+}
+
+func compareBool(a, b bool) int {
+	if a == b {
+		return 0
+	}
+
+	if a {
+		return 1
+	}
+
+	return -1
+}
+
+// commitSHA returns the SHA of the commit that the tag of a release
+// points to, from the provided commit SHAs of tags when set, otherwise
+// from the target commitish of the release.
+// The target commitish is only the commit of the release tag for releases
+// fetched from the GitHub GraphQL API, with the GitHub REST API, it is the
+// branch or commit that the tag was created from when the release was
+// created.
+func commitSHA(
+	release *github.RepositoryRelease,
+	tagCommitSHAs map[string]string,
+) string {
+	if tagCommitSHAs != nil {
+		return tagCommitSHAs[release.GetTagName()]
+	}
+
+	targetCommitish := release.GetTargetCommitish()
+	if commitSHAPattern.MatchString(targetCommitish) {
+		return targetCommitish
+	}
+
+	return ""
+}
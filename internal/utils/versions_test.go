@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionsTestSuite struct {
+	suite.Suite
+}
+
+func (s *VersionsTestSuite) Test_sorts_versions_by_precedence() {
+	// Ordered by precedence as per the example in the
+	// semantic versioning specification along with some extra
+	// cases for numeric identifiers and build metadata.
+	expected := []string{
+		"0.9.12",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1+build.5",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+		"18446744073709551616.0.0",
+	}
+
+	versions := slices.Clone(expected)
+	rand.New(rand.NewSource(1)).Shuffle(len(versions), func(i, j int) {
+		versions[i], versions[j] = versions[j], versions[i]
+	})
+	slices.SortFunc(versions, compareVersions)
+
+	s.Assert().Equal(expected, versions)
+}
+
+func (s *VersionsTestSuite) Test_ignores_build_metadata() {
+	s.Assert().Equal(0, compareVersions("1.0.0+build.1", "1.0.0+build.2"))
+	s.Assert().Equal(0, compareVersions("1.0.0-rc.1+build.1", "1.0.0-rc.1"))
+}
+
+func (s *VersionsTestSuite) Test_only_provides_commit_sha_for_full_commit_shas() {
+	release := func(targetCommitish string) *github.RepositoryRelease {
+		return &github.RepositoryRelease{
+			TagName:         github.Ptr("v1.0.0"),
+			TargetCommitish: github.Ptr(targetCommitish),
+		}
+	}
+
+	s.Assert().Equal(
+		"2f1a6a3c8e0b5d4f7a9c1e3b5d7f9a1c3e5b7d9f",
+		commitSHA(release("2f1a6a3c8e0b5d4f7a9c1e3b5d7f9a1c3e5b7d9f"), nil),
+	)
+	s.Assert().Equal("", commitSHA(release("main"), nil))
+	s.Assert().Equal("", commitSHA(release("2f1a6a3"), nil))
+}
+
+func TestVersionsTestSuite(t *testing.T) {
+	suite.Run(t, new(VersionsTestSuite))
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/newstack-cloud/bluelink-github-registry/internal/apikeys"
+	"github.com/newstack-cloud/bluelink-github-registry/internal/auth"
+)
+
+const usage = `Manage API keys for the Bluelink GitHub Registry.
+
+Usage:
+  go run ./tools/api-keys <command> [flags]
+
+Commands:
+  create  Create a new API key, the key is only displayed once.
+  list    List all API keys, including revoked and expired keys.
+  revoke  Revoke the API key with the provided ID.
+
+The API keys file defaults to the value of the
+BLUELINK_GITHUB_REGISTRY_API_KEYS_FILE environment variable,
+use the -file flag of a command to use a different file.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "create":
+		err = create(os.Args[2:])
+	case "list":
+		err = list(os.Args[2:])
+	case "revoke":
+		err = revoke(os.Args[2:])
+	default:
+		fmt.Print(usage)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func create(args []string) error {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	file := fileFlag(flags)
+	label := flags.String("label", "", "Who or what the key is for, included in audit logs (required)")
+	orgs := flags.String("orgs", "", "Comma-separated organisations the key can access, all if not set")
+	plugins := flags.String("plugins", "", "Comma-separated plugin name globs the key can access (e.g. \"aws,azure-*\"), all if not set")
+	expiresIn := flags.Duration("expires-in", 0, "How long until the key expires (e.g. 720h), the key does not expire if not set")
+	flags.Parse(args)
+
+	if *label == "" {
+		return fmt.Errorf("a label must be provided with -label")
+	}
+
+	params := &apikeys.CreateKeyParams{
+		Label: *label,
+	}
+	if *orgs != "" || *plugins != "" {
+		params.Scope = &auth.Scope{
+			Organisations: splitList(*orgs),
+			Plugins:       splitList(*plugins),
+		}
+	}
+	if *expiresIn > 0 {
+		expiresAt := time.Now().Add(*expiresIn).UTC()
+		params.ExpiresAt = &expiresAt
+	}
+
+	plainKey, key, err := storeForFile(*file).Create(params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %s for %q.\n", key.ID, key.Label)
+	fmt.Println("Store the key somewhere safe, it can not be displayed again:")
+	fmt.Println()
+	fmt.Println(plainKey)
+	return nil
+}
+
+func list(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	file := fileFlag(flags)
+	flags.Parse(args)
+
+	keys, err := storeForFile(*file).List()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tLABEL\tSTATUS\tORGANISATIONS\tPLUGINS\tCREATED\tEXPIRES")
+	now := time.Now()
+	for _, key := range keys {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID,
+			key.Label,
+			status(key, now),
+			scopeList(key.Scope, func(scope *auth.Scope) []string { return scope.Organisations }),
+			scopeList(key.Scope, func(scope *auth.Scope) []string { return scope.Plugins }),
+			key.CreatedAt.Format(time.RFC3339),
+			formatOptionalTime(key.ExpiresAt),
+		)
+	}
+	return writer.Flush()
+}
+
+func revoke(args []string) error {
+	flags := flag.NewFlagSet("revoke", flag.ExitOnError)
+	file := fileFlag(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("the ID of the key to revoke must be provided")
+	}
+
+	id := flags.Arg(0)
+	if err := storeForFile(*file).Revoke(id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked API key %s.\n", id)
+	return nil
+}
+
+func fileFlag(flags *flag.FlagSet) *string {
+	return flags.String(
+		"file",
+		os.Getenv("BLUELINK_GITHUB_REGISTRY_API_KEYS_FILE"),
+		"Path to the API keys file",
+	)
+}
+
+func storeForFile(file string) *apikeys.FileStore {
+	if file == "" {
+		fmt.Println("Error: an API keys file must be provided with -file or BLUELINK_GITHUB_REGISTRY_API_KEYS_FILE")
+		os.Exit(1)
+	}
+
+	return apikeys.NewFileStore(file)
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+func status(key *apikeys.Key, now time.Time) string {
+	if key.RevokedAt != nil {
+		return "revoked"
+	}
+
+	if !key.IsActive(now) {
+		return "expired"
+	}
+
+	return "active"
+}
+
+func scopeList(scope *auth.Scope, getList func(*auth.Scope) []string) string {
+	if scope == nil || len(getList(scope)) == 0 {
+		return "*"
+	}
+
+	return strings.Join(getList(scope), ",")
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return "never"
+	}
+
+	return value.Format(time.RFC3339)
+}